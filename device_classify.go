@@ -0,0 +1,270 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+)
+
+//go:embed device_rules.json
+var defaultDeviceRules []byte
+
+var activeDeviceRules *DeviceRuleSet
+var deviceRulesOnce sync.Once
+
+const deviceMinConfidence = 20
+
+var deviceTypes = map[string]struct {
+	Label string
+	Icon  string
+}{
+	"router":      {"Router", "📶"},
+	"switch":      {"Switch", "🔀"},
+	"printer":     {"Printer", "📠"},
+	"camera":      {"Camera", "📷"},
+	"phone":       {"Phone", "📱"},
+	"media":       {"TV/Media", "📺"},
+	"nas":         {"NAS", "💾"},
+	"hypervisor":  {"Hypervisor/VM", "📦"},
+	"workstation": {"Workstation", "💻"},
+	"server":      {"Server", "🏢"},
+}
+
+type DeviceRule struct {
+	Type     string `json:"type"`
+	Weight   int    `json:"weight"`
+	Vendor   string `json:"vendor,omitempty"`
+	Ports    []int  `json:"ports,omitempty"`
+	Service  string `json:"service,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+	MDNS     string `json:"mdns,omitempty"`
+	UPnP     string `json:"upnp,omitempty"`
+	SNMP     string `json:"snmp,omitempty"`
+	TTL      []int  `json:"ttl,omitempty"`
+
+	vendor, service, hostname, mdns, upnp, snmp *regexp.Regexp
+}
+
+type DeviceRuleSet struct {
+	Rules []*DeviceRule `json:"rules"`
+}
+
+type deviceEvidence struct {
+	Vendor   string
+	Hostname string
+	Ports    []int
+	Services []string
+	MDNS     []string
+	UPnP     []string
+	SNMP     []string
+	TTL      int
+}
+
+func LoadDeviceRules(path string) (*DeviceRuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read device rules: %v", err)
+	}
+
+	custom, err := parseDeviceRules(data)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := parseDeviceRules(defaultDeviceRules)
+	if err != nil {
+		return nil, err
+	}
+
+	custom.Rules = append(custom.Rules, defaults.Rules...)
+	return custom, nil
+}
+
+func parseDeviceRules(data []byte) (*DeviceRuleSet, error) {
+	var set DeviceRuleSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid device rules: %v", err)
+	}
+
+	for i, rule := range set.Rules {
+		if _, ok := deviceTypes[rule.Type]; !ok {
+			return nil, fmt.Errorf("rule %d: unknown device type %q", i, rule.Type)
+		}
+		if len(rule.TTL) != 0 && len(rule.TTL) != 2 {
+			return nil, fmt.Errorf("rule %d: ttl must be [min, max]", i)
+		}
+
+		patterns := []struct {
+			source string
+			target **regexp.Regexp
+		}{
+			{rule.Vendor, &rule.vendor},
+			{rule.Service, &rule.service},
+			{rule.Hostname, &rule.hostname},
+			{rule.MDNS, &rule.mdns},
+			{rule.UPnP, &rule.upnp},
+			{rule.SNMP, &rule.snmp},
+		}
+		for _, p := range patterns {
+			if p.source == "" {
+				continue
+			}
+			re, err := regexp.Compile(p.source)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: invalid pattern %q: %v", i, p.source, err)
+			}
+			*p.target = re
+		}
+	}
+
+	return &set, nil
+}
+
+func getDeviceRules() *DeviceRuleSet {
+	deviceRulesOnce.Do(func() {
+		if activeDeviceRules != nil {
+			return
+		}
+		set, err := parseDeviceRules(defaultDeviceRules)
+		if err != nil {
+			panic(fmt.Sprintf("bundled device rules are invalid: %v", err))
+		}
+		activeDeviceRules = set
+	})
+	return activeDeviceRules
+}
+
+func collectDeviceEvidence(host *HostInfo) deviceEvidence {
+	evidence := deviceEvidence{
+		Vendor:   host.Vendor,
+		Hostname: host.Hostname,
+		TTL:      host.TTL,
+	}
+
+	for _, service := range host.Services {
+		evidence.Ports = append(evidence.Ports, service.Port)
+		evidence.Services = append(evidence.Services, service.Service, service.Product, service.Version, service.Banner)
+		if service.HTTP != nil {
+			evidence.Services = append(evidence.Services, service.HTTP.Title, service.HTTP.Server)
+		}
+		if service.TLS != nil {
+			evidence.Services = append(evidence.Services, service.TLS.Subject, service.TLS.Issuer)
+		}
+	}
+	if host.SMB != nil {
+		evidence.Services = append(evidence.Services, host.SMB.OSHint)
+	}
+	if host.MDNS != nil {
+		if evidence.Hostname == "" {
+			evidence.Hostname = host.MDNS.Hostname
+		}
+		for _, service := range host.MDNS.Services {
+			evidence.MDNS = append(evidence.MDNS, service.Type+".local")
+			evidence.MDNS = append(evidence.MDNS, service.TXT...)
+		}
+	}
+	if host.UPnP != nil {
+		evidence.UPnP = append(evidence.UPnP, host.UPnP.DeviceType, host.UPnP.FriendlyName,
+			host.UPnP.Manufacturer, host.UPnP.ModelName, host.UPnP.Server)
+		evidence.UPnP = append(evidence.UPnP, host.UPnP.Services...)
+	}
+	if host.SNMP != nil {
+		evidence.SNMP = append(evidence.SNMP, host.SNMP.SysDescr, host.SNMP.SysObjectID, host.SNMP.SysName)
+		if evidence.Vendor == "" || evidence.Vendor == "Unknown" {
+			evidence.Vendor = host.SNMP.EnterpriseVendor
+		}
+	}
+
+	return evidence
+}
+
+func matchesAny(re *regexp.Regexp, values []string) bool {
+	for _, value := range values {
+		if value != "" && re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *DeviceRule) matches(evidence deviceEvidence) bool {
+	if r.vendor != nil && (evidence.Vendor == "" || !r.vendor.MatchString(evidence.Vendor)) {
+		return false
+	}
+	if r.hostname != nil && (evidence.Hostname == "" || !r.hostname.MatchString(evidence.Hostname)) {
+		return false
+	}
+	if len(r.Ports) > 0 && !slices.ContainsFunc(r.Ports, func(port int) bool { return slices.Contains(evidence.Ports, port) }) {
+		return false
+	}
+	if r.service != nil && !matchesAny(r.service, evidence.Services) {
+		return false
+	}
+	if r.mdns != nil && !matchesAny(r.mdns, evidence.MDNS) {
+		return false
+	}
+	if r.upnp != nil && !matchesAny(r.upnp, evidence.UPnP) {
+		return false
+	}
+	if r.snmp != nil && !matchesAny(r.snmp, evidence.SNMP) {
+		return false
+	}
+	if len(r.TTL) == 2 && (evidence.TTL < r.TTL[0] || evidence.TTL > r.TTL[1]) {
+		return false
+	}
+	return true
+}
+
+func (set *DeviceRuleSet) classify(evidence deviceEvidence) (string, int) {
+	scores := make(map[string]int)
+	total := 0
+	for _, rule := range set.Rules {
+		if rule.matches(evidence) {
+			scores[rule.Type] += rule.Weight
+			total += rule.Weight
+		}
+	}
+	if total == 0 {
+		return "", 0
+	}
+
+	types := make([]string, 0, len(scores))
+	for deviceType := range scores {
+		types = append(types, deviceType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if scores[types[i]] != scores[types[j]] {
+			return scores[types[i]] > scores[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	best := scores[types[0]]
+	confidence := min(best, 100) * best / total
+	if confidence < deviceMinConfidence {
+		return "", 0
+	}
+	return types[0], confidence
+}
+
+func classifyHost(host *HostInfo) {
+	host.DeviceType, host.DeviceConfidence = getDeviceRules().classify(collectDeviceEvidence(host))
+}
+
+func deviceTypeLabel(deviceType string) string {
+	if info, ok := deviceTypes[deviceType]; ok {
+		return info.Label
+	}
+	return ""
+}
+
+func deviceTypeIcon(deviceType string) string {
+	if info, ok := deviceTypes[deviceType]; ok {
+		return info.Icon
+	}
+	return ""
+}
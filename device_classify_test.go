@@ -0,0 +1,146 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassifyHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     *HostInfo
+		expected string
+	}{
+		{
+			name:     "printer by vendor and raw print port",
+			host:     &HostInfo{Vendor: "Brother Industries", Services: []ServiceInfo{{Port: 9100}, {Port: 80, Service: "HTTP"}}},
+			expected: "printer",
+		},
+		{
+			name:     "printer by mDNS only",
+			host:     &HostInfo{MDNS: &MDNSInfo{Services: []MDNSService{{Type: "_ipp._tcp"}}}},
+			expected: "printer",
+		},
+		{
+			name:     "router by UPnP gateway",
+			host:     &HostInfo{Vendor: "AVM GmbH", UPnP: &UPnPInfo{DeviceType: "InternetGatewayDevice:1", Services: []string{"WANIPConnection:1"}}},
+			expected: "router",
+		},
+		{
+			name:     "media by googlecast",
+			host:     &HostInfo{MDNS: &MDNSInfo{Services: []MDNSService{{Type: "_googlecast._tcp"}}}, Services: []ServiceInfo{{Port: 8009}}},
+			expected: "media",
+		},
+		{
+			name:     "hypervisor by VMware OUI",
+			host:     &HostInfo{Vendor: "VMware, Inc.", TTL: 64, Services: []ServiceInfo{{Port: 22, Service: "SSH"}}},
+			expected: "hypervisor",
+		},
+		{
+			name:     "switch by SNMP sysDescr",
+			host:     &HostInfo{SNMP: &SNMPInfo{SysDescr: "Cisco IOS Software, Catalyst 2960 Software"}},
+			expected: "switch",
+		},
+		{
+			name:     "DNS resolver is not a router",
+			host:     &HostInfo{Vendor: "Raspberry Pi Trading Ltd", TTL: 64, Services: []ServiceInfo{{Port: 53}}},
+			expected: "",
+		},
+		{
+			name:     "router by vendor and DNS",
+			host:     &HostInfo{Vendor: "MikroTik", TTL: 64, Services: []ServiceInfo{{Port: 53}}},
+			expected: "router",
+		},
+		{
+			name:     "vendor alternatives are anchored",
+			host:     &HostInfo{Vendor: "Shenzhen Lenovo Parts Supplier", TTL: 64},
+			expected: "",
+		},
+		{
+			name:     "workstation by anchored vendor",
+			host:     &HostInfo{Vendor: "Lenovo", TTL: 128},
+			expected: "workstation",
+		},
+		{
+			name:     "ASUS computer is not a router",
+			host:     &HostInfo{Vendor: "ASUS", TTL: 128, Services: []ServiceInfo{{Port: 445}}},
+			expected: "workstation",
+		},
+		{
+			name:     "ASUS router by DNS",
+			host:     &HostInfo{Vendor: "ASUS", TTL: 64, Services: []ServiceInfo{{Port: 53}, {Port: 80}}},
+			expected: "router",
+		},
+		{
+			name:     "Microsoft device is not a hypervisor",
+			host:     &HostInfo{Vendor: "Microsoft", TTL: 128},
+			expected: "",
+		},
+		{
+			name:     "Hyper-V by Microsoft vendor and VMConnect port",
+			host:     &HostInfo{Vendor: "Microsoft", TTL: 128, Services: []ServiceInfo{{Port: 2179}}},
+			expected: "hypervisor",
+		},
+		{
+			name:     "no evidence",
+			host:     &HostInfo{Vendor: "Unknown"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classifyHost(tt.host)
+			if tt.host.DeviceType != tt.expected {
+				t.Errorf("DeviceType = %q (%d%%), expected %q", tt.host.DeviceType, tt.host.DeviceConfidence, tt.expected)
+			}
+			if tt.expected != "" && (tt.host.DeviceConfidence < deviceMinConfidence || tt.host.DeviceConfidence > 100) {
+				t.Errorf("confidence %d out of range", tt.host.DeviceConfidence)
+			}
+			if tt.expected == "" && tt.host.DeviceConfidence != 0 {
+				t.Errorf("unclassified host should have zero confidence, got %d", tt.host.DeviceConfidence)
+			}
+		})
+	}
+}
+
+func TestClassifyConfidenceReflectsConflicts(t *testing.T) {
+	clear := &HostInfo{Vendor: "Brother Industries", Services: []ServiceInfo{{Port: 9100}, {Port: 631}}}
+	mixed := &HostInfo{Vendor: "Brother Industries", Services: []ServiceInfo{{Port: 9100}, {Port: 631}, {Port: 3306}, {Port: 22}}}
+
+	classifyHost(clear)
+	classifyHost(mixed)
+	if mixed.DeviceConfidence >= clear.DeviceConfidence {
+		t.Errorf("conflicting evidence should lower confidence: clear=%d mixed=%d", clear.DeviceConfidence, mixed.DeviceConfidence)
+	}
+}
+
+func TestLoadDeviceRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	os.WriteFile(path, []byte(`{"rules": [{"type": "camera", "weight": 90, "hostname": "^cam-"}]}`), 0644)
+
+	rules, err := LoadDeviceRules(path)
+	if err != nil {
+		t.Fatalf("LoadDeviceRules() error: %v", err)
+	}
+	defaults, _ := parseDeviceRules(defaultDeviceRules)
+	if len(rules.Rules) != len(defaults.Rules)+1 {
+		t.Errorf("expected custom rules to be merged with defaults, got %d rules", len(rules.Rules))
+	}
+
+	deviceType, _ := rules.classify(collectDeviceEvidence(&HostInfo{Hostname: "cam-frontdoor"}))
+	if deviceType != "camera" {
+		t.Errorf("custom rule not applied, got %q", deviceType)
+	}
+
+	for _, invalid := range []string{
+		`{"rules": [{"type": "toaster", "weight": 10}]}`,
+		`{"rules": [{"type": "nas", "weight": 10, "vendor": "("}]}`,
+		`{"rules": [{"type": "nas", "weight": 10, "ttl": [64]}]}`,
+	} {
+		if _, err := parseDeviceRules([]byte(invalid)); err == nil {
+			t.Errorf("expected error for %s", invalid)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+const discoveryListenWindow = 4 * time.Second
+
+type discoverySession struct {
+	mdns    *MulticastDiscovery
+	ssdp    *SSDPDiscovery
+	started time.Time
+}
+
+func startDiscovery() *discoverySession {
+	session := &discoverySession{started: time.Now()}
+	if activeProxy != nil {
+		return session
+	}
+	if mdns, err := StartMulticastDiscovery(); err == nil {
+		session.mdns = mdns
+	}
+	if ssdp, err := StartSSDPDiscovery(); err == nil {
+		session.ssdp = ssdp
+	}
+	return session
+}
+
+func (s *discoverySession) Apply(host *HostInfo) {
+	if !host.IsReachable {
+		return
+	}
+	if s.mdns != nil {
+		s.mdns.Apply(host)
+	}
+	if s.ssdp != nil {
+		s.ssdp.Apply(host)
+	}
+	classifyHost(host)
+	fingerprintOS(host)
+}
+
+func (s *discoverySession) Close(ctx context.Context) {
+	if s.mdns != nil || s.ssdp != nil {
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Until(s.started.Add(discoveryListenWindow))):
+		}
+	}
+	if s.mdns != nil {
+		s.mdns.Close()
+	}
+	if s.ssdp != nil {
+		s.ssdp.Close()
+	}
+}
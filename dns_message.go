@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	dnsTypeA    = 1
+	dnsTypePTR  = 12
+	dnsTypeTXT  = 16
+	dnsTypeAAAA = 28
+	dnsTypeSRV  = 33
+	dnsTypeANY  = 255
+
+	dnsClassIN      = 1
+	dnsClassMask    = 0x7fff
+	dnsUnicastReply = 0x8000
+	dnsMaxPointers  = 32
+)
+
+type dnsQuestion struct {
+	Name  string
+	Type  uint16
+	Class uint16
+}
+
+type dnsRecord struct {
+	Name   string
+	Type   uint16
+	Class  uint16
+	TTL    uint32
+	Target string
+	Port   uint16
+	TXT    []string
+	IP     net.IP
+}
+
+type dnsMessage struct {
+	ID        uint16
+	Flags     uint16
+	Questions []dnsQuestion
+	Records   []dnsRecord
+}
+
+func (m *dnsMessage) IsResponse() bool {
+	return m.Flags&0x8000 != 0
+}
+
+func appendDNSName(packet []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			continue
+		}
+		packet = append(packet, byte(len(label)))
+		packet = append(packet, label...)
+	}
+	return append(packet, 0)
+}
+
+func buildDNSQuery(id uint16, questions ...dnsQuestion) []byte {
+	packet := make([]byte, 12)
+	binary.BigEndian.PutUint16(packet[0:2], id)
+	binary.BigEndian.PutUint16(packet[4:6], uint16(len(questions)))
+
+	for _, q := range questions {
+		packet = appendDNSName(packet, q.Name)
+		packet = binary.BigEndian.AppendUint16(packet, q.Type)
+		packet = binary.BigEndian.AppendUint16(packet, q.Class)
+	}
+	return packet
+}
+
+func readDNSName(packet []byte, offset int) (string, int, error) {
+	var labels []string
+	next := -1
+
+	for jumps := 0; ; {
+		if offset >= len(packet) {
+			return "", 0, fmt.Errorf("truncated name")
+		}
+
+		length := int(packet[offset])
+		switch {
+		case length == 0:
+			if next < 0 {
+				next = offset + 1
+			}
+			return strings.Join(labels, "."), next, nil
+		case length&0xc0 == 0xc0:
+			if offset+1 >= len(packet) {
+				return "", 0, fmt.Errorf("truncated name pointer")
+			}
+			if jumps++; jumps > dnsMaxPointers {
+				return "", 0, fmt.Errorf("name compression loop")
+			}
+			if next < 0 {
+				next = offset + 2
+			}
+			offset = int(binary.BigEndian.Uint16(packet[offset:offset+2]) & 0x3fff)
+		default:
+			if offset+1+length > len(packet) {
+				return "", 0, fmt.Errorf("truncated label")
+			}
+			labels = append(labels, string(packet[offset+1:offset+1+length]))
+			offset += 1 + length
+		}
+	}
+}
+
+func parseDNSMessage(packet []byte) (*dnsMessage, error) {
+	if len(packet) < 12 {
+		return nil, fmt.Errorf("DNS message too short")
+	}
+
+	msg := &dnsMessage{
+		ID:    binary.BigEndian.Uint16(packet[0:2]),
+		Flags: binary.BigEndian.Uint16(packet[2:4]),
+	}
+	questions := int(binary.BigEndian.Uint16(packet[4:6]))
+	records := int(binary.BigEndian.Uint16(packet[6:8])) +
+		int(binary.BigEndian.Uint16(packet[8:10])) +
+		int(binary.BigEndian.Uint16(packet[10:12]))
+
+	offset := 12
+	for range questions {
+		name, next, err := readDNSName(packet, offset)
+		if err != nil {
+			return nil, err
+		}
+		if next+4 > len(packet) {
+			return nil, fmt.Errorf("truncated question")
+		}
+		msg.Questions = append(msg.Questions, dnsQuestion{
+			Name:  name,
+			Type:  binary.BigEndian.Uint16(packet[next : next+2]),
+			Class: binary.BigEndian.Uint16(packet[next+2 : next+4]),
+		})
+		offset = next + 4
+	}
+
+	for range records {
+		record, next, err := parseDNSRecord(packet, offset)
+		if err != nil {
+			return nil, err
+		}
+		msg.Records = append(msg.Records, record)
+		offset = next
+	}
+
+	return msg, nil
+}
+
+func parseDNSRecord(packet []byte, offset int) (dnsRecord, int, error) {
+	name, next, err := readDNSName(packet, offset)
+	if err != nil {
+		return dnsRecord{}, 0, err
+	}
+	if next+10 > len(packet) {
+		return dnsRecord{}, 0, fmt.Errorf("truncated record header")
+	}
+
+	record := dnsRecord{
+		Name:  name,
+		Type:  binary.BigEndian.Uint16(packet[next : next+2]),
+		Class: binary.BigEndian.Uint16(packet[next+2 : next+4]),
+		TTL:   binary.BigEndian.Uint32(packet[next+4 : next+8]),
+	}
+	rdLength := int(binary.BigEndian.Uint16(packet[next+8 : next+10]))
+	start := next + 10
+	end := start + rdLength
+	if end > len(packet) {
+		return dnsRecord{}, 0, fmt.Errorf("truncated record data")
+	}
+	rdata := packet[start:end]
+
+	switch record.Type {
+	case dnsTypeA:
+		if len(rdata) == 4 {
+			record.IP = net.IP(append([]byte{}, rdata...))
+		}
+	case dnsTypeAAAA:
+		if len(rdata) == 16 {
+			record.IP = net.IP(append([]byte{}, rdata...))
+		}
+	case dnsTypePTR:
+		if record.Target, _, err = readDNSName(packet, start); err != nil {
+			return dnsRecord{}, 0, err
+		}
+	case dnsTypeSRV:
+		if len(rdata) < 7 {
+			return dnsRecord{}, 0, fmt.Errorf("truncated SRV record")
+		}
+		record.Port = binary.BigEndian.Uint16(rdata[4:6])
+		if record.Target, _, err = readDNSName(packet, start+6); err != nil {
+			return dnsRecord{}, 0, err
+		}
+	case dnsTypeTXT:
+		for i := 0; i < len(rdata); {
+			length := int(rdata[i])
+			if i+1+length > len(rdata) {
+				break
+			}
+			if length > 0 {
+				record.TXT = append(record.TXT, string(rdata[i+1:i+1+length]))
+			}
+			i += 1 + length
+		}
+	}
+
+	return record, end, nil
+}
+
+func reverseDNSName(ip string) string {
+	parsed := net.ParseIP(ip).To4()
+	if parsed == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa", parsed[3], parsed[2], parsed[1], parsed[0])
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+)
+
+type exportFormat struct {
+	Name      string
+	Extension string
+	write     func(filename string, hosts []*HostInfo) error
+}
+
+var exportFormats = []exportFormat{
+	{Name: "CSV", Extension: "csv", write: exportToCSV},
+	{Name: "JSON", Extension: "json", write: exportToJSON},
+	{Name: "HTML", Extension: "html", write: exportToHTML},
+	{Name: "Markdown", Extension: "md", write: exportToMarkdown},
+}
+
+func defaultExportFilename(format exportFormat, now time.Time) string {
+	return fmt.Sprintf("viewnet-%s.%s", now.Format("20060102-150405"), format.Extension)
+}
+
+func exportToJSON(filename string, hosts []*HostInfo) error {
+	data, err := json.MarshalIndent(hosts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+type exportRow struct {
+	IP       string
+	MAC      string
+	Vendor   string
+	Hostname string
+	Device   string
+	OS       string
+	Ports    string
+	Services string
+}
+
+func exportRows(hosts []*HostInfo) []exportRow {
+	rows := make([]exportRow, 0, len(hosts))
+	for _, host := range hosts {
+		var ports, services []string
+		for _, service := range host.Services {
+			ports = append(ports, fmt.Sprintf("%d", service.Port))
+			detail := fmt.Sprintf("%d/%s", service.Port, service.Service)
+			if version := serviceVersion(&service); version != "" {
+				detail += " (" + version + ")"
+			}
+			services = append(services, detail)
+		}
+		rows = append(rows, exportRow{
+			IP:       host.IP,
+			MAC:      host.MAC,
+			Vendor:   host.Vendor,
+			Hostname: host.Hostname,
+			Device:   deviceTypeLabel(host.DeviceType),
+			OS:       host.OSFamily,
+			Ports:    strings.Join(ports, ", "),
+			Services: strings.Join(services, ", "),
+		})
+	}
+	return rows
+}
+
+var exportHTMLTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>ViewNet results</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #7D56F4; color: #FAFAFA; }
+</style>
+</head>
+<body>
+<h1>ViewNet results</h1>
+<p>{{len .Rows}} hosts, exported {{.Generated}}</p>
+<table>
+<tr><th>IP Address</th><th>MAC Address</th><th>Vendor</th><th>Hostname</th><th>Device Type</th><th>OS Family</th><th>Open Ports</th><th>Services</th></tr>
+{{- range .Rows}}
+<tr><td>{{.IP}}</td><td>{{.MAC}}</td><td>{{.Vendor}}</td><td>{{.Hostname}}</td><td>{{.Device}}</td><td>{{.OS}}</td><td>{{.Ports}}</td><td>{{.Services}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+func exportToHTML(filename string, hosts []*HostInfo) (err error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	return exportHTMLTemplate.Execute(file, struct {
+		Rows      []exportRow
+		Generated string
+	}{exportRows(hosts), time.Now().Format("2006-01-02 15:04:05")})
+}
+
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
+func exportToMarkdown(filename string, hosts []*HostInfo) error {
+	var b strings.Builder
+	b.WriteString("# ViewNet results\n\n")
+	fmt.Fprintf(&b, "%d hosts, exported %s\n\n", len(hosts), time.Now().Format("2006-01-02 15:04:05"))
+	b.WriteString("| IP Address | MAC Address | Vendor | Hostname | Device Type | OS Family | Open Ports | Services |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for _, row := range exportRows(hosts) {
+		cells := []string{row.IP, row.MAC, row.Vendor, row.Hostname, row.Device, row.OS, row.Ports, row.Services}
+		for i, cell := range cells {
+			cells[i] = markdownCell(cell)
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return os.WriteFile(filename, []byte(b.String()), 0644)
+}
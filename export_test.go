@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+func exportTestHosts() []*HostInfo {
+	return []*HostInfo{
+		{IP: "10.0.0.1", MAC: "00:11:22:33:44:55", Vendor: "Acme | Co", Hostname: "gw", IsReachable: true,
+			Services: []ServiceInfo{{Port: 22, Service: "ssh", Product: "OpenSSH", Version: "9.6"}}},
+		{IP: "10.0.0.7", Hostname: "<nas>", IsReachable: true, Services: []ServiceInfo{{Port: 445, Service: "microsoft-ds"}}},
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{format: "CSV", want: []string{"IP Address,MAC Address", "10.0.0.1,00:11:22:33:44:55", "22/ssh"}},
+		{format: "JSON", want: []string{`"IP": "10.0.0.1"`, `"Port": 445`}},
+		{format: "HTML", want: []string{"<table>", "<td>10.0.0.7</td>", "&lt;nas&gt;", "22/ssh (OpenSSH 9.6)"}},
+		{format: "Markdown", want: []string{"| IP Address |", "| 10.0.0.1 | 00:11:22:33:44:55 | Acme \\| Co | gw |", "445/microsoft-ds"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var format exportFormat
+			for _, f := range exportFormats {
+				if f.Name == tt.format {
+					format = f
+				}
+			}
+			filename := filepath.Join(t.TempDir(), defaultExportFilename(format, time.Now()))
+			if err := format.write(filename, exportTestHosts()); err != nil {
+				t.Fatalf("export failed: %v", err)
+			}
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("%s export should contain %q:\n%s", tt.format, want, data)
+				}
+			}
+			if _, err := os.Stat("/dev/full"); err == nil {
+				if err := format.write("/dev/full", exportTestHosts()); err == nil {
+					t.Errorf("%s export to a full device should fail", tt.format)
+				}
+			}
+		})
+	}
+}
+
+func TestExportJSONRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "hosts.json")
+	if err := exportToJSON(filename, exportTestHosts()); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filename)
+	var hosts []*HostInfo
+	if err := json.Unmarshal(data, &hosts); err != nil {
+		t.Fatalf("exported JSON should parse back: %v", err)
+	}
+	if len(hosts) != 2 || hosts[0].Services[0].Version != "9.6" {
+		t.Errorf("round trip lost data: %+v", hosts)
+	}
+}
+
+func TestExportDialog(t *testing.T) {
+	model := NewModularUI("10.0.0.0/24", 1, 1024, 200, false, "", nil, false)
+	model.state = stateComplete
+	model.results = exportTestHosts()
+	model.searchInput.SetValue("port:445")
+	filterResults(model.UIModel)
+
+	openExport(model.UIModel)
+	if !model.exportFiltered || len(exportHosts(model.UIModel)) != 1 {
+		t.Fatalf("an active search should default to exporting the filtered results")
+	}
+	export := NewExportComponent()
+	export.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, model.UIModel)
+	if len(exportHosts(model.UIModel)) != 2 {
+		t.Error("space should switch to exporting all results")
+	}
+	export.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, model.UIModel)
+
+	export.Update(tea.KeyMsg{Type: tea.KeyDown}, model.UIModel)
+	export.Update(tea.KeyMsg{Type: tea.KeyEnter}, model.UIModel)
+	if !model.exportNaming || !strings.HasSuffix(model.exportInput.Placeholder, ".json") {
+		t.Fatalf("enter should ask for a filename with a .json suggestion, got %q", model.exportInput.Placeholder)
+	}
+
+	filename := filepath.Join(t.TempDir(), "filtered.json")
+	model.exportInput.SetValue(filename)
+	cmd := export.Update(tea.KeyMsg{Type: tea.KeyEnter}, model.UIModel)
+	if cmd == nil || model.exportVisible {
+		t.Fatal("enter on the filename should close the dialog and start the export")
+	}
+	status, _ := cmd().(statusMsg)
+	if !strings.Contains(string(status), "1 host exported to "+filename) {
+		t.Errorf("unexpected status %q", status)
+	}
+	data, _ := os.ReadFile(filename)
+	if !strings.Contains(string(data), "10.0.0.7") || strings.Contains(string(data), "10.0.0.1\"") {
+		t.Errorf("only the filtered host should be exported:\n%s", data)
+	}
+}
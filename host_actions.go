@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const deepScanWorkers = 500
+
+func hostURLs(host *HostInfo) []string {
+	var urls []string
+	for i := range host.Services {
+		service := &host.Services[i]
+		if service.HTTP == nil && !isHTTPService(service) {
+			continue
+		}
+		scheme := "http"
+		if service.TLS != nil {
+			scheme = "https"
+		}
+		urls = append(urls, fmt.Sprintf("%s://%s/", scheme, net.JoinHostPort(host.IP, strconv.Itoa(service.Port))))
+	}
+	return urls
+}
+
+func mergeHostInfo(existing, scanned *HostInfo) {
+	firstSeen := existing.FirstSeen
+	if firstSeen.IsZero() {
+		firstSeen = scanned.FirstSeen
+	}
+	if scanned.Hostname == "" {
+		scanned.Hostname = existing.Hostname
+	}
+	if scanned.MAC == "" {
+		scanned.MAC, scanned.Vendor = existing.MAC, existing.Vendor
+	}
+	if scanned.NetBIOS == nil {
+		scanned.NetBIOS = existing.NetBIOS
+	}
+	if scanned.SMB == nil {
+		scanned.SMB = existing.SMB
+	}
+	if scanned.MDNS == nil {
+		scanned.MDNS = existing.MDNS
+	}
+	if scanned.UPnP == nil {
+		scanned.UPnP = existing.UPnP
+	}
+	if scanned.SNMP == nil {
+		scanned.SNMP = existing.SNMP
+	}
+
+	previous := make(map[int]ServiceInfo, len(existing.Services))
+	for _, service := range existing.Services {
+		previous[service.Port] = service
+	}
+	for i := range scanned.Services {
+		service := &scanned.Services[i]
+		old, ok := previous[service.Port]
+		if !ok {
+			continue
+		}
+		if service.TLS == nil {
+			service.TLS = old.TLS
+		}
+		if service.HTTP == nil {
+			service.HTTP = old.HTTP
+		}
+		if service.SSH == nil {
+			service.SSH = old.SSH
+		}
+	}
+
+	*existing = *scanned
+	existing.FirstSeen = firstSeen
+	classifyHost(existing)
+	fingerprintOS(existing)
+}
+
+func scanSingleHost(ctx context.Context, ip string, options ScanOptions, deep bool) *HostInfo {
+	ctx = withDiscoveryMethod(ctx, options.Discovery)
+	timeout := time.Duration(options.Timeout) * time.Millisecond
+	if deep {
+		return scanHostCustom(ctx, ip, 1, 65535, timeout, deepScanWorkers, nil, false)
+	}
+	return scanHostCustom(ctx, ip, options.StartPort, options.EndPort, timeout, 100, options.CustomPorts, options.IPsOnly)
+}
+
+type terminalOutput struct {
+	*os.File
+	mu sync.Mutex
+}
+
+var programOutput = &terminalOutput{File: os.Stdout}
+
+func (o *terminalOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.File.Write(p)
+}
+
+func (o *terminalOutput) WriteString(s string) (int, error) {
+	return o.Write([]byte(s))
+}
+
+func osc52Sequence(text string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+}
+
+func copyToClipboard(text string) error {
+	_, err := programOutput.Write([]byte(osc52Sequence(text)))
+	return err
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+func hostExportFilename(host *HostInfo) string {
+	return "viewnet-" + strings.NewReplacer(".", "-", ":", "-").Replace(host.IP) + ".csv"
+}
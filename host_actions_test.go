@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+func TestHostURLs(t *testing.T) {
+	host := &HostInfo{
+		IP: "10.0.0.5",
+		Services: []ServiceInfo{
+			{Port: 22, Service: "ssh"},
+			{Port: 80, Service: "HTTP", HTTP: &HTTPInfo{Title: "Router"}},
+			{Port: 443, Service: "https", TLS: &TLSInfo{ALPN: "h2"}},
+			{Port: 8883, Service: "mqtt", TLS: &TLSInfo{}},
+		},
+	}
+
+	got := hostURLs(host)
+	want := []string{"http://10.0.0.5:80/", "https://10.0.0.5:443/"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("hostURLs() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeHostInfo(t *testing.T) {
+	firstSeen := time.Date(2026, 10, 1, 8, 0, 0, 0, time.Local)
+	existing := &HostInfo{
+		IP:        "10.0.0.5",
+		MAC:       "00:11:22:33:44:55",
+		Vendor:    "Acme",
+		Hostname:  "printer.lan",
+		Services:  []ServiceInfo{{Port: 80, HTTP: &HTTPInfo{Title: "Printer"}}, {Port: 22, SSH: &SSHInfo{}}},
+		MDNS:      &MDNSInfo{Hostname: "printer.local"},
+		FirstSeen: firstSeen,
+		LastSeen:  firstSeen,
+	}
+	kept := existing
+
+	now := time.Now()
+	mergeHostInfo(existing, &HostInfo{
+		IP:          "10.0.0.5",
+		IsReachable: true,
+		Services:    []ServiceInfo{{Port: 80}, {Port: 9100}},
+		FirstSeen:   now,
+		LastSeen:    now,
+	})
+
+	if existing != kept {
+		t.Fatal("merge must update the existing HostInfo in place")
+	}
+	if len(existing.Services) != 2 || !existing.IsReachable {
+		t.Errorf("scan results should replace services, got %d services", len(existing.Services))
+	}
+	if !existing.FirstSeen.Equal(firstSeen) || !existing.LastSeen.Equal(now) {
+		t.Errorf("first seen should be kept and last seen updated, got %v / %v", existing.FirstSeen, existing.LastSeen)
+	}
+	if existing.MAC != "00:11:22:33:44:55" || existing.Vendor != "Acme" || existing.Hostname != "printer.lan" || existing.MDNS == nil {
+		t.Errorf("details the single-host scan cannot see should be kept: %+v", existing)
+	}
+	if existing.Services[0].HTTP == nil || existing.Services[0].HTTP.Title != "Printer" {
+		t.Error("HTTP details should be kept for a port the rescan did not identify")
+	}
+	for _, service := range existing.Services {
+		if service.SSH != nil {
+			t.Errorf("details of port %d, which is no longer open, should be dropped", service.Port)
+		}
+	}
+}
+
+func TestOSC52Sequence(t *testing.T) {
+	seq := osc52Sequence("10.0.0.5")
+	payload, ok := strings.CutPrefix(seq, "\x1b]52;c;")
+	if !ok || !strings.HasSuffix(payload, "\a") {
+		t.Fatalf("unexpected OSC52 framing: %q", seq)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(payload, "\a"))
+	if err != nil || string(decoded) != "10.0.0.5" {
+		t.Errorf("payload decodes to %q (%v)", decoded, err)
+	}
+
+	out, err := os.CreateTemp(t.TempDir(), "terminal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(file *os.File) { programOutput.File = file }(programOutput.File)
+	programOutput.File = out
+	if err := copyToClipboard("10.0.0.5"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out.Name()); string(data) != seq {
+		t.Errorf("the sequence should be written through the program output, got %q", data)
+	}
+}
+
+func TestHostActionsMenu(t *testing.T) {
+	model := NewModularUI("10.0.0.0/24", 1, 1024, 200, false, "", []int{80}, false)
+	model.results = []*HostInfo{
+		{IP: "10.0.0.1", IsReachable: true},
+		{IP: "10.0.0.5", IsReachable: true, Hostname: "nas", Services: []ServiceInfo{{Port: 80, HTTP: &HTTPInfo{}}}},
+	}
+	model.cursor = 1
+
+	openHostActions(model.UIModel)
+	if !model.actionsVisible {
+		t.Fatal("actions menu should open for the selected host")
+	}
+	view := model.actions.View(model.UIModel)
+	for _, want := range []string{"10.0.0.5 (nas)", "[h] Copy hostname (nas)", "[1] Open http://10.0.0.5:80/"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("menu should contain %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "[m]") {
+		t.Error("copy MAC should be hidden when the MAC is unknown")
+	}
+
+	if cmd := model.actions.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")}, model.UIModel); cmd != nil {
+		t.Error("single-host scans should wait until the scan is complete")
+	}
+	if model.actionsVisible || !strings.Contains(model.status, "Wait for the scan") {
+		t.Errorf("running an action should close the menu and report why it was skipped, status %q", model.status)
+	}
+
+	model.state = stateComplete
+	host := model.results[1]
+	tab := model.tabs[model.activeTab]
+	applyHostScan(model.UIModel, hostScanMsg{tab: tab, host: host, result: &HostInfo{IP: "10.0.0.5"}, deep: true})
+	if len(host.Services) != 1 {
+		t.Error("an unreachable rescan result should not wipe the host")
+	}
+	status := applyHostScan(model.UIModel, hostScanMsg{tab: tab, host: host, result: &HostInfo{IP: "10.0.0.5", IsReachable: true,
+		Services: []ServiceInfo{{Port: 80}, {Port: 443}, {Port: 8443}}}, deep: true})
+	if len(model.results[1].Services) != 3 || selectedHost(model.UIModel) != host {
+		t.Errorf("deep scan should update the selected host in place, got %d services", len(model.results[1].Services))
+	}
+	if !strings.Contains(status, "Deep scan of 10.0.0.5 finished: 3 open ports") {
+		t.Errorf("unexpected status %q", status)
+	}
+
+	addScanTab(model.UIModel, ScanOptions{Target: "10.0.0.0/24", CustomPorts: []int{80}, Timeout: 200})
+	model.state = stateComplete
+	model.results = []*HostInfo{{IP: "10.0.1.1"}, {IP: "10.0.1.2"}}
+	model.cursor, model.scrollOffset = 1, 1
+	applyHostScan(model.UIModel, hostScanMsg{tab: tab, host: host, result: &HostInfo{IP: "10.0.0.5", IsReachable: true}})
+	if len(host.Services) != 0 || model.cursor != 1 || model.scrollOffset != 1 {
+		t.Errorf("a result for a background tab should update its host without touching the active tab, cursor %d offset %d", model.cursor, model.scrollOffset)
+	}
+}
+
+func TestHostScanInFlight(t *testing.T) {
+	model := NewModularUI("127.0.0.0/30", 1, 1024, 200, false, "", []int{1}, false)
+	model.state = stateComplete
+	host := &HostInfo{IP: "127.0.0.1", IsReachable: true}
+	model.results = []*HostInfo{host}
+
+	if cmd := startHostScan(model.UIModel, host, true); cmd == nil {
+		t.Fatal("the first deep scan should start")
+	}
+	if cmd := startHostScan(model.UIModel, host, false); cmd != nil || !strings.Contains(model.status, "already running") {
+		t.Errorf("a second scan of the same host should be refused, status %q", model.status)
+	}
+
+	addScanTab(model.UIModel, ScanOptions{Target: "127.0.0.0/30", CustomPorts: []int{1}, Timeout: 200})
+	model.state = stateComplete
+	other := &HostInfo{IP: "127.0.0.2", IsReachable: true}
+	model.results = []*HostInfo{other}
+	if cmd := startHostScan(model.UIModel, other, true); cmd == nil {
+		t.Fatal("hosts in other tabs should scan independently")
+	}
+	tab := model.tabs[model.activeTab]
+	cancelled := false
+	cancel := tab.hostScans[other.IP]
+	tab.hostScans[other.IP] = func() { cancelled = true; cancel() }
+
+	closeTab(model.UIModel)
+	if !cancelled {
+		t.Error("closing the tab should cancel its host scans")
+	}
+	if finishHostScan(model.UIModel, hostScanMsg{tab: tab, host: other, result: other}) {
+		t.Error("results for a closed tab should be dropped")
+	}
+	if len(tab.hostScans) != 0 {
+		t.Error("finished scans should be removed from the tab")
+	}
+}
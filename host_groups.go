@@ -0,0 +1,59 @@
+package main
+
+import (
+	"sort"
+)
+
+const (
+	groupByVendor = "vendor"
+	groupByDevice = "device"
+
+	unknownVendorGroup = "Unknown"
+	unclassifiedGroup  = "Unclassified"
+)
+
+type HostGroup struct {
+	Name  string
+	Icon  string
+	Hosts []*HostInfo
+}
+
+func hostGroupName(host *HostInfo, by string) string {
+	if by == groupByDevice {
+		if label := deviceTypeLabel(host.DeviceType); label != "" {
+			return label
+		}
+		return unclassifiedGroup
+	}
+	if host.Vendor == "" || host.Vendor == "Unknown" {
+		return unknownVendorGroup
+	}
+	return host.Vendor
+}
+
+func groupHosts(hosts []*HostInfo, by string) []HostGroup {
+	index := make(map[string]int)
+	var groups []HostGroup
+	for _, host := range hosts {
+		name := hostGroupName(host, by)
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			group := HostGroup{Name: name}
+			if by == groupByDevice {
+				group.Icon = deviceTypeIcon(host.DeviceType)
+			}
+			groups = append(groups, group)
+		}
+		groups[i].Hosts = append(groups[i].Hosts, host)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].Hosts) != len(groups[j].Hosts) {
+			return len(groups[i].Hosts) > len(groups[j].Hosts)
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
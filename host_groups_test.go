@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+func groupTestHosts() []*HostInfo {
+	return []*HostInfo{
+		{IP: "10.0.0.1", Vendor: "Ubiquiti", DeviceType: "router"},
+		{IP: "10.0.0.20", Vendor: "Apple", DeviceType: "phone"},
+		{IP: "10.0.0.21", Vendor: "Apple", DeviceType: "workstation"},
+		{IP: "10.0.0.30", Vendor: ""},
+		{IP: "10.0.0.31", Vendor: "Unknown"},
+		{IP: "10.0.0.32", Vendor: "Unknown"},
+	}
+}
+
+func TestGroupHosts(t *testing.T) {
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{groupByVendor, []string{"Unknown:3", "Apple:2", "Ubiquiti:1"}},
+		{groupByDevice, []string{"Unclassified:3", deviceTypeLabel("phone") + ":1", deviceTypeLabel("router") + ":1", deviceTypeLabel("workstation") + ":1"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, group := range groupHosts(groupTestHosts(), tt.by) {
+			got = append(got, fmt.Sprintf("%s:%d", group.Name, len(group.Hosts)))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("groupHosts(%s) = %v, want %v", tt.by, got, tt.want)
+		}
+	}
+}
+
+func TestGroupComponent(t *testing.T) {
+	model := NewModularUI("10.0.0.0/24", 1, 1024, 200, false, "", nil, false)
+	model.results = groupTestHosts()
+	model.groupBy = groupByVendor
+
+	groups := NewGroupComponent()
+	groups.Update(tea.KeyMsg{Type: tea.KeyEnter}, model.UIModel)
+	view := groups.View(model.UIModel)
+	for _, want := range []string{"Hosts by vendor (3 groups, 6 hosts)", "▾ Unknown", strings.Repeat("█", groupBarWidth), "10.0.0.32"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("group view missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "10.0.0.20") {
+		t.Errorf("collapsed groups should hide their hosts:\n%s", view)
+	}
+
+	groups.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")}, model.UIModel)
+	if model.groupBy != groupByDevice || model.groupExpanded != nil {
+		t.Errorf("'g' should switch to device grouping, got %q", model.groupBy)
+	}
+	groups.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")}, model.UIModel)
+	if model.groupBy != "" {
+		t.Errorf("'g' after device grouping should return to the host list, got %q", model.groupBy)
+	}
+}
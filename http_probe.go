@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"html"
+	"io"
+	"math/bits"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const httpMaxRedirects = 5
+const httpMaxBodySize = 256 * 1024
+
+var (
+	htmlTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlIconPattern  = regexp.MustCompile(`(?is)<link\s[^>]*rel=["']?(?:shortcut\s+)?icon["']?[^>]*>`)
+	htmlHrefPattern  = regexp.MustCompile(`(?is)href=["']?([^"'\s>]+)`)
+	authRealmPattern = regexp.MustCompile(`(?i)realm="?([^",]*)"?`)
+)
+
+type HTTPInfo struct {
+	URL         string
+	StatusCode  int
+	Server      string
+	PoweredBy   string
+	Title       string
+	FaviconHash int32
+	AuthScheme  string
+	AuthRealm   string
+	Redirects   []string
+}
+
+func isHTTPService(info *ServiceInfo) bool {
+	if strings.HasPrefix(info.Service, "HTTP") {
+		return true
+	}
+	return info.TLS != nil && (info.TLS.ALPN == "http/1.1" || info.TLS.ALPN == "h2")
+}
+
+func newHTTPProbeClient(host string, timeout time.Duration, info *HTTPInfo) *http.Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialTCP(ctx, address, timeout)
+		},
+		TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
+		TLSHandshakeTimeout:   timeout * 5,
+		ResponseHeaderTimeout: timeout * 10,
+		DisableKeepAlives:     true,
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout * 15,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > httpMaxRedirects {
+				return http.ErrUseLastResponse
+			}
+			info.Redirects = append(info.Redirects, req.URL.String())
+			if req.URL.Hostname() != host {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+}
+
+func probeHTTP(ctx context.Context, ip string, port int, useTLS bool, timeout time.Duration) (*HTTPInfo, error) {
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	baseURL := fmt.Sprintf("%s://%s/", scheme, net.JoinHostPort(ip, fmt.Sprintf("%d", port)))
+
+	info := &HTTPInfo{}
+	client := newHTTPProbeClient(ip, timeout, info)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "viewnet")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, httpMaxBodySize))
+
+	info.URL = resp.Request.URL.String()
+	info.StatusCode = resp.StatusCode
+	info.Server = sanitizeText(resp.Header.Get("Server"), 100)
+	info.PoweredBy = sanitizeText(resp.Header.Get("X-Powered-By"), 100)
+	info.Title = extractHTMLTitle(body)
+	info.AuthScheme, info.AuthRealm = parseAuthChallenge(resp.Header.Get("WWW-Authenticate"))
+
+	faviconURL := findFaviconURL(resp.Request.URL, body)
+	faviconClient := newHTTPProbeClient(ip, timeout, &HTTPInfo{})
+	if hash, err := fetchFaviconHash(ctx, faviconClient, faviconURL); err == nil {
+		info.FaviconHash = hash
+	}
+
+	return info, nil
+}
+
+func extractHTMLTitle(body []byte) string {
+	matches := htmlTitlePattern.FindSubmatch(body)
+	if len(matches) < 2 {
+		return ""
+	}
+
+	return sanitizeText(html.UnescapeString(string(matches[1])), 120)
+}
+
+func parseAuthChallenge(header string) (string, string) {
+	if header == "" {
+		return "", ""
+	}
+
+	scheme, _, _ := strings.Cut(strings.TrimSpace(header), " ")
+	scheme = sanitizeText(scheme, 30)
+	realm := ""
+	if matches := authRealmPattern.FindStringSubmatch(header); len(matches) > 1 {
+		realm = sanitizeText(matches[1], 100)
+	}
+	return scheme, realm
+}
+
+func findFaviconURL(base *url.URL, body []byte) string {
+	if link := htmlIconPattern.Find(body); link != nil {
+		if href := htmlHrefPattern.FindSubmatch(link); len(href) > 1 {
+			if ref, err := url.Parse(html.UnescapeString(string(href[1]))); err == nil {
+				return base.ResolveReference(ref).String()
+			}
+		}
+	}
+	return base.ResolveReference(&url.URL{Path: "/favicon.ico"}).String()
+}
+
+func fetchFaviconHash(ctx context.Context, client *http.Client, faviconURL string) (int32, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, faviconURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "viewnet")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("favicon returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, httpMaxBodySize))
+	if err != nil || len(data) == 0 {
+		return 0, fmt.Errorf("empty favicon")
+	}
+
+	return faviconHash(data), nil
+}
+
+func faviconHash(data []byte) int32 {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76])
+		b.WriteByte('\n')
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	b.WriteByte('\n')
+
+	return int32(murmur3Hash32([]byte(b.String()), 0))
+}
+
+func murmur3Hash32(data []byte, seed uint32) uint32 {
+	const c1, c2 = 0xcc9e2d51, 0x1b873593
+
+	h := seed
+	nblocks := len(data) / 4
+	for i := range nblocks {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	tail := data[nblocks*4:]
+	var k uint32
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+
+	return h
+}
+
+func (h *HTTPInfo) Summary() string {
+	parts := []string{fmt.Sprintf("%d", h.StatusCode)}
+	if h.Title != "" {
+		parts = append(parts, fmt.Sprintf("%q", h.Title))
+	}
+	if h.Server != "" {
+		parts = append(parts, h.Server)
+	}
+	if h.PoweredBy != "" {
+		parts = append(parts, h.PoweredBy)
+	}
+	if h.AuthScheme != "" {
+		auth := h.AuthScheme
+		if h.AuthRealm != "" {
+			auth += fmt.Sprintf(" realm=%q", h.AuthRealm)
+		}
+		parts = append(parts, auth)
+	}
+	if h.FaviconHash != 0 {
+		parts = append(parts, fmt.Sprintf("favicon %d", h.FaviconHash))
+	}
+	return strings.Join(parts, " | ")
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestMurmur3Hash32(t *testing.T) {
+	tests := []struct {
+		input    string
+		seed     uint32
+		expected uint32
+	}{
+		{"", 0, 0},
+		{"hello", 0, 613153351},
+		{"hello, world", 0, 345750399},
+		{"The quick brown fox jumps over the lazy dog", 0, 776992547},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := murmur3Hash32([]byte(tt.input), tt.seed)
+			if result != tt.expected {
+				t.Errorf("murmur3Hash32(%q) = %d, expected %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseAuthChallenge(t *testing.T) {
+	tests := []struct {
+		header string
+		scheme string
+		realm  string
+	}{
+		{`Basic realm="RouterOS"`, "Basic", "RouterOS"},
+		{`Digest realm="NAS", nonce="abc", qop="auth"`, "Digest", "NAS"},
+		{`Bearer`, "Bearer", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		scheme, realm := parseAuthChallenge(tt.header)
+		if scheme != tt.scheme || realm != tt.realm {
+			t.Errorf("parseAuthChallenge(%q) = %q, %q, expected %q, %q", tt.header, scheme, realm, tt.scheme, tt.realm)
+		}
+	}
+}
+
+func TestExtractHTMLTitle(t *testing.T) {
+	body := []byte("<html><head><TITLE>\n  RouterOS &amp; WebFig\n</TITLE></head></html>")
+	if title := extractHTMLTitle(body); title != "RouterOS & WebFig" {
+		t.Errorf("extractHTMLTitle() = %q", title)
+	}
+
+	if title := extractHTMLTitle([]byte("<html></html>")); title != "" {
+		t.Errorf("expected empty title, got %q", title)
+	}
+
+	injected := []byte("<title>&#27;]0;pwned&#7;&#27;[2J Admin</title>")
+	if title := extractHTMLTitle(injected); title != "]0;pwned[2J Admin" {
+		t.Errorf("control characters should be stripped from titles, got %q", title)
+	}
+
+	long := []byte("<title>" + strings.Repeat("é", 130) + "</title>")
+	if title := extractHTMLTitle(long); !utf8.ValidString(title) || title != strings.Repeat("é", 120)+"..." {
+		t.Errorf("long titles should be cut at a rune boundary, got %q", title)
+	}
+}
+
+func TestProbeHTTP(t *testing.T) {
+	favicon := []byte("\x00\x00\x01\x00fake-icon-data")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/webfig/", http.StatusFound)
+	})
+	mux.HandleFunc("/webfig/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "mikrotik/7.1")
+		w.Header().Set("X-Powered-By", "PHP/8.1")
+		w.Header().Set("WWW-Authenticate", `Basic realm="RouterOS"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`<html><head><title>RouterOS router configuration page</title>
+			<link rel="shortcut icon" href="/static/icon.ico"></head></html>`))
+	})
+	mux.HandleFunc("/static/icon.ico", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(favicon)
+	})
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	info, err := probeHTTP(context.Background(), "127.0.0.1", port, false, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("probeHTTP() error: %v", err)
+	}
+
+	if info.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, expected 401", info.StatusCode)
+	}
+	if len(info.Redirects) != 1 {
+		t.Errorf("redirects = %v, expected one hop", info.Redirects)
+	}
+	if info.Title != "RouterOS router configuration page" {
+		t.Errorf("title = %q", info.Title)
+	}
+	if info.Server != "mikrotik/7.1" || info.PoweredBy != "PHP/8.1" {
+		t.Errorf("server = %q, powered by = %q", info.Server, info.PoweredBy)
+	}
+	if info.AuthScheme != "Basic" || info.AuthRealm != "RouterOS" {
+		t.Errorf("auth = %q realm %q", info.AuthScheme, info.AuthRealm)
+	}
+	if info.FaviconHash != faviconHash(favicon) {
+		t.Errorf("favicon hash = %d, expected %d", info.FaviconHash, faviconHash(favicon))
+	}
+
+	host := &HostInfo{IP: "127.0.0.1", Services: []ServiceInfo{{Port: port, Service: "HTTP", HTTP: info}}}
+	if !matchesSearch(host, "title:routeros") {
+		t.Error("expected title:routeros to match")
+	}
+	if matchesSearch(host, "title:synology") {
+		t.Error("title:synology should not match")
+	}
+}
+
+func TestProbeHTTPRedirectLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+	})
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	info, err := probeHTTP(context.Background(), "127.0.0.1", listener.Addr().(*net.TCPAddr).Port, false, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("probeHTTP() error: %v", err)
+	}
+
+	if info.StatusCode != http.StatusFound {
+		t.Errorf("status = %d, expected 302 after hitting the redirect limit", info.StatusCode)
+	}
+	if len(info.Redirects) > httpMaxRedirects+1 {
+		t.Errorf("followed %d redirects, limit is %d", len(info.Redirects), httpMaxRedirects)
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+type KeyMap struct {
+	Quit          key.Binding
+	Help          key.Binding
+	Up            key.Binding
+	Down          key.Binding
+	PageUp        key.Binding
+	PageDown      key.Binding
+	Home          key.Binding
+	End           key.Binding
+	Details       key.Binding
+	Actions       key.Binding
+	Search        key.Binding
+	FocusedSearch key.Binding
+	ClearSearch   key.Binding
+	Sort          key.Binding
+	ReverseSort   key.Binding
+	Ports         key.Binding
+	Group         key.Binding
+	Trace         key.Binding
+	Rescan        key.Binding
+	NewScan       key.Binding
+	Export        key.Binding
+	NextTab       key.Binding
+	PrevTab       key.Binding
+	CloseTab      key.Binding
+	SearchConfirm key.Binding
+	SearchCancel  key.Binding
+	SearchMode    key.Binding
+	CloseDetail   key.Binding
+	PrevHost      key.Binding
+	NextHost      key.Binding
+	Expand        key.Binding
+	ExpandAll     key.Binding
+	Back          key.Binding
+	Confirm       key.Binding
+	ToggleOption  key.Binding
+	NextField     key.Binding
+	PrevField     key.Binding
+	TraceMethod   key.Binding
+	HostRescan    key.Binding
+	DeepScan      key.Binding
+	CopyIP        key.Binding
+	CopyMAC       key.Binding
+	CopyHostname  key.Binding
+	ExportHost    key.Binding
+}
+
+var keys = defaultKeyMap()
+
+func defaultKeyMap() KeyMap {
+	return KeyMap{
+		Quit:          key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
+		Help:          key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "toggle help")),
+		Up:            key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+		Down:          key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+		PageUp:        key.NewBinding(key.WithKeys("pageup"), key.WithHelp("pgup", "page up")),
+		PageDown:      key.NewBinding(key.WithKeys("pagedown"), key.WithHelp("pgdn", "page down")),
+		Home:          key.NewBinding(key.WithKeys("home"), key.WithHelp("home", "first")),
+		End:           key.NewBinding(key.WithKeys("end"), key.WithHelp("end", "last")),
+		Details:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "host details")),
+		Actions:       key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "host actions")),
+		Search:        key.NewBinding(key.WithKeys("/", "f"), key.WithHelp("/", "search")),
+		FocusedSearch: key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "focused search")),
+		ClearSearch:   key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear search")),
+		Sort:          key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort column")),
+		ReverseSort:   key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "reverse sort")),
+		Ports:         key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "port view")),
+		Group:         key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "group hosts")),
+		Trace:         key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "traceroute")),
+		Rescan:        key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rescan")),
+		NewScan:       key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new scan")),
+		Export:        key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export")),
+		NextTab:       key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next scan")),
+		PrevTab:       key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous scan")),
+		CloseTab:      key.NewBinding(key.WithKeys("ctrl+w"), key.WithHelp("ctrl+w", "close scan")),
+		SearchConfirm: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "apply search")),
+		SearchCancel:  key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "leave search")),
+		SearchMode:    key.NewBinding(key.WithKeys("ctrl+f", "alt+f"), key.WithHelp("ctrl+f", "toggle focused mode")),
+		CloseDetail:   key.NewBinding(key.WithKeys("esc", "enter"), key.WithHelp("esc", "close details")),
+		PrevHost:      key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("←/h", "previous host")),
+		NextHost:      key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("→/l", "next host")),
+		Expand:        key.NewBinding(key.WithKeys("enter", " "), key.WithHelp("enter", "expand")),
+		ExpandAll:     key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "expand/collapse all")),
+		Back:          key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+		Confirm:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "confirm")),
+		ToggleOption:  key.NewBinding(key.WithKeys(" ", "x", "f"), key.WithHelp("space", "toggle option")),
+		NextField:     key.NewBinding(key.WithKeys("tab", "down"), key.WithHelp("tab/↓", "next field")),
+		PrevField:     key.NewBinding(key.WithKeys("shift+tab", "up"), key.WithHelp("shift+tab/↑", "previous field")),
+		TraceMethod:   key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "switch probe type")),
+		HostRescan:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rescan host")),
+		DeepScan:      key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "deep scan")),
+		CopyIP:        key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "copy IP")),
+		CopyMAC:       key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "copy MAC")),
+		CopyHostname:  key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "copy hostname")),
+		ExportHost:    key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export host")),
+	}
+}
+
+func (k *KeyMap) named() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":           &k.Quit,
+		"help":           &k.Help,
+		"up":             &k.Up,
+		"down":           &k.Down,
+		"page_up":        &k.PageUp,
+		"page_down":      &k.PageDown,
+		"home":           &k.Home,
+		"end":            &k.End,
+		"details":        &k.Details,
+		"actions":        &k.Actions,
+		"search":         &k.Search,
+		"focused_search": &k.FocusedSearch,
+		"clear_search":   &k.ClearSearch,
+		"sort":           &k.Sort,
+		"reverse_sort":   &k.ReverseSort,
+		"ports":          &k.Ports,
+		"group":          &k.Group,
+		"trace":          &k.Trace,
+		"rescan":         &k.Rescan,
+		"new_scan":       &k.NewScan,
+		"export":         &k.Export,
+		"next_tab":       &k.NextTab,
+		"prev_tab":       &k.PrevTab,
+		"close_tab":      &k.CloseTab,
+		"search_confirm": &k.SearchConfirm,
+		"search_cancel":  &k.SearchCancel,
+		"search_mode":    &k.SearchMode,
+		"close_detail":   &k.CloseDetail,
+		"prev_host":      &k.PrevHost,
+		"next_host":      &k.NextHost,
+		"expand":         &k.Expand,
+		"expand_all":     &k.ExpandAll,
+		"back":           &k.Back,
+		"confirm":        &k.Confirm,
+		"toggle_option":  &k.ToggleOption,
+		"next_field":     &k.NextField,
+		"prev_field":     &k.PrevField,
+		"trace_method":   &k.TraceMethod,
+		"host_rescan":    &k.HostRescan,
+		"deep_scan":      &k.DeepScan,
+		"copy_ip":        &k.CopyIP,
+		"copy_mac":       &k.CopyMAC,
+		"copy_hostname":  &k.CopyHostname,
+		"export_host":    &k.ExportHost,
+	}
+}
+
+func defaultKeyMapPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "viewnet", "keys.json")
+}
+
+func LoadKeyMap(path string) (KeyMap, error) {
+	keyMap := defaultKeyMap()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return keyMap, fmt.Errorf("failed to read key bindings: %v", err)
+	}
+	return keyMap, keyMap.apply(data)
+}
+
+func (k *KeyMap) apply(data []byte) error {
+	var overrides map[string][]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("invalid key bindings: %v", err)
+	}
+
+	bindings := k.named()
+	for name, keyList := range overrides {
+		binding, ok := bindings[name]
+		if !ok {
+			return fmt.Errorf("unknown key binding %q (valid: %s)", name, strings.Join(keyMapNames(), ", "))
+		}
+		if len(keyList) == 0 {
+			binding.Unbind()
+			continue
+		}
+		binding.SetKeys(keyList...)
+		binding.SetHelp(strings.Join(keyList, "/"), binding.Help().Desc)
+	}
+	return nil
+}
+
+func keyMapNames() []string {
+	var names []string
+	for name := range (&KeyMap{}).named() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func loadUserKeyMap(path string) error {
+	if path == "" {
+		path = defaultKeyMapPath()
+		if path == "" {
+			return nil
+		}
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+	keyMap, err := LoadKeyMap(path)
+	if err != nil {
+		return err
+	}
+	keys = keyMap
+	return nil
+}
+
+type helpSection struct {
+	title   string
+	columns [][]key.Binding
+}
+
+func (k KeyMap) helpSections() []helpSection {
+	return []helpSection{
+		{title: "Scanning", columns: [][]key.Binding{
+			{k.Up, k.Down, k.PageUp, k.PageDown, k.Home, k.End, k.Details, k.Actions},
+			{k.Sort, k.ReverseSort, k.Ports, k.Group},
+			{k.NewScan, k.NextTab, k.PrevTab, k.CloseTab, k.Help, k.Quit},
+		}},
+		{title: "Results", columns: [][]key.Binding{
+			{k.Up, k.Down, k.PageUp, k.PageDown, k.Home, k.End, k.Details, k.Actions},
+			{k.Search, k.FocusedSearch, k.ClearSearch, k.Sort, k.ReverseSort, k.Ports, k.Group},
+			{k.Trace, k.Rescan, k.NewScan, k.Export, k.NextTab, k.PrevTab, k.CloseTab, k.Help, k.Quit},
+		}},
+		{title: "Search", columns: [][]key.Binding{{k.SearchConfirm, k.SearchCancel, k.SearchMode}}},
+		{title: "Host details", columns: [][]key.Binding{
+			{k.Up, k.Down, k.PageUp, k.PageDown, k.Home},
+			{k.PrevHost, k.NextHost, k.Actions, k.CloseDetail, k.Help, k.Quit},
+		}},
+		{title: "Host actions", columns: [][]key.Binding{
+			{k.Up, k.Down, k.Confirm, k.Back},
+			{k.HostRescan, k.DeepScan, k.CopyIP, k.CopyMAC, k.CopyHostname, k.ExportHost},
+		}},
+		{title: "Port view", columns: [][]key.Binding{
+			{k.Up, k.Down, k.Home, k.End},
+			{k.Expand, k.ExpandAll, k.Search, k.Ports, k.Back, k.Help, k.Quit},
+		}},
+		{title: "Groups", columns: [][]key.Binding{
+			{k.Up, k.Down, k.Home, k.End},
+			{k.Expand, k.ExpandAll, k.Group, k.Search, k.Back, k.Help, k.Quit},
+		}},
+		{title: "Traceroute", columns: [][]key.Binding{{k.Trace, k.TraceMethod, k.Back, k.Help, k.Quit}}},
+		{title: "Export", columns: [][]key.Binding{{k.Up, k.Down, k.ToggleOption, k.Confirm, k.Back}}},
+		{title: "New scan", columns: [][]key.Binding{{k.NextField, k.PrevField, k.ToggleOption, k.Confirm, k.Back}}},
+		{title: "Interfaces", columns: [][]key.Binding{{k.Up, k.Down, k.Confirm, k.Quit}}},
+	}
+}
+
+func helpContext(model *UIModel) string {
+	switch {
+	case model.state == stateSelectInterface:
+		return "Interfaces"
+	case model.formVisible:
+		return "New scan"
+	case model.exportVisible:
+		return "Export"
+	case model.actionsVisible:
+		return "Host actions"
+	case model.traceVisible:
+		return "Traceroute"
+	case model.searchFocused:
+		return "Search"
+	case model.detailVisible:
+		return "Host details"
+	case model.portView:
+		return "Port view"
+	case model.groupBy != "":
+		return "Groups"
+	case model.state == stateScanning:
+		return "Scanning"
+	default:
+		return "Results"
+	}
+}
+
+func (k KeyMap) shortHelp(model *UIModel) []key.Binding {
+	switch helpContext(model) {
+	case "Interfaces":
+		return []key.Binding{k.Up, k.Down, k.Confirm, k.Quit}
+	case "New scan":
+		return []key.Binding{k.NextField, k.PrevField, k.ToggleOption, k.Confirm, k.Back}
+	case "Export":
+		if model.exportNaming {
+			return []key.Binding{k.Confirm, k.Back}
+		}
+		return []key.Binding{k.Up, k.Down, k.ToggleOption, k.Confirm, k.Back}
+	case "Host actions":
+		return []key.Binding{k.Up, k.Down, k.Confirm, k.Back}
+	case "Traceroute":
+		return []key.Binding{k.Trace, k.TraceMethod, k.Back, k.Help, k.Quit}
+	case "Search":
+		return []key.Binding{k.SearchConfirm, k.SearchCancel, k.SearchMode}
+	case "Host details":
+		return []key.Binding{k.Up, k.Down, k.PrevHost, k.NextHost, k.Actions, k.CloseDetail, k.Help}
+	case "Port view":
+		return []key.Binding{k.Up, k.Down, k.Expand, k.ExpandAll, k.Search, k.Back, k.Help}
+	case "Groups":
+		return []key.Binding{k.Up, k.Down, k.Expand, k.ExpandAll, k.Group, k.Back, k.Help}
+	case "Scanning":
+		return []key.Binding{k.Up, k.Down, k.Details, k.Actions, k.NewScan, k.Help, k.Quit}
+	default:
+		return []key.Binding{k.Up, k.Down, k.Details, k.Actions, k.Search, k.Export, k.NewScan, k.Help, k.Quit}
+	}
+}
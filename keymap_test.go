@@ -0,0 +1,159 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+)
+
+func TestLoadKeyMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+		check   func(t *testing.T, k KeyMap)
+	}{
+		{
+			name:   "override and unbind",
+			config: `{"quit": ["x", "ctrl+q"], "trace": [], "search": ["s"]}`,
+			check: func(t *testing.T, k KeyMap) {
+				if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}, k.Quit) {
+					t.Error("x should quit")
+				}
+				if key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}, k.Quit) {
+					t.Error("q should no longer quit")
+				}
+				if k.Quit.Help().Key != "x/ctrl+q" || k.Quit.Help().Desc != "quit" {
+					t.Errorf("help should show the new keys, got %+v", k.Quit.Help())
+				}
+				if k.Trace.Enabled() {
+					t.Error("an empty list should disable the binding")
+				}
+				if !key.Matches(tea.KeyMsg{Type: tea.KeyDown}, k.Down) {
+					t.Error("bindings that are not overridden keep their defaults")
+				}
+			},
+		},
+		{name: "unknown binding", config: `{"launch": ["l"]}`, wantErr: `unknown key binding "launch"`},
+		{name: "invalid json", config: `{"quit": "x"}`, wantErr: "invalid key bindings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys.json")
+			if err := os.WriteFile(path, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			keyMap, err := LoadKeyMap(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, keyMap)
+		})
+	}
+}
+
+func TestHelpOverlay(t *testing.T) {
+	defer func() { keys = defaultKeyMap() }()
+
+	model := NewModularUI("10.0.0.0/24", 1, 1024, 200, false, "", nil, false)
+	model.state = stateComplete
+	model.results = []*HostInfo{{IP: "10.0.0.1"}}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	if !model.helpVisible {
+		t.Fatal("? should open the help overlay")
+	}
+	view := model.View()
+	for _, want := range []string{"Scanning", "▸ Results (current)", "Search", "Host details", "apply search", "next host", "traceroute"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("overlay should contain %q:\n%s", want, view)
+		}
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if model.cursor != 0 || !model.helpVisible {
+		t.Error("keys should not reach the table while the overlay is open")
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.helpVisible {
+		t.Fatal("esc should close the overlay")
+	}
+
+	model.detailVisible = true
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	if !strings.Contains(model.View(), "▸ Host details (current)") {
+		t.Error("the overlay should highlight the detail bindings when opened from the detail pane")
+	}
+	model.helpVisible = false
+	model.detailVisible = false
+
+	if err := keys.apply([]byte(`{"export": ["E"], "sort": ["o"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	footer := model.help.View(model.UIModel)
+	if !strings.Contains(footer, "E export") || !strings.Contains(footer, "? toggle help") {
+		t.Errorf("footer should be generated from the active keymap: %s", footer)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	if model.sortColumn == sortByIP {
+		t.Error("the remapped sort key should cycle the sort column")
+	}
+}
+
+func TestRemappedViewKeys(t *testing.T) {
+	defer func() { keys = defaultKeyMap() }()
+
+	model := NewModularUI("10.0.0.0/24", 1, 1024, 200, false, "", nil, false)
+	model.state = stateComplete
+	model.results = []*HostInfo{
+		{IP: "10.0.0.1", Services: []ServiceInfo{{Port: 22}, {Port: 80}}},
+		{IP: "10.0.0.2", Services: []ServiceInfo{{Port: 443}}},
+	}
+	if err := keys.apply([]byte(`{"up": ["w"], "down": ["z"], "ports": ["P"], "expand_all": ["X"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	press := func(s string) { model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}) }
+
+	press("P")
+	if !model.portView {
+		t.Fatal("the remapped ports key should open the port view")
+	}
+	press("z")
+	press("z")
+	press("w")
+	if model.portCursor != 1 {
+		t.Errorf("the remapped up and down keys should move the port cursor, got %d", model.portCursor)
+	}
+	press("X")
+	if len(model.portExpanded) == 0 || !model.portExpanded[80] {
+		t.Errorf("the remapped expand_all key should expand every port, got %v", model.portExpanded)
+	}
+	footer := model.help.View(model.UIModel)
+	for _, want := range []string{"w up", "z down", "X expand/collapse all"} {
+		if !strings.Contains(footer, want) {
+			t.Errorf("port view footer should contain %q: %s", want, footer)
+		}
+	}
+	press("?")
+	view := model.View()
+	for _, want := range []string{"▸ Port view (current)", "Groups", "Traceroute", "Host actions", "New scan", "switch probe type"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("overlay should contain %q:\n%s", want, view)
+		}
+	}
+	press("?")
+	press("P")
+	if model.portView {
+		t.Error("the remapped ports key should close the port view")
+	}
+}
@@ -45,40 +45,90 @@ func parsePortList(portStr string) ([]int, error) {
 	return ports, nil
 }
 
-func sortHostsByIP(hosts []*HostInfo) {
-	sort.Slice(hosts, func(i, j int) bool {
-		ipI := net.ParseIP(hosts[i].IP)
-		ipJ := net.ParseIP(hosts[j].IP)
-		if ipI == nil || ipJ == nil {
-			return hosts[i].IP < hosts[j].IP
-		}
+func parsePortSpec(spec string) (int, int, []int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "common" {
+		return 0, 0, getCommonPorts(), nil
+	}
 
-		ipI = ipI.To4()
-		ipJ = ipJ.To4()
-		if ipI == nil || ipJ == nil {
-			return hosts[i].IP < hosts[j].IP
+	if low, high, isRange := strings.Cut(spec, "-"); isRange && !strings.Contains(spec, ",") {
+		start, errStart := strconv.Atoi(strings.TrimSpace(low))
+		end, errEnd := strconv.Atoi(strings.TrimSpace(high))
+		if errStart != nil || errEnd != nil || start < 1 || end > 65535 || start > end {
+			return 0, 0, nil, fmt.Errorf("invalid port range '%s' (use e.g. 1-1024)", spec)
 		}
+		return start, end, nil, nil
+	}
 
-		for k := range 4 {
-			if ipI[k] != ipJ[k] {
-				return ipI[k] < ipJ[k]
-			}
+	ports, err := parsePortList(spec)
+	return 0, 0, ports, err
+}
+
+func normalizeTarget(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if !strings.Contains(target, "/") {
+		target += "/32"
+	}
+	if _, _, err := net.ParseCIDR(target); err != nil {
+		return "", fmt.Errorf("invalid target %q (use an IP address or CIDR)", strings.TrimSuffix(target, "/32"))
+	}
+	return target, nil
+}
+
+func chooseDiscoveryMethod(requested string) (string, bool, error) {
+	switch requested {
+	case "":
+		if activeProxy != nil {
+			return discoveryTCP, false, nil
+		}
+		return discoveryICMP, false, nil
+	case discoveryICMP:
+		if activeProxy != nil {
+			return discoveryTCP, true, nil
 		}
-		return false
+		return discoveryICMP, false, nil
+	case discoveryTCP:
+		return discoveryTCP, false, nil
+	default:
+		return "", false, fmt.Errorf("unknown discovery method %q (use icmp or tcp)", requested)
+	}
+}
+
+func sortHostsByIP(hosts []*HostInfo) {
+	sort.Slice(hosts, func(i, j int) bool {
+		return lessIP(hosts[i].IP, hosts[j].IP)
 	})
 }
 
-func exportToCSV(filename string, hosts []*HostInfo) error {
+func lessIP(a, b string) bool {
+	ipA := net.ParseIP(a).To4()
+	ipB := net.ParseIP(b).To4()
+	if ipA == nil || ipB == nil {
+		return a < b
+	}
+
+	for k := range 4 {
+		if ipA[k] != ipB[k] {
+			return ipA[k] < ipB[k]
+		}
+	}
+	return false
+}
+
+func exportToCSV(filename string, hosts []*HostInfo) (err error) {
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
 
-	header := []string{"IP Address", "MAC Address", "Vendor", "Hostname", "Is Reachable", "Response Time (ms)", "Open Ports", "Services"}
+	header := []string{"IP Address", "MAC Address", "Vendor", "Hostname", "Is Reachable", "Response Time (ms)", "Open Ports", "Services", "Device Type", "Device Confidence", "OS Family", "OS Confidence", "Discovery Method"}
 	if err := writer.Write(header); err != nil {
 		return err
 	}
@@ -105,6 +155,11 @@ func exportToCSV(filename string, hosts []*HostInfo) error {
 			fmt.Sprintf("%.2f", float64(host.ResponseTime.Nanoseconds())/1000000.0),
 			strings.Join(portList, ";"),
 			strings.Join(serviceList, ";"),
+			deviceTypeLabel(host.DeviceType),
+			fmt.Sprintf("%d", host.DeviceConfidence),
+			host.OSFamily,
+			fmt.Sprintf("%d", host.OSConfidence),
+			host.DiscoveryMethod,
 		}
 
 		if err := writer.Write(row); err != nil {
@@ -112,10 +167,11 @@ func exportToCSV(filename string, hosts []*HostInfo) error {
 		}
 	}
 
-	return nil
+	writer.Flush()
+	return writer.Error()
 }
 
-func runNonInteractiveMode(targetSubnet string, startPort, endPort, timeoutMs int, customPorts []int, ipsOnly bool, csvFile string) {
+func runNonInteractiveMode(targetSubnet string, startPort, endPort, timeoutMs int, customPorts []int, ipsOnly bool, csvFile string, certDays int, filter *HostQuery, matrixFile string) {
 	timeout := time.Duration(timeoutMs) * time.Millisecond
 
 	fmt.Printf("🔍 ViewNet - Non-Interactive Mode\n")
@@ -129,7 +185,21 @@ func runNonInteractiveMode(targetSubnet string, startPort, endPort, timeoutMs in
 		fmt.Printf("Ports: %d-%d", startPort, endPort)
 	}
 	fmt.Printf(" | Timeout: %dms\n", timeoutMs)
-	fmt.Printf("Output: %s\n\n", csvFile)
+	if activeProxy != nil {
+		fmt.Printf("🧦 Proxy: %s | Discovery: %s\n", activeProxy, discoveryMethod)
+	} else if netContext := getNetworkContext(); netContext != nil {
+		fmt.Printf("🌐 %s\n", netContext.Summary())
+	}
+	if csvFile != "" {
+		fmt.Printf("Output: %s\n", csvFile)
+	}
+	if matrixFile != "" {
+		fmt.Printf("Port matrix: %s\n", matrixFile)
+	}
+	if filter != nil {
+		fmt.Printf("Filter: %s\n", filter)
+	}
+	fmt.Println()
 
 	_, ipnet, err := net.ParseCIDR(targetSubnet)
 	if err != nil {
@@ -147,6 +217,8 @@ func runNonInteractiveMode(targetSubnet string, startPort, endPort, timeoutMs in
 	startTime := time.Now()
 	var results []*HostInfo
 
+	discovery := startDiscovery()
+
 	if ipsOnly || len(customPorts) > 0 {
 		results = scanHostsNonInteractive(ips, startPort, endPort, timeout, customPorts, ipsOnly)
 	} else {
@@ -154,6 +226,11 @@ func runNonInteractiveMode(targetSubnet string, startPort, endPort, timeoutMs in
 		results = scanner.ScanSubnet(targetSubnet, startPort, endPort, timeout)
 	}
 
+	discovery.Close(context.Background())
+	for _, host := range results {
+		discovery.Apply(host)
+	}
+
 	duration := time.Since(startTime)
 
 	activeHosts := 0
@@ -168,8 +245,30 @@ func runNonInteractiveMode(targetSubnet string, startPort, endPort, timeoutMs in
 	fmt.Printf("✅ Scan completed in %v\n", duration.Round(time.Millisecond))
 	fmt.Printf("📈 Results: %d active hosts, %d open ports\n", activeHosts, totalPorts)
 
+	if filter != nil {
+		matched := filterHosts(results, filter)
+		fmt.Printf("🔎 Filter matched %d of %d hosts\n", len(matched), len(results))
+		results = matched
+	}
+
 	sortHostsByIP(results)
 
+	if certDays > 0 {
+		printCertificateReport(results, certDays)
+	}
+
+	if matrixFile != "" {
+		if err := exportPortMatrix(matrixFile, results); err != nil {
+			fmt.Printf("❌ Error exporting port matrix: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("📄 Port matrix exported to %s\n", matrixFile)
+	}
+
+	if csvFile == "" {
+		return
+	}
+
 	if err := exportToCSV(csvFile, results); err != nil {
 		fmt.Printf("❌ Error exporting to CSV: %v\n", err)
 		os.Exit(1)
@@ -218,7 +317,135 @@ func scanHostsNonInteractive(ips []string, startPort, endPort int, timeout time.
 	return results
 }
 
+func expandTraceTargets(args []string) ([]string, error) {
+	var targets []string
+	for _, arg := range args {
+		if strings.Contains(arg, "/") {
+			_, ipnet, err := net.ParseCIDR(arg)
+			if err != nil {
+				return nil, err
+			}
+			for ip := ipnet.IP.Mask(ipnet.Mask); ipnet.Contains(ip); inc(ip) {
+				targets = append(targets, ip.String())
+			}
+			continue
+		}
+		if ip := net.ParseIP(arg); ip != nil {
+			targets = append(targets, ip.String())
+			continue
+		}
+
+		addrs, err := net.LookupIP(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if addr.To4() != nil {
+				targets = append(targets, addr.String())
+				break
+			}
+		}
+	}
+	return targets, nil
+}
+
+func runTraceCommand(args []string) {
+	fs := flag.NewFlagSet("trace", flag.ExitOnError)
+	method := fs.String("method", traceUDP, "probe type: udp, icmp or tcp")
+	port := fs.Int("port", 0, "destination port (udp base port, default 33434; tcp port, default 80)")
+	maxHops := fs.Int("max-hops", traceDefaultMaxHops, "maximum TTL")
+	probes := fs.Int("probes", traceDefaultProbes, "probes per hop")
+	timeoutMs := fs.Int("timeout", 1000, "ms to wait for replies per round")
+	ifaceName := fs.String("i", "", "network interface to trace from (e.g., eth0)")
+	sourceAddr := fs.String("source-ip", "", "local address to send probes from")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: viewnet trace [flags] <ip|host|cidr>...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *ifaceName != "" {
+		if err := selectInterface(*ifaceName); err != nil {
+			fmt.Printf("❌ Error selecting interface: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *sourceAddr != "" {
+		if err := setSourceIP(*sourceAddr); err != nil {
+			fmt.Printf("❌ Error setting source address: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	targets, err := expandTraceTargets(fs.Args())
+	if err != nil {
+		fmt.Printf("❌ Error parsing targets: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := TraceOptions{
+		Method:  *method,
+		MaxHops: *maxHops,
+		Probes:  *probes,
+		Port:    *port,
+		Timeout: time.Duration(*timeoutMs) * time.Millisecond,
+	}
+
+	results := make([]*TraceResult, len(targets))
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 8)
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = traceRoute(context.Background(), target, opts)
+		}(i, target)
+	}
+	wg.Wait()
+
+	var traces []*TraceResult
+	for i, result := range results {
+		if errs[i] != nil {
+			fmt.Printf("❌ %s: %v\n", targets[i], errs[i])
+			continue
+		}
+		for _, line := range result.Lines() {
+			fmt.Println(line)
+		}
+		fmt.Println()
+		traces = append(traces, result)
+	}
+
+	if len(traces) > 1 {
+		fmt.Println("🗺️  Topology")
+		for _, line := range renderTopology(buildTopology(traces)) {
+			fmt.Println(line)
+		}
+	}
+	if len(traces) == 0 {
+		os.Exit(1)
+	}
+}
+
+func reportSSHHostKeyError() {
+	if err := sshHostKeyStoreError(); err != nil {
+		fmt.Printf("⚠️  SSH host key changes were not saved: %v\n", err)
+	}
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "trace" {
+		runTraceCommand(os.Args[2:])
+		return
+	}
+
 	subnet := flag.String("subnet", "", "CIDR to scan (auto-detects local subnet if empty)")
 	startPort := flag.Int("start", 1, "start port")
 	endPort := flag.Int("end", 1024, "end port")
@@ -228,9 +455,105 @@ func main() {
 	focusedSearch := flag.Bool("focused", false, "enable focused search mode (IP and vendor only)")
 	searchTerm := flag.String("s", "", "search term for IP or vendor (speeds up search)")
 	csvOutput := flag.String("csv", "", "output results to CSV file (e.g., results.csv)")
+	portMatrix := flag.String("port-matrix", "", "write a port-by-host matrix CSV (hosts as rows, open ports as columns)")
+	synScan := flag.Bool("syn", false, "use raw-socket SYN scanning (requires root/CAP_NET_RAW, falls back to connect scan)")
+	synDetect := flag.Bool("syn-detect", false, "after a SYN scan, connect to open ports for service detection (completes the TCP handshake)")
+	certReport := flag.Int("cert-report", 0, "list TLS certificates expiring within N days or self-signed (non-interactive)")
+	probesFile := flag.String("probes", "", "service detection probe database (JSON, uses bundled default if empty)")
+	deviceRules := flag.String("device-rules", "", "additional device classification rules (JSON, merged with the bundled rules)")
+	snmpCommunity := flag.String("snmp-community", "public", "comma-separated SNMP communities to try (empty disables SNMP)")
+	ifaceName := flag.String("i", "", "network interface for subnet auto-detection, ARP and raw sockets (e.g., eth0)")
+	sourceAddr := flag.String("source-ip", "", "local address to bind outgoing connections to")
+	proxyURL := flag.String("proxy", "", "route TCP scans through a proxy: socks5://[user:pass@]host:port or http://[user:pass@]host:port (default $VIEWNET_PROXY)")
+	filterQuery := flag.String("filter", "", "only report hosts matching a query, e.g. 'port:22 vendor:apple' or 'net:10.0.1.0/26 !port:23' (also prefills the TUI search)")
+	discovery := flag.String("discovery", "", "host discovery method: icmp or tcp (default icmp, tcp when a proxy is used)")
+	keyBindings := flag.String("keys", "", "TUI key binding overrides (JSON, default <config dir>/viewnet/keys.json when present)")
+	hostKeyFile := flag.String("hostkeys", "", "file recording SSH host key fingerprints to detect key changes (default <config dir>/viewnet/ssh_hostkeys.json)")
+	acceptHostKeys := flag.Bool("accept-hostkeys", false, "replace recorded SSH host key fingerprints with the keys seen in this scan")
 	flag.Parse()
+
+	if *proxyURL == "" {
+		*proxyURL = proxyFromEnvironment()
+	}
+	if *proxyURL != "" {
+		proxy, err := parseProxyURL(*proxyURL)
+		if err != nil {
+			fmt.Printf("❌ Error configuring proxy: %v\n", err)
+			os.Exit(1)
+		}
+		activeProxy = proxy
+	}
+
+	method, forced, err := chooseDiscoveryMethod(*discovery)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if forced {
+		fmt.Printf("⚠️  ICMP cannot be proxied, using TCP discovery\n")
+	}
+	discoveryMethod = method
+
+	if *ifaceName != "" {
+		if err := selectInterface(*ifaceName); err != nil {
+			fmt.Printf("❌ Error selecting interface: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *sourceAddr != "" {
+		if err := setSourceIP(*sourceAddr); err != nil {
+			fmt.Printf("❌ Error setting source address: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	snmpCommunities = nil
+	for _, community := range strings.Split(*snmpCommunity, ",") {
+		if community = strings.TrimSpace(community); community != "" {
+			snmpCommunities = append(snmpCommunities, community)
+		}
+	}
+
+	if *probesFile != "" {
+		db, err := LoadProbeDatabase(*probesFile)
+		if err != nil {
+			fmt.Printf("❌ Error loading probe database: %v\n", err)
+			os.Exit(1)
+		}
+		activeProbeDB = db
+	}
+
+	if *hostKeyFile != "" {
+		sshHostKeyFile = *hostKeyFile
+	}
+	sshAcceptHostKeys = *acceptHostKeys
+	if err := loadSSHHostKeyStore(); err != nil {
+		fmt.Printf("❌ Error loading SSH host keys: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *deviceRules != "" {
+		rules, err := LoadDeviceRules(*deviceRules)
+		if err != nil {
+			fmt.Printf("❌ Error loading device rules: %v\n", err)
+			os.Exit(1)
+		}
+		activeDeviceRules = rules
+	}
+
+	if *synScan && activeProxy != nil {
+		fmt.Printf("⚠️  SYN scan cannot be proxied, using connect scan through %s\n", activeProxy.Address)
+	} else if *synScan && !*ipsOnly {
+		scanner, err := NewSYNScanner()
+		if err != nil {
+			fmt.Printf("⚠️  SYN scan unavailable, falling back to connect scan: %v\n", err)
+		} else {
+			activeSYNScanner = scanner
+			synServiceDetection = *synDetect
+			defer scanner.Close()
+		}
+	}
 	var customPorts []int
-	var err error
 	if *portList != "" {
 		customPorts, err = parsePortList(*portList)
 		if err != nil {
@@ -241,51 +564,67 @@ func main() {
 		customPorts = getCommonPorts()
 	}
 
+	var filter *HostQuery
+	if *filterQuery != "" {
+		filter, err = parseQuery(*filterQuery)
+		if err != nil {
+			fmt.Printf("❌ Error parsing filter: %v\n", err)
+			os.Exit(1)
+		}
+		if *searchTerm == "" {
+			*searchTerm = *filterQuery
+		}
+	}
+
+	nonInteractive := *csvOutput != "" || *certReport > 0 || *portMatrix != ""
+
 	targetSubnet := *subnet
+	var interfaceChoices []InterfaceSubnet
 	args := flag.Args()
 	if len(args) > 0 {
-		targetSubnet = args[0]
-		if !strings.Contains(targetSubnet, "/") {
-			targetSubnet = targetSubnet + "/32"
-		}
-	} else if targetSubnet == "" {
-		detected, err := getLocalSubnet()
+		targetSubnet, err = normalizeTarget(args[0])
 		if err != nil {
-			fmt.Printf("❌ Error detecting local subnet: %v\n", err)
-			fmt.Printf("Please specify a subnet manually using -subnet flag or as an argument\n")
+			fmt.Printf("❌ %v\n", err)
 			os.Exit(1)
 		}
-		targetSubnet = detected
+	} else if targetSubnet == "" {
+		candidates := listInterfaceSubnets()
+		if *ifaceName == "" && !nonInteractive && len(candidates) > 1 {
+			interfaceChoices = candidates
+		} else {
+			detected, err := getLocalSubnet()
+			if err != nil {
+				fmt.Printf("❌ Error detecting local subnet: %v\n", err)
+				fmt.Printf("Please specify a subnet manually using -subnet flag or as an argument\n")
+				os.Exit(1)
+			}
+			targetSubnet = detected
+		}
 	}
-	if *csvOutput != "" {
-		runNonInteractiveMode(targetSubnet, *startPort, *endPort, *timeoutMs, customPorts, *ipsOnly, *csvOutput)
+	if nonInteractive {
+		runNonInteractiveMode(targetSubnet, *startPort, *endPort, *timeoutMs, customPorts, *ipsOnly, *csvOutput, *certReport, filter, *portMatrix)
+		reportSSHHostKeyError()
 		return
 	}
 
+	if err := loadUserKeyMap(*keyBindings); err != nil {
+		fmt.Printf("❌ Error loading key bindings: %v\n", err)
+		os.Exit(1)
+	}
+
 	model := NewModularUI(targetSubnet, *startPort, *endPort, *timeoutMs, *focusedSearch, *searchTerm, customPorts, *ipsOnly)
-	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if len(interfaceChoices) > 0 {
+		model.promptInterface(interfaceChoices)
+	}
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(programOutput))
 	finalModel, err := p.Run()
 	if err != nil {
 		fmt.Printf("Error running TUI: %v\n", err)
 		os.Exit(1)
 	}
+	reportSSHHostKeyError()
 	if m, ok := finalModel.(*ModularUIModel); ok && m.quitting && m.err != nil {
 		fmt.Printf("❌ Error: %v\n", m.err)
 		os.Exit(1)
 	}
-
-	if *csvOutput != "" {
-		results := GetScanResults()
-		if len(results) > 0 {
-			sortHostsByIP(results)
-
-			if err := exportToCSV(*csvOutput, results); err != nil {
-				fmt.Printf("❌ Error exporting to CSV: %v\n", err)
-			} else {
-				fmt.Printf("✅ Results exported to %s\n", *csvOutput)
-			}
-		} else {
-			fmt.Printf("⚠️  No results to export\n")
-		}
-	}
 }
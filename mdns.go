@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	mdnsServiceEnumeration = "_services._dns-sd._udp.local"
+	mdnsPort               = 5353
+	llmnrPort              = 5355
+)
+
+var (
+	mdnsGroupAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: mdnsPort}
+	mdnsQueryRuns = []time.Duration{0, time.Second, 3 * time.Second}
+)
+
+type MDNSService struct {
+	Instance string
+	Type     string
+	Port     int
+	Target   string
+	TXT      []string
+}
+
+type MDNSInfo struct {
+	Hostname string
+	Source   string
+	Services []MDNSService
+}
+
+type mdnsSRV struct {
+	target string
+	port   int
+}
+
+type MulticastDiscovery struct {
+	mu           sync.Mutex
+	conn         *net.UDPConn
+	listener     *net.UDPConn
+	addresses    map[string][]string
+	serviceTypes map[string]bool
+	instances    map[string]string
+	srv          map[string]mdnsSRV
+	txt          map[string][]string
+	sources      map[string]string
+	done         chan struct{}
+	wg           sync.WaitGroup
+}
+
+func newMulticastDiscovery() *MulticastDiscovery {
+	return &MulticastDiscovery{
+		addresses:    make(map[string][]string),
+		serviceTypes: make(map[string]bool),
+		instances:    make(map[string]string),
+		srv:          make(map[string]mdnsSRV),
+		txt:          make(map[string][]string),
+		sources:      make(map[string]string),
+		done:         make(chan struct{}),
+	}
+}
+
+func StartMulticastDiscovery() (*MulticastDiscovery, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: scanSourceIP()})
+	if err != nil {
+		return nil, err
+	}
+
+	d := newMulticastDiscovery()
+	d.conn = conn
+
+	if listener, err := net.ListenMulticastUDP("udp4", multicastInterface(), mdnsGroupAddr); err == nil {
+		d.listener = listener
+		d.wg.Add(1)
+		go d.receiveLoop(listener)
+	}
+
+	d.wg.Add(2)
+	go d.receiveLoop(conn)
+	go d.queryLoop()
+
+	return d, nil
+}
+
+func (d *MulticastDiscovery) Close() {
+	close(d.done)
+	d.conn.Close()
+	if d.listener != nil {
+		d.listener.Close()
+	}
+	d.wg.Wait()
+}
+
+func (d *MulticastDiscovery) queryLoop() {
+	defer d.wg.Done()
+
+	start := time.Now()
+	for _, at := range mdnsQueryRuns {
+		select {
+		case <-d.done:
+			return
+		case <-time.After(time.Until(start.Add(at))):
+		}
+		d.sendQuery(dnsQuestion{Name: mdnsServiceEnumeration, Type: dnsTypePTR, Class: dnsClassIN | dnsUnicastReply})
+	}
+}
+
+func (d *MulticastDiscovery) sendQuery(questions ...dnsQuestion) {
+	d.conn.WriteToUDP(buildDNSQuery(0, questions...), mdnsGroupAddr)
+}
+
+func (d *MulticastDiscovery) receiveLoop(conn *net.UDPConn) {
+	defer d.wg.Done()
+
+	buffer := make([]byte, 9000)
+	for {
+		n, addr, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			select {
+			case <-d.done:
+				return
+			default:
+			}
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			return
+		}
+
+		msg, err := parseDNSMessage(buffer[:n])
+		if err != nil || !msg.IsResponse() {
+			continue
+		}
+		if followUp := d.handleMessage(msg, addr.IP.String()); len(followUp) > 0 {
+			d.sendQuery(followUp...)
+		}
+	}
+}
+
+func (d *MulticastDiscovery) handleMessage(msg *dnsMessage, source string) []dnsQuestion {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var followUp []dnsQuestion
+	for _, record := range msg.Records {
+		name := strings.ToLower(record.Name)
+
+		switch record.Type {
+		case dnsTypeA:
+			if record.IP == nil {
+				continue
+			}
+			ip := record.IP.String()
+			if !slices.Contains(d.addresses[name], ip) {
+				d.addresses[name] = append(d.addresses[name], ip)
+			}
+		case dnsTypePTR:
+			target := record.Target
+			switch {
+			case name == mdnsServiceEnumeration:
+				if !d.serviceTypes[strings.ToLower(target)] {
+					d.serviceTypes[strings.ToLower(target)] = true
+					followUp = append(followUp, dnsQuestion{Name: target, Type: dnsTypePTR, Class: dnsClassIN | dnsUnicastReply})
+				}
+			case strings.HasSuffix(name, ".in-addr.arpa"):
+				ip := reverseNameToIP(name)
+				host := strings.ToLower(target)
+				if ip != "" && !slices.Contains(d.addresses[host], ip) {
+					d.addresses[host] = append(d.addresses[host], ip)
+				}
+			default:
+				if _, seen := d.instances[target]; !seen {
+					d.instances[target] = record.Name
+					d.sources[target] = source
+					followUp = append(followUp, dnsQuestion{Name: target, Type: dnsTypeANY, Class: dnsClassIN | dnsUnicastReply})
+				}
+			}
+		case dnsTypeSRV:
+			d.srv[record.Name] = mdnsSRV{target: strings.ToLower(record.Target), port: int(record.Port)}
+			if _, seen := d.sources[record.Name]; !seen {
+				d.sources[record.Name] = source
+			}
+		case dnsTypeTXT:
+			if len(record.TXT) > 0 {
+				d.txt[record.Name] = record.TXT
+			}
+		}
+	}
+
+	return followUp
+}
+
+func reverseNameToIP(name string) string {
+	labels := strings.Split(strings.TrimSuffix(name, ".in-addr.arpa"), ".")
+	if len(labels) != 4 {
+		return ""
+	}
+	slices.Reverse(labels)
+	ip := net.ParseIP(strings.Join(labels, "."))
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+func (d *MulticastDiscovery) Lookup(ip string) *MDNSInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	info := &MDNSInfo{Source: "mDNS"}
+	var hostnames []string
+	for name, addresses := range d.addresses {
+		if slices.Contains(addresses, ip) {
+			hostnames = append(hostnames, name)
+		}
+	}
+	sort.Strings(hostnames)
+	if len(hostnames) > 0 {
+		info.Hostname = sanitizeText(hostnames[0], 0)
+	}
+
+	for instance, serviceType := range d.instances {
+		srv, hasSRV := d.srv[instance]
+		if hasSRV && len(d.addresses[srv.target]) > 0 {
+			if !slices.Contains(d.addresses[srv.target], ip) {
+				continue
+			}
+		} else if d.sources[instance] != ip {
+			continue
+		}
+
+		if info.Hostname == "" && srv.target != "" {
+			info.Hostname = sanitizeText(srv.target, 0)
+		}
+		var txt []string
+		for _, entry := range d.txt[instance] {
+			txt = append(txt, sanitizeText(entry, 100))
+		}
+		info.Services = append(info.Services, MDNSService{
+			Instance: sanitizeText(strings.TrimSuffix(instance, "."+serviceType), 0),
+			Type:     sanitizeText(strings.TrimSuffix(serviceType, ".local"), 0),
+			Port:     srv.port,
+			Target:   sanitizeText(srv.target, 0),
+			TXT:      txt,
+		})
+	}
+
+	if info.Hostname == "" && len(info.Services) == 0 {
+		return nil
+	}
+
+	sort.Slice(info.Services, func(i, j int) bool {
+		if info.Services[i].Type != info.Services[j].Type {
+			return info.Services[i].Type < info.Services[j].Type
+		}
+		return info.Services[i].Instance < info.Services[j].Instance
+	})
+	return info
+}
+
+func (d *MulticastDiscovery) Apply(host *HostInfo) {
+	info := d.Lookup(host.IP)
+	if info == nil {
+		return
+	}
+
+	if info.Hostname == "" && host.MDNS != nil {
+		info.Hostname, info.Source = host.MDNS.Hostname, host.MDNS.Source
+	}
+	host.MDNS = info
+	if host.Hostname == "" {
+		host.Hostname = info.Hostname
+	}
+}
+
+func resolveMulticastName(ctx context.Context, ip string, timeout time.Duration) (*MDNSInfo, error) {
+	if name, err := queryReverseName(ctx, net.JoinHostPort(ip, fmt.Sprintf("%d", mdnsPort)), ip, timeout); err == nil {
+		return &MDNSInfo{Hostname: name, Source: "mDNS"}, nil
+	}
+	name, err := queryReverseName(ctx, net.JoinHostPort(ip, fmt.Sprintf("%d", llmnrPort)), ip, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &MDNSInfo{Hostname: name, Source: "LLMNR"}, nil
+}
+
+func queryReverseName(ctx context.Context, address, ip string, timeout time.Duration) (string, error) {
+	reverse := reverseDNSName(ip)
+	if reverse == "" {
+		return "", fmt.Errorf("invalid IPv4 address %q", ip)
+	}
+
+	d := newDialer("udp", timeout)
+	conn, err := d.DialContext(ctx, "udp", address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	id := uint16(rand.IntN(0xffff) + 1)
+	if _, err := conn.Write(buildDNSQuery(id, dnsQuestion{Name: reverse, Type: dnsTypePTR, Class: dnsClassIN})); err != nil {
+		return "", err
+	}
+
+	buffer := make([]byte, 1500)
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		n, err := conn.Read(buffer)
+		if err != nil {
+			return "", err
+		}
+
+		msg, err := parseDNSMessage(buffer[:n])
+		if err != nil || !msg.IsResponse() || msg.ID != id {
+			continue
+		}
+		for _, record := range msg.Records {
+			if record.Type == dnsTypePTR && strings.EqualFold(record.Name, reverse) && record.Target != "" {
+				return sanitizeText(record.Target, 0), nil
+			}
+		}
+		return "", fmt.Errorf("no PTR answer from %s", address)
+	}
+}
+
+func (s MDNSService) Summary() string {
+	text := s.Type
+	if s.Instance != "" {
+		text += fmt.Sprintf(" %q", s.Instance)
+	}
+	if s.Port > 0 {
+		text += fmt.Sprintf(" :%d", s.Port)
+	}
+	if len(s.TXT) > 0 {
+		txt := strings.Join(s.TXT, " ")
+		if len(txt) > 80 {
+			txt = txt[:80] + "..."
+		}
+		text += " [" + txt + "]"
+	}
+	return text
+}
+
+func (m *MDNSInfo) Summary() string {
+	parts := []string{m.Source}
+	if m.Hostname != "" {
+		parts = append(parts, m.Hostname)
+	}
+	var types []string
+	for _, service := range m.Services {
+		if !slices.Contains(types, service.Type) {
+			types = append(types, service.Type)
+		}
+	}
+	if len(types) > 0 {
+		parts = append(parts, strings.Join(types, ", "))
+	}
+	return strings.Join(parts, " | ")
+}
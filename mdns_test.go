@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadHexFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	packet, err := hex.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+	if err != nil {
+		t.Fatalf("invalid hex fixture %s: %v", name, err)
+	}
+	return packet
+}
+
+func TestParseDNSMessageFixtures(t *testing.T) {
+	services, err := parseDNSMessage(loadHexFixture(t, "mdns_services.hex"))
+	if err != nil {
+		t.Fatalf("parseDNSMessage(services) error: %v", err)
+	}
+	var types []string
+	for _, record := range services.Records {
+		if record.Type == dnsTypePTR && record.Name == mdnsServiceEnumeration {
+			types = append(types, record.Target)
+		}
+	}
+	if !slices.Equal(types, []string{"_ipp._tcp.local", "_airplay._tcp.local", "_googlecast._tcp.local"}) {
+		t.Errorf("service types = %v", types)
+	}
+
+	ipp, err := parseDNSMessage(loadHexFixture(t, "mdns_ipp_response.hex"))
+	if err != nil {
+		t.Fatalf("parseDNSMessage(ipp) error: %v", err)
+	}
+	if !ipp.IsResponse() || len(ipp.Records) != 4 {
+		t.Fatalf("expected response with 4 records, got %d", len(ipp.Records))
+	}
+
+	for _, record := range ipp.Records {
+		switch record.Type {
+		case dnsTypeSRV:
+			if record.Target != "officejet.local" || record.Port != 631 {
+				t.Errorf("SRV = %s:%d", record.Target, record.Port)
+			}
+			if record.Class&dnsClassMask != dnsClassIN {
+				t.Errorf("SRV class = %#x", record.Class)
+			}
+		case dnsTypeTXT:
+			if len(record.TXT) != 4 || record.TXT[1] != "ty=HP OfficeJet Pro 9010" {
+				t.Errorf("TXT = %v", record.TXT)
+			}
+		case dnsTypeA:
+			if record.IP.String() != "192.168.1.50" {
+				t.Errorf("A = %s", record.IP)
+			}
+		}
+	}
+}
+
+func TestReadDNSNameRejectsLoops(t *testing.T) {
+	packet := make([]byte, 12)
+	packet = append(packet, 0xc0, 0x0c)
+
+	if _, _, err := readDNSName(packet, 12); err == nil {
+		t.Error("expected error for self-referencing pointer")
+	}
+	if _, _, err := readDNSName([]byte{5, 'a', 'b'}, 0); err == nil {
+		t.Error("expected error for truncated label")
+	}
+}
+
+func TestMulticastDiscoveryLookup(t *testing.T) {
+	d := newMulticastDiscovery()
+
+	services, _ := parseDNSMessage(loadHexFixture(t, "mdns_services.hex"))
+	followUp := d.handleMessage(services, "192.168.1.50")
+	if len(followUp) != 3 || followUp[0].Name != "_ipp._tcp.local" || followUp[0].Type != dnsTypePTR {
+		t.Errorf("follow-up queries = %+v", followUp)
+	}
+	if again := d.handleMessage(services, "192.168.1.50"); len(again) != 0 {
+		t.Errorf("repeated announcement should not re-query, got %+v", again)
+	}
+
+	ipp, _ := parseDNSMessage(loadHexFixture(t, "mdns_ipp_response.hex"))
+	d.handleMessage(ipp, "192.168.1.50")
+
+	if info := d.Lookup("192.168.1.99"); info != nil {
+		t.Errorf("unexpected info for unrelated host: %+v", info)
+	}
+
+	host := &HostInfo{IP: "192.168.1.50"}
+	d.Apply(host)
+	if host.MDNS == nil {
+		t.Fatal("expected mDNS info to be attached")
+	}
+	if host.Hostname != "officejet.local" {
+		t.Errorf("Hostname = %q", host.Hostname)
+	}
+	if len(host.MDNS.Services) != 1 {
+		t.Fatalf("services = %+v", host.MDNS.Services)
+	}
+
+	service := host.MDNS.Services[0]
+	if service.Type != "_ipp._tcp" || service.Instance != "Office Printer" || service.Port != 631 {
+		t.Errorf("service = %+v", service)
+	}
+	if !slices.Contains(service.TXT, "rp=ipp/print") {
+		t.Errorf("TXT = %v", service.TXT)
+	}
+	if !matchesSearch(host, "_ipp") || !matchesSearch(host, "office printer") {
+		t.Error("mDNS service details should be searchable")
+	}
+
+	d.addresses["evil\x1b[2J.local"] = []string{"192.168.1.66"}
+	d.instances["Cam\x1b]0;x\a._http._tcp.local"] = "_http._tcp.local"
+	d.sources["Cam\x1b]0;x\a._http._tcp.local"] = "192.168.1.66"
+	d.txt["Cam\x1b]0;x\a._http._tcp.local"] = []string{"path=/\x07"}
+	evil := d.Lookup("192.168.1.66")
+	if evil == nil || evil.Hostname != "evil[2J.local" || evil.Services[0].Instance != "Cam]0;x" || evil.Services[0].TXT[0] != "path=/" {
+		t.Errorf("control characters should be stripped from mDNS names, got %+v", evil)
+	}
+}
+
+func TestQueryReverseName(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	response := loadHexFixture(t, "llmnr_ptr_response.hex")
+	go func() {
+		buffer := make([]byte, 1500)
+		n, addr, err := conn.ReadFrom(buffer)
+		if err != nil || n < 12 {
+			return
+		}
+		binary.BigEndian.PutUint16(response[0:2], binary.BigEndian.Uint16(buffer[0:2]))
+		conn.WriteTo(response, addr)
+	}()
+
+	name, err := queryReverseName(context.Background(), conn.LocalAddr().String(), "192.168.1.50", 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("queryReverseName() error: %v", err)
+	}
+	if name != "DESKTOP-42" {
+		t.Errorf("name = %q", name)
+	}
+}
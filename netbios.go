@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+)
+
+const netbiosNodeStatusType = 0x21
+
+type NetBIOSName struct {
+	Name   string
+	Suffix byte
+	Group  bool
+}
+
+type NetBIOSInfo struct {
+	Name      string
+	Workgroup string
+	User      string
+	MAC       string
+	Names     []NetBIOSName
+}
+
+func encodeNetBIOSName(name string, suffix byte) []byte {
+	padded := make([]byte, 16)
+	copy(padded, strings.ToUpper(name))
+	if name != "*" {
+		for i := len(name); i < 15; i++ {
+			padded[i] = ' '
+		}
+	}
+	padded[15] = suffix
+
+	encoded := make([]byte, 0, 34)
+	encoded = append(encoded, 32)
+	for _, b := range padded {
+		encoded = append(encoded, 'A'+(b>>4), 'A'+(b&0x0f))
+	}
+	return append(encoded, 0)
+}
+
+func buildNetBIOSNodeStatusRequest(transactionID uint16) []byte {
+	packet := make([]byte, 12)
+	binary.BigEndian.PutUint16(packet[0:2], transactionID)
+	binary.BigEndian.PutUint16(packet[4:6], 1)
+
+	packet = append(packet, encodeNetBIOSName("*", 0)...)
+	packet = binary.BigEndian.AppendUint16(packet, netbiosNodeStatusType)
+	return binary.BigEndian.AppendUint16(packet, 1)
+}
+
+func skipDNSName(packet []byte, offset int) (int, error) {
+	for offset < len(packet) {
+		length := int(packet[offset])
+		switch {
+		case length == 0:
+			return offset + 1, nil
+		case length&0xc0 == 0xc0:
+			return offset + 2, nil
+		default:
+			offset += length + 1
+		}
+	}
+	return 0, fmt.Errorf("truncated name")
+}
+
+func parseNetBIOSNodeStatus(packet []byte) (*NetBIOSInfo, error) {
+	if len(packet) < 12 {
+		return nil, fmt.Errorf("NetBIOS response too short")
+	}
+	if binary.BigEndian.Uint16(packet[2:4])&0x8000 == 0 {
+		return nil, fmt.Errorf("not a NetBIOS response")
+	}
+	if binary.BigEndian.Uint16(packet[6:8]) == 0 {
+		return nil, fmt.Errorf("NetBIOS response has no answers")
+	}
+
+	offset, err := skipDNSName(packet, 12)
+	if err != nil {
+		return nil, err
+	}
+	if offset+10 > len(packet) {
+		return nil, fmt.Errorf("truncated NetBIOS resource record")
+	}
+	if binary.BigEndian.Uint16(packet[offset:offset+2]) != netbiosNodeStatusType {
+		return nil, fmt.Errorf("unexpected NetBIOS record type")
+	}
+
+	rdLength := int(binary.BigEndian.Uint16(packet[offset+8 : offset+10]))
+	offset += 10
+	if offset+rdLength > len(packet) || rdLength < 1 {
+		return nil, fmt.Errorf("truncated NetBIOS node status")
+	}
+	rdata := packet[offset : offset+rdLength]
+
+	count := int(rdata[0])
+	if 1+count*18 > len(rdata) {
+		return nil, fmt.Errorf("truncated NetBIOS name table")
+	}
+
+	info := &NetBIOSInfo{}
+	for i := range count {
+		entry := rdata[1+i*18 : 1+(i+1)*18]
+		name := NetBIOSName{
+			Name:   sanitizeText(strings.TrimRight(string(entry[:15]), " \x00"), 0),
+			Suffix: entry[15],
+			Group:  binary.BigEndian.Uint16(entry[16:18])&0x8000 != 0,
+		}
+		info.Names = append(info.Names, name)
+
+		switch {
+		case name.Suffix == 0x00 && !name.Group && info.Name == "":
+			info.Name = name.Name
+		case name.Suffix == 0x00 && name.Group && info.Workgroup == "":
+			info.Workgroup = name.Name
+		case name.Suffix == 0x03 && !name.Group && name.Name != info.Name && info.User == "":
+			info.User = name.Name
+		}
+	}
+
+	if info.Name == "" {
+		for _, name := range info.Names {
+			if name.Suffix == 0x20 && !name.Group {
+				info.Name = name.Name
+				break
+			}
+		}
+	}
+
+	macOffset := 1 + count*18
+	if macOffset+6 <= len(rdata) {
+		mac := net.HardwareAddr(rdata[macOffset : macOffset+6])
+		if mac.String() != "00:00:00:00:00:00" {
+			info.MAC = strings.ToUpper(mac.String())
+		}
+	}
+
+	return info, nil
+}
+
+func queryNetBIOS(ctx context.Context, ip string, timeout time.Duration) (*NetBIOSInfo, error) {
+	return queryNetBIOSAddr(ctx, net.JoinHostPort(ip, "137"), timeout)
+}
+
+func queryNetBIOSAddr(ctx context.Context, address string, timeout time.Duration) (*NetBIOSInfo, error) {
+	d := newDialer("udp", timeout)
+	conn, err := d.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	transactionID := uint16(rand.IntN(0xffff))
+	request := buildNetBIOSNodeStatusRequest(transactionID)
+	buffer := make([]byte, 1500)
+
+	for range 2 {
+		if _, err := conn.Write(request); err != nil {
+			return nil, err
+		}
+
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		n, err := conn.Read(buffer)
+		if err != nil {
+			continue
+		}
+		if n < 2 || binary.BigEndian.Uint16(buffer[0:2]) != transactionID {
+			continue
+		}
+		return parseNetBIOSNodeStatus(buffer[:n])
+	}
+
+	return nil, fmt.Errorf("no NetBIOS response from %s", address)
+}
+
+func (n *NetBIOSInfo) Summary() string {
+	parts := []string{"NetBIOS " + n.Name}
+	if n.Workgroup != "" {
+		parts = append(parts, "workgroup "+n.Workgroup)
+	}
+	if n.User != "" {
+		parts = append(parts, "user "+n.User)
+	}
+	return strings.Join(parts, " | ")
+}
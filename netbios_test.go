@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+func buildTestNodeStatusResponse(transactionID uint16, name, workgroup string) []byte {
+	packet := make([]byte, 12)
+	binary.BigEndian.PutUint16(packet[0:2], transactionID)
+	binary.BigEndian.PutUint16(packet[2:4], 0x8400)
+	binary.BigEndian.PutUint16(packet[6:8], 1)
+	packet = append(packet, encodeNetBIOSName("*", 0)...)
+	packet = binary.BigEndian.AppendUint16(packet, netbiosNodeStatusType)
+	packet = binary.BigEndian.AppendUint16(packet, 1)
+	packet = append(packet, 0, 0, 0, 0)
+
+	names := []struct {
+		name   string
+		suffix byte
+		flags  uint16
+	}{
+		{name, 0x00, 0x0400},
+		{workgroup, 0x00, 0x8400},
+		{name, 0x20, 0x0400},
+		{"ALICE", 0x03, 0x0400},
+	}
+
+	rdata := []byte{byte(len(names))}
+	for _, n := range names {
+		entry := []byte(n.name)
+		for len(entry) < 15 {
+			entry = append(entry, ' ')
+		}
+		entry = append(entry, n.suffix)
+		rdata = binary.BigEndian.AppendUint16(append(rdata, entry...), n.flags)
+	}
+	rdata = append(rdata, 0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e)
+
+	packet = binary.BigEndian.AppendUint16(packet, uint16(len(rdata)))
+	return append(packet, rdata...)
+}
+
+func TestEncodeNetBIOSName(t *testing.T) {
+	encoded := encodeNetBIOSName("*", 0)
+	if len(encoded) != 34 || encoded[0] != 32 || encoded[33] != 0 {
+		t.Fatalf("unexpected encoding length/framing: %v", encoded)
+	}
+	if string(encoded[1:3]) != "CK" || string(encoded[3:5]) != "AA" {
+		t.Errorf("wildcard encoded as %q", encoded[1:33])
+	}
+}
+
+func TestParseNetBIOSNodeStatus(t *testing.T) {
+	info, err := parseNetBIOSNodeStatus(buildTestNodeStatusResponse(0x1234, "DESKTOP-42", "WORKGROUP"))
+	if err != nil {
+		t.Fatalf("parseNetBIOSNodeStatus() error: %v", err)
+	}
+
+	if info.Name != "DESKTOP-42" || info.Workgroup != "WORKGROUP" || info.User != "ALICE" {
+		t.Errorf("got name=%q workgroup=%q user=%q", info.Name, info.Workgroup, info.User)
+	}
+	if info.MAC != "00:1A:2B:3C:4D:5E" {
+		t.Errorf("MAC = %q", info.MAC)
+	}
+	if len(info.Names) != 4 {
+		t.Errorf("expected 4 names, got %d", len(info.Names))
+	}
+
+	if _, err := parseNetBIOSNodeStatus([]byte{0, 1, 0, 0}); err == nil {
+		t.Error("expected error for truncated packet")
+	}
+
+	injected, err := parseNetBIOSNodeStatus(buildTestNodeStatusResponse(0x1234, "PC\x1b[2J\x07", "WG\x1b]0;x"))
+	if err != nil {
+		t.Fatalf("parseNetBIOSNodeStatus() error: %v", err)
+	}
+	if injected.Name != "PC[2J" || injected.Workgroup != "WG]0;x" || injected.Names[0].Name != "PC[2J" {
+		t.Errorf("control characters should be stripped, got name=%q workgroup=%q", injected.Name, injected.Workgroup)
+	}
+}
+
+func TestQueryNetBIOS(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		buffer := make([]byte, 1500)
+		n, addr, err := conn.ReadFrom(buffer)
+		if err != nil || n < 2 {
+			return
+		}
+		conn.WriteTo(buildTestNodeStatusResponse(binary.BigEndian.Uint16(buffer[0:2]), "DESKTOP-42", "WORKGROUP"), addr)
+	}()
+
+	info, err := queryNetBIOSAddr(context.Background(), conn.LocalAddr().String(), 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("queryNetBIOSAddr() error: %v", err)
+	}
+	if info.Name != "DESKTOP-42" {
+		t.Errorf("Name = %q", info.Name)
+	}
+}
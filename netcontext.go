@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	routeFlagUp      = 0x1
+	routeFlagGateway = 0x2
+
+	neighborHeaderLen  = 12
+	neighborAttrDst    = 1
+	neighborAttrLLAddr = 2
+)
+
+var (
+	procNetRoutePath    = "/proc/net/route"
+	resolvConfPaths     = []string{"/etc/resolv.conf", "/run/systemd/resolve/resolv.conf"}
+	dhcpLeasePatterns   = []string{"/run/systemd/netif/leases/*", "/var/lib/dhcp/dhclient*.leases", "/var/lib/dhclient/*.lease*", "/var/lib/NetworkManager/*.lease"}
+	resolvedStubServers = []string{"127.0.0.53", "127.0.0.54"}
+)
+
+var activeNetworkContext *NetworkContext
+var networkContextOnce sync.Once
+
+type NetworkContext struct {
+	Interface  string
+	IP         string
+	MAC        string
+	Subnet     string
+	Gateway    string
+	GatewayMAC string
+	DNSServers []string
+	DHCPServer string
+}
+
+type defaultRoute struct {
+	Interface string
+	Gateway   net.IP
+	Metric    int
+}
+
+func parseProcNetRoute(data []byte, iface string) (*defaultRoute, error) {
+	var best *defaultRoute
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[0] == "Iface" {
+			continue
+		}
+		if iface != "" && fields[0] != iface {
+			continue
+		}
+		if fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil || flags&routeFlagUp == 0 || flags&routeFlagGateway == 0 {
+			continue
+		}
+		gateway, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil {
+			continue
+		}
+		metric, err := strconv.Atoi(fields[6])
+		if err != nil {
+			continue
+		}
+
+		ip := make(net.IP, 4)
+		binary.LittleEndian.PutUint32(ip, uint32(gateway))
+		if best == nil || metric < best.Metric {
+			best = &defaultRoute{Interface: fields[0], Gateway: ip, Metric: metric}
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no default route found")
+	}
+	return best, nil
+}
+
+func parseResolvConf(data []byte) []string {
+	var servers []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		server := strings.Split(fields[1], "%")[0]
+		if net.ParseIP(server) != nil && !slices.Contains(servers, server) {
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
+func parseDHCPLease(data []byte) string {
+	var server string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if value, ok := strings.CutPrefix(line, "SERVER_ADDRESS="); ok {
+			server = value
+		} else if value, ok := strings.CutPrefix(line, "option dhcp-server-identifier "); ok {
+			server = strings.TrimSuffix(value, ";")
+		}
+	}
+
+	if net.ParseIP(server) == nil {
+		return ""
+	}
+	return server
+}
+
+func parseNeighborMessage(data []byte) (net.IP, net.HardwareAddr) {
+	if len(data) < neighborHeaderLen {
+		return nil, nil
+	}
+
+	var ip net.IP
+	var mac net.HardwareAddr
+	attrs := data[neighborHeaderLen:]
+	for len(attrs) >= 4 {
+		length := int(binary.NativeEndian.Uint16(attrs[0:2]))
+		kind := binary.NativeEndian.Uint16(attrs[2:4])
+		if length < 4 || length > len(attrs) {
+			break
+		}
+
+		value := attrs[4:length]
+		switch kind {
+		case neighborAttrDst:
+			ip = net.IP(slices.Clone(value))
+		case neighborAttrLLAddr:
+			mac = net.HardwareAddr(slices.Clone(value))
+		}
+
+		aligned := (length + 3) &^ 3
+		if aligned > len(attrs) {
+			break
+		}
+		attrs = attrs[aligned:]
+	}
+
+	if len(mac) == 0 || bytes.Equal(mac, make([]byte, len(mac))) {
+		mac = nil
+	}
+	return ip, mac
+}
+
+func readDNSServers() []string {
+	var stubOnly []string
+	for _, path := range resolvConfPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		servers := parseResolvConf(data)
+		if slices.ContainsFunc(servers, func(server string) bool {
+			return !slices.Contains(resolvedStubServers, server)
+		}) {
+			return servers
+		}
+		if stubOnly == nil {
+			stubOnly = servers
+		}
+	}
+	return stubOnly
+}
+
+func readDHCPServer(iface *net.Interface) string {
+	for _, pattern := range dhcpLeasePatterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			name := filepath.Base(path)
+			if name != strconv.Itoa(iface.Index) && !strings.Contains(name, iface.Name) {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			if server := parseDHCPLease(data); server != "" {
+				return server
+			}
+		}
+	}
+	return ""
+}
+
+func interfaceIPv4(iface *net.Interface) *net.IPNet {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil && !ipNet.IP.IsLinkLocalUnicast() {
+			return ipNet
+		}
+	}
+	return nil
+}
+
+func detectNetworkContext() (*NetworkContext, error) {
+	name := selectedInterface
+	var route *defaultRoute
+	if data, err := os.ReadFile(procNetRoutePath); err == nil {
+		if route, err = parseProcNetRoute(data, selectedInterface); err == nil {
+			name = route.Interface
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no default route found")
+	}
+
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interface %s: %v", name, err)
+	}
+
+	netContext := &NetworkContext{
+		Interface:  iface.Name,
+		MAC:        strings.ToUpper(iface.HardwareAddr.String()),
+		DNSServers: readDNSServers(),
+		DHCPServer: readDHCPServer(iface),
+	}
+	if ipNet := interfaceIPv4(iface); ipNet != nil {
+		netContext.IP = ipNet.IP.String()
+		netContext.Subnet = (&net.IPNet{IP: ipNet.IP.Mask(ipNet.Mask), Mask: ipNet.Mask}).String()
+	}
+
+	if route == nil {
+		return netContext, nil
+	}
+	netContext.Gateway = route.Gateway.String()
+	if mac := lookupNeighborMAC(route.Gateway); mac != nil {
+		netContext.GatewayMAC = strings.ToUpper(mac.String())
+	} else {
+		netContext.GatewayMAC, _ = getMACAddressNew(netContext.Gateway)
+	}
+
+	return netContext, nil
+}
+
+func getNetworkContext() *NetworkContext {
+	networkContextOnce.Do(func() {
+		if activeNetworkContext != nil {
+			return
+		}
+		if netContext, err := detectNetworkContext(); err == nil {
+			activeNetworkContext = netContext
+		}
+	})
+	return activeNetworkContext
+}
+
+func resolveHostMAC(ip string) (string, string) {
+	if activeProxy != nil {
+		return "", ""
+	}
+	if netContext := getNetworkContext(); netContext != nil && ip == netContext.IP && netContext.MAC != "" {
+		return netContext.MAC, getEnhancedVendor(netContext.MAC)
+	}
+	return getMACAddressNew(ip)
+}
+
+func (c *NetworkContext) Role(ip string) string {
+	if c == nil || ip == "" {
+		return ""
+	}
+	switch ip {
+	case c.Gateway:
+		return "gateway"
+	case c.IP:
+		return "this host"
+	}
+	return ""
+}
+
+func (c *NetworkContext) Summary() string {
+	local := c.Interface
+	if c.IP != "" {
+		local += " " + c.IP
+	}
+	if c.MAC != "" {
+		local += " (" + c.MAC + ")"
+	}
+	parts := []string{"Interface: " + local}
+
+	if c.Gateway != "" {
+		gateway := c.Gateway
+		if c.GatewayMAC != "" {
+			gateway += " (" + c.GatewayMAC + ")"
+		}
+		parts = append(parts, "Gateway: "+gateway)
+	}
+
+	if len(c.DNSServers) > 0 {
+		parts = append(parts, "DNS: "+strings.Join(c.DNSServers, ", "))
+	}
+	if c.DHCPServer != "" {
+		parts = append(parts, "DHCP: "+c.DHCPServer)
+	}
+	return strings.Join(parts, " | ")
+}
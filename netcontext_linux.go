@@ -0,0 +1,30 @@
+//go:build linux
+
+package main
+
+import (
+	"net"
+	"syscall"
+)
+
+func lookupNeighborMAC(ip net.IP) net.HardwareAddr {
+	data, err := syscall.NetlinkRIB(syscall.RTM_GETNEIGH, syscall.AF_INET)
+	if err != nil {
+		return nil
+	}
+	messages, err := syscall.ParseNetlinkMessage(data)
+	if err != nil {
+		return nil
+	}
+
+	for _, message := range messages {
+		if message.Header.Type != syscall.RTM_NEWNEIGH {
+			continue
+		}
+		neighbor, mac := parseNeighborMessage(message.Data)
+		if mac != nil && neighbor.Equal(ip) {
+			return mac
+		}
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+import "net"
+
+func lookupNeighborMAC(ip net.IP) net.HardwareAddr {
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+)
+
+func TestParseProcNetRoute(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		iface     string
+		wantIface string
+		wantGW    string
+		wantErr   bool
+	}{
+		{
+			name: "single default route",
+			data: "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n" +
+				"eth0\t00000000\t0101A8C0\t0003\t0\t0\t100\t00000000\t0\t0\t0\n" +
+				"eth0\t0001A8C0\t00000000\t0001\t0\t0\t100\t00FFFFFF\t0\t0\t0\n",
+			wantIface: "eth0",
+			wantGW:    "192.168.1.1",
+		},
+		{
+			name: "lowest metric wins",
+			data: "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n" +
+				"wlan0\t00000000\t0100000A\t0003\t0\t0\t600\t00000000\t0\t0\t0\n" +
+				"eth0\t00000000\tFE01A8C0\t0003\t0\t0\t100\t00000000\t0\t0\t0\n",
+			wantIface: "eth0",
+			wantGW:    "192.168.1.254",
+		},
+		{
+			name: "restricted to selected interface",
+			data: "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n" +
+				"wlan0\t00000000\t0100000A\t0003\t0\t0\t600\t00000000\t0\t0\t0\n" +
+				"eth0\t00000000\tFE01A8C0\t0003\t0\t0\t100\t00000000\t0\t0\t0\n",
+			iface:     "wlan0",
+			wantIface: "wlan0",
+			wantGW:    "10.0.0.1",
+		},
+		{
+			name: "route without gateway flag ignored",
+			data: "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n" +
+				"tun0\t00000000\t00000000\t0001\t0\t0\t0\t00000000\t0\t0\t0\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty table",
+			data:    "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := parseProcNetRoute([]byte(tt.data), tt.iface)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got route %+v", route)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseProcNetRoute() error = %v", err)
+			}
+			if route.Interface != tt.wantIface || route.Gateway.String() != tt.wantGW {
+				t.Errorf("got %s via %s, want %s via %s", route.Gateway, route.Interface, tt.wantGW, tt.wantIface)
+			}
+		})
+	}
+}
+
+func TestParseResolvConf(t *testing.T) {
+	data := "# generated by NetworkManager\n" +
+		"search lan\n" +
+		"nameserver 192.168.1.1\n" +
+		"; comment\n" +
+		"nameserver fe80::1%eth0\n" +
+		"nameserver 192.168.1.1\n" +
+		"nameserver not-an-ip\n" +
+		"options edns0\n"
+
+	got := parseResolvConf([]byte(data))
+	want := []string{"192.168.1.1", "fe80::1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseResolvConf() = %v, want %v", got, want)
+	}
+}
+
+func TestParseDHCPLease(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"systemd-networkd", "# This is private data.\nADDRESS=192.168.1.50\nSERVER_ADDRESS=192.168.1.1\nROUTER=192.168.1.1\n", "192.168.1.1"},
+		{"dhclient uses latest lease", "lease {\n  option dhcp-server-identifier 10.0.0.1;\n}\nlease {\n  option dhcp-server-identifier 10.0.0.2;\n}\n", "10.0.0.2"},
+		{"no server", "lease {\n  fixed-address 10.0.0.5;\n}\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDHCPLease([]byte(tt.data)); got != tt.want {
+				t.Errorf("parseDHCPLease() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func appendNeighborAttr(data []byte, kind uint16, value []byte) []byte {
+	attr := make([]byte, 4)
+	binary.NativeEndian.PutUint16(attr[0:2], uint16(4+len(value)))
+	binary.NativeEndian.PutUint16(attr[2:4], kind)
+	attr = append(attr, value...)
+	for len(attr)%4 != 0 {
+		attr = append(attr, 0)
+	}
+	return append(data, attr...)
+}
+
+func TestParseNeighborMessage(t *testing.T) {
+	message := make([]byte, neighborHeaderLen)
+	message = appendNeighborAttr(message, neighborAttrDst, []byte{192, 168, 1, 1})
+	message = appendNeighborAttr(message, neighborAttrLLAddr, []byte{0x00, 0x11, 0x22, 0xaa, 0xbb, 0xcc})
+
+	ip, mac := parseNeighborMessage(message)
+	if ip.String() != "192.168.1.1" || mac.String() != "00:11:22:aa:bb:cc" {
+		t.Errorf("parseNeighborMessage() = %s %s", ip, mac)
+	}
+
+	incomplete := make([]byte, neighborHeaderLen)
+	incomplete = appendNeighborAttr(incomplete, neighborAttrDst, []byte{192, 168, 1, 2})
+	incomplete = appendNeighborAttr(incomplete, neighborAttrLLAddr, make([]byte, 6))
+	if _, mac := parseNeighborMessage(incomplete); mac != nil {
+		t.Errorf("expected zero MAC to be dropped, got %s", mac)
+	}
+
+	if ip, mac := parseNeighborMessage([]byte{1, 2, 3}); ip != nil || mac != nil {
+		t.Errorf("expected nothing from truncated message, got %s %s", ip, mac)
+	}
+}
+
+func TestNetworkContextRole(t *testing.T) {
+	netContext := &NetworkContext{IP: "192.168.1.50", Gateway: "192.168.1.1"}
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"192.168.1.1", "gateway"},
+		{"192.168.1.50", "this host"},
+		{"192.168.1.20", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := netContext.Role(tt.ip); got != tt.want {
+			t.Errorf("Role(%q) = %q, want %q", tt.ip, got, tt.want)
+		}
+	}
+
+	var missing *NetworkContext
+	if got := missing.Role("192.168.1.1"); got != "" {
+		t.Errorf("nil context Role() = %q, want empty", got)
+	}
+}
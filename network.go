@@ -7,14 +7,31 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"time"
 )
 
-func getLocalSubnet() (string, error) {
+var selectedInterface string
+var sourceIP net.IP
+
+type InterfaceSubnet struct {
+	Name    string
+	IP      string
+	Subnet  string
+	Default bool
+}
+
+func listInterfaceSubnets() []InterfaceSubnet {
 	interfaces, err := net.Interfaces()
 	if err != nil {
-		return "", fmt.Errorf("failed to get network interfaces: %v", err)
+		return nil
 	}
 
+	defaultInterface := ""
+	if netContext := getNetworkContext(); netContext != nil && netContext.Gateway != "" {
+		defaultInterface = netContext.Interface
+	}
+
+	var candidates []InterfaceSubnet
 	for _, iface := range interfaces {
 		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
 			continue
@@ -42,18 +59,121 @@ func getLocalSubnet() (string, error) {
 				}
 			}
 
-			if ip.To4() != nil && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
-				if isPrivateIP(ip) {
-					network := &net.IPNet{IP: ip.Mask(mask), Mask: mask}
-					return network.String(), nil
-				}
+			if ip.To4() != nil && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && isPrivateIP(ip) {
+				network := &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+				candidates = append(candidates, InterfaceSubnet{
+					Name:    iface.Name,
+					IP:      ip.String(),
+					Subnet:  network.String(),
+					Default: iface.Name == defaultInterface,
+				})
+				break
+			}
+		}
+	}
+
+	return candidates
+}
+
+func getLocalSubnet() (string, error) {
+	candidates := listInterfaceSubnets()
+
+	if selectedInterface != "" {
+		for _, candidate := range candidates {
+			if candidate.Name == selectedInterface {
+				return candidate.Subnet, nil
 			}
 		}
+		return "", fmt.Errorf("no private IPv4 subnet on interface %s", selectedInterface)
+	}
+
+	for _, candidate := range candidates {
+		if candidate.Default {
+			return candidate.Subnet, nil
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0].Subnet, nil
 	}
 
 	return "", fmt.Errorf("no suitable network interface found")
 }
 
+func selectInterface(name string) error {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return fmt.Errorf("unknown interface %s: %v", name, err)
+	}
+	if interfaceIPv4(iface) == nil {
+		return fmt.Errorf("interface %s has no IPv4 address", name)
+	}
+
+	selectedInterface = iface.Name
+	if netContext, err := detectNetworkContext(); err == nil {
+		activeNetworkContext = netContext
+	}
+	return nil
+}
+
+func setSourceIP(address string) error {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return fmt.Errorf("invalid source address %q", address)
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return fmt.Errorf("failed to get interface addresses: %v", err)
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			sourceIP = ip
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not assigned to a local interface", address)
+}
+
+func scanSourceIP() net.IP {
+	if sourceIP != nil {
+		return sourceIP.To4()
+	}
+	if selectedInterface == "" {
+		return nil
+	}
+	iface, err := net.InterfaceByName(selectedInterface)
+	if err != nil {
+		return nil
+	}
+	if ipNet := interfaceIPv4(iface); ipNet != nil {
+		return ipNet.IP.To4()
+	}
+	return nil
+}
+
+func multicastInterface() *net.Interface {
+	if selectedInterface == "" {
+		return nil
+	}
+	iface, err := net.InterfaceByName(selectedInterface)
+	if err != nil {
+		return nil
+	}
+	return iface
+}
+
+func newDialer(network string, timeout time.Duration) *net.Dialer {
+	d := &net.Dialer{Timeout: timeout}
+	if sourceIP != nil {
+		if strings.HasPrefix(network, "udp") {
+			d.LocalAddr = &net.UDPAddr{IP: sourceIP}
+		} else {
+			d.LocalAddr = &net.TCPAddr{IP: sourceIP}
+		}
+	}
+	return d
+}
+
 func isPrivateIP(ip net.IP) bool {
 	privateRanges := []string{
 		"10.0.0.0/8",
@@ -101,7 +221,11 @@ func getMACAddressNew(ip string) (string, string) {
 		
 		if len(strings.TrimSpace(string(output))) == 0 {
 			if isIPInLocalSubnet(ip) {
-				arpingCmd := exec.Command("arping", "-c", "1", "-w", "1", ip)
+				arpingArgs := []string{"-c", "1", "-w", "1"}
+				if selectedInterface != "" {
+					arpingArgs = append(arpingArgs, "-I", selectedInterface)
+				}
+				arpingCmd := exec.Command("arping", append(arpingArgs, ip)...)
 				arpingCmd.Run()
 				
 				cmd = exec.Command("ip", "neighbor", "show", ip)
@@ -3,6 +3,7 @@ package main
 import (
 	"net"
 	"testing"
+	"time"
 )
 
 func TestIsPrivateIP(t *testing.T) {
@@ -107,3 +108,55 @@ func TestGetLocalSubnet(t *testing.T) {
 
 	t.Logf("detected local subnet: %s", subnet)
 }
+
+func TestNewDialerSourceIP(t *testing.T) {
+	defer func() { sourceIP = nil }()
+
+	sourceIP = nil
+	if d := newDialer("tcp", time.Second); d.LocalAddr != nil {
+		t.Errorf("expected no local address without -source-ip, got %v", d.LocalAddr)
+	}
+
+	if err := setSourceIP("127.0.0.1"); err != nil {
+		t.Fatalf("setSourceIP(127.0.0.1) error = %v", err)
+	}
+	if addr, ok := newDialer("tcp", time.Second).LocalAddr.(*net.TCPAddr); !ok || !addr.IP.Equal(sourceIP) {
+		t.Errorf("tcp dialer local address = %v", addr)
+	}
+	if addr, ok := newDialer("udp", time.Second).LocalAddr.(*net.UDPAddr); !ok || !addr.IP.Equal(sourceIP) {
+		t.Errorf("udp dialer local address = %v", addr)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	conn, err := newDialer("tcp", time.Second).Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial with bound source failed: %v", err)
+	}
+	conn.Close()
+}
+
+func TestSetSourceIPRejectsForeignAddress(t *testing.T) {
+	defer func() { sourceIP = nil }()
+
+	for _, address := range []string{"not-an-ip", "203.0.113.254"} {
+		if err := setSourceIP(address); err == nil {
+			t.Errorf("setSourceIP(%q) expected error", address)
+		}
+	}
+	if sourceIP != nil {
+		t.Errorf("sourceIP should stay unset, got %v", sourceIP)
+	}
+}
+
+func TestSelectInterfaceUnknown(t *testing.T) {
+	if err := selectInterface("viewnet-missing0"); err == nil {
+		t.Error("expected error for unknown interface")
+	}
+	if selectedInterface != "" {
+		t.Errorf("selectedInterface should stay unset, got %q", selectedInterface)
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	osLinux   = "Linux/Unix"
+	osWindows = "Windows"
+	osNetwork = "Network device"
+	osEmbed   = "Embedded"
+
+	osMinConfidence = 15
+)
+
+var synProbeOptions = []byte{
+	2, 4, 0x05, 0xb4,
+	4, 2,
+	8, 10, 0, 0, 0, 1, 0, 0, 0, 0,
+	1,
+	3, 3, 7,
+}
+
+type TCPFingerprint struct {
+	TTL     int
+	Window  uint16
+	Options string
+	MSS     int
+	WScale  int
+}
+
+type osSignature struct {
+	family  string
+	weight  int
+	pattern *regexp.Regexp
+}
+
+var osBannerSignatures = []osSignature{
+	{osWindows, 40, regexp.MustCompile(`(?i)windows|microsoft|IIS/|OpenSSH_for_Windows|MSRPC`)},
+	{osNetwork, 40, regexp.MustCompile(`(?i)RouterOS|Cisco|JUNOS|FortiOS|FortiGate|EdgeOS|ProCurve|Aruba|VyOS|NX-OS|PAN-OS|ZyNOS|Comware`)},
+	{osEmbed, 35, regexp.MustCompile(`(?i)lwIP|uIP|BusyBox|Boa/|GoAhead|micro_httpd|mini_httpd|RomPager|Allegro|thttpd|uc-httpd|Hikvision|Dahua|JetDirect|Virata|EmWeb`)},
+	{osLinux, 30, regexp.MustCompile(`(?i)ubuntu|debian|centos|red hat|rhel|fedora|rocky|almalinux|alpine|raspbian|linux|freebsd|openbsd|netbsd|darwin|mac ?os|synology|qnap`)},
+	{osLinux, 15, regexp.MustCompile(`(?i)OpenSSH_|dropbear|Postfix|Exim|Dovecot|nginx|Apache`)},
+}
+
+var osTCPOptionSignatures = []struct {
+	family  string
+	options string
+}{
+	{osLinux, "M,S,T,N,W"},
+	{osLinux, "M,N,W,N,N,T,S,E"},
+	{osLinux, "M,N,W,S,T"},
+	{osWindows, "M,N,W,N,N,S"},
+	{osWindows, "M,N,W,S"},
+	{osNetwork, "M"},
+}
+
+var osLinuxWindows = []uint16{5792, 5840, 14480, 14600, 26847, 28960, 29200, 43440, 64768, 65160}
+
+func newTCPFingerprint(reply synReply) *TCPFingerprint {
+	fp := &TCPFingerprint{
+		TTL:    reply.TTL,
+		Window: reply.Window,
+		WScale: -1,
+	}
+
+	var kinds []string
+	options := reply.Options
+	for len(options) > 0 {
+		kind := options[0]
+		if kind == 0 {
+			kinds = append(kinds, "E")
+			break
+		}
+		if kind == 1 {
+			kinds = append(kinds, "N")
+			options = options[1:]
+			continue
+		}
+		if len(options) < 2 || int(options[1]) < 2 || int(options[1]) > len(options) {
+			break
+		}
+		data := options[2:options[1]]
+
+		switch kind {
+		case 2:
+			kinds = append(kinds, "M")
+			if len(data) == 2 {
+				fp.MSS = int(binary.BigEndian.Uint16(data))
+			}
+		case 3:
+			kinds = append(kinds, "W")
+			if len(data) == 1 {
+				fp.WScale = int(data[0])
+			}
+		case 4:
+			kinds = append(kinds, "S")
+		case 8:
+			kinds = append(kinds, "T")
+		default:
+			kinds = append(kinds, fmt.Sprintf("%d", kind))
+		}
+		options = options[options[1]:]
+	}
+
+	fp.Options = strings.Join(kinds, ",")
+	return fp
+}
+
+func initialTTL(ttl int) int {
+	if ttl <= 0 {
+		return 0
+	}
+	for _, initial := range []int{32, 64, 128, 255} {
+		if ttl <= initial {
+			return initial
+		}
+	}
+	return 0
+}
+
+func hostTCPFingerprint(host *HostInfo) *TCPFingerprint {
+	for _, service := range host.Services {
+		if service.TCP != nil {
+			return service.TCP
+		}
+	}
+	return nil
+}
+
+func collectOSEvidence(host *HostInfo) map[string]int {
+	scores := make(map[string]int)
+
+	ttl := host.TTL
+	fp := hostTCPFingerprint(host)
+	if ttl == 0 && fp != nil {
+		ttl = fp.TTL
+	}
+	switch initialTTL(ttl) {
+	case 32:
+		scores[osEmbed] += 20
+	case 64:
+		scores[osLinux] += 30
+		scores[osEmbed] += 10
+	case 128:
+		scores[osWindows] += 40
+	case 255:
+		scores[osNetwork] += 35
+		scores[osEmbed] += 10
+	}
+
+	if fp != nil {
+		for _, signature := range osTCPOptionSignatures {
+			if fp.Options == signature.options {
+				scores[signature.family] += 25
+				break
+			}
+		}
+		switch {
+		case slices.Contains(osLinuxWindows, fp.Window):
+			scores[osLinux] += 20
+		case fp.Window == 4128:
+			scores[osNetwork] += 30
+		case (fp.Window == 8192 || fp.Window == 64240 || fp.Window == 65535) && initialTTL(fp.TTL) == 128:
+			scores[osWindows] += 20
+		case fp.Window > 0 && fp.Window < 5792:
+			scores[osEmbed] += 10
+		}
+	}
+
+	var banners []string
+	for _, service := range host.Services {
+		banners = append(banners, service.Banner, service.Product, service.Version)
+		if service.HTTP != nil {
+			banners = append(banners, service.HTTP.Server, service.HTTP.PoweredBy)
+		}
+		if service.SSH != nil {
+			banners = append(banners, service.SSH.SoftwareVersion, service.SSH.Comments)
+		}
+	}
+	if host.SNMP != nil {
+		banners = append(banners, host.SNMP.SysDescr)
+	}
+	if host.UPnP != nil {
+		banners = append(banners, host.UPnP.Server)
+	}
+	if host.SMB != nil && host.SMB.OSHint != "" {
+		scores[osWindows] += 50
+	} else if host.NetBIOS != nil || host.SMB != nil {
+		scores[osWindows] += 15
+	}
+
+	for _, signature := range osBannerSignatures {
+		if matchesAny(signature.pattern, banners) {
+			scores[signature.family] += signature.weight
+		}
+	}
+
+	switch host.DeviceType {
+	case "router", "switch":
+		scores[osNetwork] += 20
+	case "printer", "camera", "phone", "media":
+		scores[osEmbed] += 15
+	}
+
+	return scores
+}
+
+func fingerprintOS(host *HostInfo) {
+	scores := collectOSEvidence(host)
+
+	total := 0
+	families := make([]string, 0, len(scores))
+	for family, score := range scores {
+		total += score
+		families = append(families, family)
+	}
+	if total == 0 {
+		host.OSFamily, host.OSConfidence = "", 0
+		return
+	}
+
+	sort.Slice(families, func(i, j int) bool {
+		if scores[families[i]] != scores[families[j]] {
+			return scores[families[i]] > scores[families[j]]
+		}
+		return families[i] < families[j]
+	})
+
+	best := scores[families[0]]
+	confidence := min(best, 100) * best / total
+	if confidence < osMinConfidence {
+		host.OSFamily, host.OSConfidence = "", 0
+		return
+	}
+	host.OSFamily, host.OSConfidence = families[0], confidence
+}
@@ -0,0 +1,85 @@
+package main
+
+import "testing"
+
+func TestNewTCPFingerprint(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []byte
+		want    string
+		mss     int
+		wscale  int
+	}{
+		{"linux", []byte{2, 4, 0x05, 0xb4, 4, 2, 8, 10, 0, 0, 0, 1, 0, 0, 0, 0, 1, 3, 3, 7}, "M,S,T,N,W", 1460, 7},
+		{"windows", []byte{2, 4, 0x05, 0xb4, 1, 3, 3, 8, 1, 1, 4, 2}, "M,N,W,N,N,S", 1460, 8},
+		{"macos", []byte{2, 4, 0x05, 0xb4, 1, 3, 3, 6, 1, 1, 8, 10, 0, 0, 0, 1, 0, 0, 0, 0, 4, 2, 0, 0}, "M,N,W,N,N,T,S,E", 1460, 6},
+		{"mss only", []byte{2, 4, 0x02, 0x18}, "M", 536, -1},
+		{"truncated", []byte{2, 4, 0x05}, "", 0, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := newTCPFingerprint(synReply{TTL: 64, Window: 65160, Options: tt.options})
+			if fp.Options != tt.want || fp.MSS != tt.mss || fp.WScale != tt.wscale {
+				t.Errorf("got options=%q mss=%d wscale=%d, expected %q %d %d", fp.Options, fp.MSS, fp.WScale, tt.want, tt.mss, tt.wscale)
+			}
+		})
+	}
+}
+
+func TestInitialTTL(t *testing.T) {
+	tests := map[int]int{0: 0, 30: 32, 57: 64, 64: 64, 113: 128, 128: 128, 240: 255, 255: 255}
+	for observed, expected := range tests {
+		if got := initialTTL(observed); got != expected {
+			t.Errorf("initialTTL(%d) = %d, expected %d", observed, got, expected)
+		}
+	}
+}
+
+func TestFingerprintOS(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     *HostInfo
+		expected string
+	}{
+		{
+			name:     "windows by TTL and SMB",
+			host:     &HostInfo{TTL: 127, SMB: &SMBInfo{OSHint: "Windows 10 / Server 2016-2019"}, Services: []ServiceInfo{{Port: 3389}}},
+			expected: osWindows,
+		},
+		{
+			name:     "windows by TCP fingerprint only",
+			host:     &HostInfo{Services: []ServiceInfo{{Port: 445, TCP: &TCPFingerprint{TTL: 128, Window: 64240, Options: "M,N,W,N,N,S"}}}},
+			expected: osWindows,
+		},
+		{
+			name:     "linux by TTL and SSH banner",
+			host:     &HostInfo{TTL: 63, Services: []ServiceInfo{{Port: 22, Banner: "SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13"}}},
+			expected: osLinux,
+		},
+		{
+			name:     "network device by TTL and SNMP",
+			host:     &HostInfo{TTL: 254, SNMP: &SNMPInfo{SysDescr: "RouterOS RB4011"}},
+			expected: osNetwork,
+		},
+		{
+			name:     "embedded by web server banner",
+			host:     &HostInfo{TTL: 64, DeviceType: "camera", Services: []ServiceInfo{{Port: 80, HTTP: &HTTPInfo{Server: "uc-httpd 1.0.0"}}}},
+			expected: osEmbed,
+		},
+		{
+			name:     "no evidence",
+			host:     &HostInfo{},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fingerprintOS(tt.host)
+			if tt.host.OSFamily != tt.expected {
+				t.Errorf("OSFamily = %q (%d%%), expected %q", tt.host.OSFamily, tt.host.OSConfidence, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type PortSummary struct {
+	Port     int
+	Services []string
+	Hosts    []*HostInfo
+}
+
+func buildPortPivot(hosts []*HostInfo) []PortSummary {
+	byPort := make(map[int]*PortSummary)
+	for _, host := range hosts {
+		for _, service := range host.Services {
+			summary, ok := byPort[service.Port]
+			if !ok {
+				summary = &PortSummary{Port: service.Port}
+				byPort[service.Port] = summary
+			}
+			if service.Service != "" && service.Service != "unknown" && !slices.Contains(summary.Services, service.Service) {
+				summary.Services = append(summary.Services, service.Service)
+			}
+			if len(summary.Hosts) == 0 || summary.Hosts[len(summary.Hosts)-1] != host {
+				summary.Hosts = append(summary.Hosts, host)
+			}
+		}
+	}
+
+	pivot := make([]PortSummary, 0, len(byPort))
+	for _, summary := range byPort {
+		sort.Strings(summary.Services)
+		sort.Slice(summary.Hosts, func(i, j int) bool {
+			return lessIP(summary.Hosts[i].IP, summary.Hosts[j].IP)
+		})
+		pivot = append(pivot, *summary)
+	}
+	sort.Slice(pivot, func(i, j int) bool {
+		if len(pivot[i].Hosts) != len(pivot[j].Hosts) {
+			return len(pivot[i].Hosts) > len(pivot[j].Hosts)
+		}
+		return pivot[i].Port < pivot[j].Port
+	})
+	return pivot
+}
+
+func (s PortSummary) Label() string {
+	label := fmt.Sprintf("%d/tcp", s.Port)
+	if len(s.Services) > 0 {
+		label += " " + strings.Join(s.Services, ", ")
+	}
+	return label
+}
+
+func hostServiceOn(host *HostInfo, port int) *ServiceInfo {
+	for i := range host.Services {
+		if host.Services[i].Port == port {
+			return &host.Services[i]
+		}
+	}
+	return nil
+}
+
+func serviceVersion(service *ServiceInfo) string {
+	return strings.TrimSpace(service.Product + " " + service.Version)
+}
+
+func exportPortMatrix(filename string, hosts []*HostInfo) (err error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	pivot := buildPortPivot(hosts)
+	sort.Slice(pivot, func(i, j int) bool { return pivot[i].Port < pivot[j].Port })
+
+	writer := csv.NewWriter(file)
+
+	header := []string{"IP Address", "Hostname"}
+	for _, summary := range pivot {
+		header = append(header, summary.Label())
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, host := range hosts {
+		if len(host.Services) == 0 {
+			continue
+		}
+		row := []string{host.IP, host.Hostname}
+		for _, summary := range pivot {
+			cell := ""
+			if service := hostServiceOn(host, summary.Port); service != nil {
+				cell = "open"
+				if version := serviceVersion(service); version != "" {
+					cell = version
+				}
+			}
+			row = append(row, cell)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+func TestBuildPortPivot(t *testing.T) {
+	pivot := buildPortPivot(queryTestHosts())
+
+	var labels []string
+	for _, summary := range pivot {
+		labels = append(labels, summary.Label())
+	}
+	want := []string{"22/tcp ssh", "23/tcp telnet", "80/tcp http", "443/tcp https"}
+	if !slices.Equal(labels, want) {
+		t.Fatalf("buildPortPivot() = %v, want %v", labels, want)
+	}
+	if got := hostIPs(pivot[0].Hosts); !slices.Equal(got, []string{"10.0.1.5", "10.0.1.70"}) {
+		t.Errorf("hosts on 22 = %v", got)
+	}
+}
+
+func TestExportPortMatrix(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "matrix.csv")
+	hosts := append(queryTestHosts(), &HostInfo{IP: "10.0.3.1"})
+	if err := exportPortMatrix(filename, hosts); err != nil {
+		t.Fatalf("exportPortMatrix() error = %v", err)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"IP Address", "Hostname", "22/tcp ssh", "23/tcp telnet", "80/tcp http", "443/tcp https"},
+		{"10.0.1.5", "nas.lan", "OpenSSH 7.4", "", "", "open"},
+		{"10.0.1.70", "macbook.lan", "OpenSSH 9.6p1", "", "", ""},
+		{"10.0.2.9", "printer", "", "open", "open", ""},
+	}
+	if !slices.EqualFunc(rows, want, slices.Equal) {
+		t.Errorf("matrix = %v\nwant %v", rows, want)
+	}
+
+	if _, err := os.Stat("/dev/full"); err == nil {
+		if err := exportPortMatrix("/dev/full", hosts); err == nil {
+			t.Error("a failed flush to a full device should be reported")
+		}
+		if err := exportToCSV("/dev/full", hosts); err == nil {
+			t.Error("a failed CSV flush to a full device should be reported")
+		}
+	}
+}
+
+func TestPortPivotComponent(t *testing.T) {
+	model := NewModularUI("10.0.0.0/16", 1, 1024, 200, false, "", nil, false)
+	model.results = queryTestHosts()
+	model.portView = true
+
+	pivot := NewPortPivotComponent()
+	pivot.Update(tea.KeyMsg{Type: tea.KeyEnter}, model.UIModel)
+	if !model.portExpanded[22] {
+		t.Fatal("enter should expand the selected port")
+	}
+	view := pivot.View(model.UIModel)
+	for _, want := range []string{"22/tcp ssh", "2 hosts", "10.0.1.70", "OpenSSH 9.6p1"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("pivot view missing %q:\n%s", want, view)
+		}
+	}
+
+	for range 10 {
+		pivot.Update(tea.KeyMsg{Type: tea.KeyDown}, model.UIModel)
+	}
+	if model.portCursor != 3 {
+		t.Errorf("cursor should stop at the last port, got %d", model.portCursor)
+	}
+
+	pivot.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")}, model.UIModel)
+	pivot.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")}, model.UIModel)
+	if model.portExpanded[22] || model.portExpanded[443] {
+		t.Errorf("second 'e' should collapse all ports, got %v", model.portExpanded)
+	}
+
+	pivot.Update(tea.KeyMsg{Type: tea.KeyEsc}, model.UIModel)
+	if model.portView {
+		t.Error("esc should return to the host list")
+	}
+}
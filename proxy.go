@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	socks5Version         = 0x05
+	socks5AuthNone        = 0x00
+	socks5AuthPassword    = 0x02
+	socks5AuthNoAccept    = 0xff
+	socks5CmdConnect      = 0x01
+	socks5AtypIPv4        = 0x01
+	socks5AtypDomain      = 0x03
+	socks5AtypIPv6        = 0x04
+	socks5ReplySuccess    = 0x00
+	socks5ReplyRefused    = 0x05
+	socks5PasswordVersion = 0x01
+)
+
+const proxyEnvVar = "VIEWNET_PROXY"
+
+var socks5ReplyErrors = map[byte]string{
+	0x01: "general SOCKS server failure",
+	0x02: "connection not allowed by ruleset",
+	0x03: "network unreachable",
+	0x04: "host unreachable",
+	0x05: "connection refused",
+	0x06: "TTL expired",
+	0x07: "command not supported",
+	0x08: "address type not supported",
+}
+
+var activeProxy *ProxyConfig
+
+type ProxyConfig struct {
+	Scheme   string
+	Address  string
+	Username string
+	Password string
+}
+
+type proxyError struct {
+	msg     string
+	refused bool
+}
+
+func (e *proxyError) Error() string {
+	return e.msg
+}
+
+func (e *proxyError) Is(target error) bool {
+	return e.refused && target == syscall.ECONNREFUSED
+}
+
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func parseProxyURL(raw string) (*ProxyConfig, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %v", raw, err)
+	}
+
+	config := &ProxyConfig{}
+	defaultPort := ""
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		config.Scheme = "socks5"
+		defaultPort = "1080"
+	case "http":
+		config.Scheme = "http"
+		defaultPort = "8080"
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (use socks5:// or http://)", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", raw)
+	}
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+	config.Address = net.JoinHostPort(u.Hostname(), port)
+
+	if u.User != nil {
+		config.Username = u.User.Username()
+		config.Password, _ = u.User.Password()
+	}
+	if config.Scheme == "socks5" && (len(config.Username) > 255 || len(config.Password) > 255) {
+		return nil, fmt.Errorf("SOCKS5 username and password are limited to 255 bytes")
+	}
+
+	return config, nil
+}
+
+func (p *ProxyConfig) String() string {
+	return p.Scheme + "://" + p.Address
+}
+
+func proxyFromEnvironment() string {
+	return os.Getenv(proxyEnvVar)
+}
+
+func dialTCP(ctx context.Context, address string, timeout time.Duration) (net.Conn, error) {
+	if activeProxy != nil {
+		return activeProxy.DialContext(ctx, address, timeout)
+	}
+	return newDialer("tcp", timeout).DialContext(ctx, "tcp", address)
+}
+
+func (p *ProxyConfig) DialContext(ctx context.Context, address string, timeout time.Duration) (net.Conn, error) {
+	conn, err := newDialer("tcp", timeout).DialContext(ctx, "tcp", p.Address)
+	if err != nil {
+		return nil, fmt.Errorf("proxy %s unreachable: %v", p.Address, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	tunnel := conn
+	if p.Scheme == "http" {
+		tunnel, err = httpConnect(conn, address, p.Username, p.Password)
+	} else {
+		err = socks5Connect(conn, address, p.Username, p.Password)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	tunnel.SetDeadline(time.Time{})
+	return tunnel, nil
+}
+
+func socks5Connect(conn net.Conn, address, username, password string) error {
+	methods := []byte{socks5AuthNone}
+	if username != "" {
+		methods = []byte{socks5AuthPassword}
+	}
+	greeting := append([]byte{socks5Version, byte(len(methods))}, methods...)
+	if _, err := conn.Write(greeting); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("SOCKS5 greeting failed: %v", err)
+	}
+	if reply[0] != socks5Version {
+		return fmt.Errorf("not a SOCKS5 proxy (version %d)", reply[0])
+	}
+
+	switch reply[1] {
+	case socks5AuthNone:
+	case socks5AuthPassword:
+		if err := socks5Authenticate(conn, username, password); err != nil {
+			return err
+		}
+	case socks5AuthNoAccept:
+		return fmt.Errorf("SOCKS5 proxy rejected the offered authentication methods")
+	default:
+		return fmt.Errorf("SOCKS5 proxy selected unsupported method %d", reply[1])
+	}
+
+	request, err := buildSOCKS5Request(address)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+	return readSOCKS5Reply(conn)
+}
+
+func socks5Authenticate(conn net.Conn, username, password string) error {
+	request := []byte{socks5PasswordVersion, byte(len(username))}
+	request = append(request, username...)
+	request = append(request, byte(len(password)))
+	request = append(request, password...)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("SOCKS5 authentication failed: %v", err)
+	}
+	if reply[1] != 0x00 {
+		return fmt.Errorf("SOCKS5 authentication rejected")
+	}
+	return nil
+}
+
+func buildSOCKS5Request(address string) ([]byte, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return nil, fmt.Errorf("invalid port in %q", address)
+	}
+
+	request := []byte{socks5Version, socks5CmdConnect, 0x00}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			request = append(request, socks5AtypIPv4)
+			request = append(request, ip4...)
+		} else {
+			request = append(request, socks5AtypIPv6)
+			request = append(request, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return nil, fmt.Errorf("hostname too long for SOCKS5: %s", host)
+		}
+		request = append(request, socks5AtypDomain, byte(len(host)))
+		request = append(request, host...)
+	}
+	return binary.BigEndian.AppendUint16(request, uint16(port)), nil
+}
+
+func readSOCKS5Reply(conn net.Conn) error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("SOCKS5 connect failed: %v", err)
+	}
+	if header[1] != socks5ReplySuccess {
+		msg, ok := socks5ReplyErrors[header[1]]
+		if !ok {
+			msg = fmt.Sprintf("reply code %d", header[1])
+		}
+		return &proxyError{msg: "SOCKS5: " + msg, refused: header[1] == socks5ReplyRefused}
+	}
+
+	var skip int
+	switch header[3] {
+	case socks5AtypIPv4:
+		skip = net.IPv4len
+	case socks5AtypIPv6:
+		skip = net.IPv6len
+	case socks5AtypDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		skip = int(length[0])
+	default:
+		return fmt.Errorf("SOCKS5 reply with unknown address type %d", header[3])
+	}
+
+	_, err := io.ReadFull(conn, make([]byte, skip+2))
+	return err
+}
+
+func httpConnect(conn net.Conn, address, username, password string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	req.Header.Set("User-Agent", "viewnet")
+	if username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP CONNECT failed: %v", err)
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	case resp.StatusCode == http.StatusProxyAuthRequired:
+		return nil, fmt.Errorf("HTTP proxy authentication required")
+	default:
+		return nil, fmt.Errorf("HTTP CONNECT to %s: %s", address, resp.Status)
+	}
+}
+
+func isConnectionRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,492 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type queryTokenKind int
+
+const (
+	tokenTerm queryTokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type queryToken struct {
+	kind   queryTokenKind
+	text   string
+	quoted bool
+	pos    int
+}
+
+type hostMatcher func(host *HostInfo) bool
+
+type HostQuery struct {
+	source string
+	match  hostMatcher
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+var queryFields = map[string]func(value string) (hostMatcher, error){
+	"port":    portMatcher,
+	"service": serviceMatcher,
+	"vendor":  vendorMatcher,
+	"host":    hostnameMatcher,
+	"ip":      ipMatcher,
+	"mac":     macMatcher,
+	"net":     netMatcher,
+	"version": versionMatcher,
+	"os":      osMatcher,
+	"type":    deviceTypeMatcher,
+	"title":   titleMatcher,
+}
+
+var versionNumberPattern = regexp.MustCompile(`\d+(\.\d+)*`)
+
+func parseQuery(input string) (*HostQuery, error) {
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &queryParser{tokens: tokens}
+	match, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos+1)
+	}
+	return &HostQuery{source: input, match: match}, nil
+}
+
+func (q *HostQuery) Match(host *HostInfo) bool {
+	return q == nil || q.match(host)
+}
+
+func (q *HostQuery) String() string {
+	return q.source
+}
+
+func filterHosts(hosts []*HostInfo, query *HostQuery) []*HostInfo {
+	var matched []*HostInfo
+	for _, host := range hosts {
+		if query.Match(host) {
+			matched = append(matched, host)
+		}
+	}
+	return matched
+}
+
+func tokenizeQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+	for i < len(input) {
+		switch c := input[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokenOpen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokenClose, text: ")", pos: i})
+			i++
+		case c == '!':
+			tokens = append(tokens, queryToken{kind: tokenNot, text: "!", pos: i})
+			i++
+		default:
+			start := i
+			var word strings.Builder
+			quoted := false
+			for i < len(input) && !strings.ContainsRune(" \t()", rune(input[i])) {
+				if input[i] != '"' {
+					word.WriteByte(input[i])
+					i++
+					continue
+				}
+				end := strings.IndexByte(input[i+1:], '"')
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated quote at position %d", i+1)
+				}
+				word.WriteString(input[i+1 : i+1+end])
+				quoted = true
+				i += end + 2
+			}
+
+			tok := queryToken{kind: tokenTerm, text: word.String(), quoted: quoted, pos: start}
+			if !quoted {
+				switch tok.text {
+				case "AND", "&&":
+					tok.kind = tokenAnd
+				case "OR", "||":
+					tok.kind = tokenOr
+				case "NOT":
+					tok.kind = tokenNot
+				}
+			}
+			tokens = append(tokens, tok)
+		}
+	}
+	return tokens, nil
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *queryParser) parseOr() (hostMatcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok != nil && tok.kind == tokenOr; tok = p.peek() {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(host *HostInfo) bool { return l(host) || right(host) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (hostMatcher, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok != nil && tok.kind != tokenOr && tok.kind != tokenClose; tok = p.peek() {
+		if tok.kind == tokenAnd {
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(host *HostInfo) bool { return l(host) && right(host) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (hostMatcher, error) {
+	tok := p.peek()
+	if tok == nil {
+		return nil, fmt.Errorf("query ends unexpectedly")
+	}
+	p.pos++
+
+	switch tok.kind {
+	case tokenNot:
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(host *HostInfo) bool { return !inner(host) }, nil
+	case tokenOpen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing == nil || closing.kind != tokenClose {
+			return nil, fmt.Errorf("missing ) for ( at position %d", tok.pos+1)
+		}
+		p.pos++
+		return inner, nil
+	case tokenTerm:
+		return compileTerm(*tok)
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos+1)
+	}
+}
+
+func compileTerm(tok queryToken) (hostMatcher, error) {
+	field, value, found := strings.Cut(tok.text, ":")
+	if found && isFieldName(field) {
+		compile, ok := queryFields[strings.ToLower(field)]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q (use port, service, vendor, host, ip, mac, net, version, os, type or title)", field)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("%s: needs a value", field)
+		}
+		match, err := compile(strings.ToLower(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", field, err)
+		}
+		return match, nil
+	}
+
+	text := strings.ToLower(tok.text)
+	if tok.quoted {
+		return func(host *HostInfo) bool { return containsPhrase(host, text) }, nil
+	}
+	return func(host *HostInfo) bool { return matchesSearch(host, text) }, nil
+}
+
+func isFieldName(s string) bool {
+	hex := true
+	for _, c := range strings.ToLower(s) {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+		if c > 'f' {
+			hex = false
+		}
+	}
+	return s != "" && !hex
+}
+
+func containsPhrase(host *HostInfo, phrase string) bool {
+	fields := []string{host.IP, host.Hostname, host.Vendor, host.MAC, host.DeviceType, host.OSFamily}
+	for _, service := range host.Services {
+		fields = append(fields, service.Service, service.Product, service.Version, service.Banner)
+		if service.HTTP != nil {
+			fields = append(fields, service.HTTP.Title, service.HTTP.Server)
+		}
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesPattern(text, pattern string) bool {
+	text = strings.ToLower(text)
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, _ := path.Match(pattern, text)
+		return matched
+	}
+	return strings.Contains(text, pattern)
+}
+
+func portMatcher(value string) (hostMatcher, error) {
+	type portRange struct{ low, high int }
+	var ranges []portRange
+	for _, part := range strings.Split(value, ",") {
+		lowStr, highStr, isRange := strings.Cut(part, "-")
+		if !isRange {
+			highStr = lowStr
+		}
+		low, errLow := strconv.Atoi(lowStr)
+		high, errHigh := strconv.Atoi(highStr)
+		if errLow != nil || errHigh != nil || low < 1 || high > 65535 || low > high {
+			return nil, fmt.Errorf("invalid port %q", part)
+		}
+		ranges = append(ranges, portRange{low, high})
+	}
+
+	return func(host *HostInfo) bool {
+		for _, service := range host.Services {
+			for _, r := range ranges {
+				if service.Port >= r.low && service.Port <= r.high {
+					return true
+				}
+			}
+		}
+		return false
+	}, nil
+}
+
+func serviceMatcher(value string) (hostMatcher, error) {
+	names := strings.Split(value, ",")
+	return func(host *HostInfo) bool {
+		for _, service := range host.Services {
+			for _, name := range names {
+				if strings.ContainsAny(name, "*?[") && matchesPattern(service.Service, name) || strings.EqualFold(service.Service, name) {
+					return true
+				}
+			}
+		}
+		return false
+	}, nil
+}
+
+func vendorMatcher(value string) (hostMatcher, error) {
+	return func(host *HostInfo) bool {
+		vendor := host.Vendor
+		if vendor == "" {
+			vendor = "Unknown"
+		}
+		return matchesPattern(vendor, value)
+	}, nil
+}
+
+func hostnameMatcher(value string) (hostMatcher, error) {
+	return func(host *HostInfo) bool {
+		if matchesPattern(host.Hostname, value) {
+			return true
+		}
+		return host.MDNS != nil && matchesPattern(host.MDNS.Hostname, value)
+	}, nil
+}
+
+func ipMatcher(value string) (hostMatcher, error) {
+	return func(host *HostInfo) bool {
+		return matchesPattern(host.IP, value)
+	}, nil
+}
+
+func macMatcher(value string) (hostMatcher, error) {
+	value = strings.ReplaceAll(value, "-", ":")
+	return func(host *HostInfo) bool {
+		return host.MAC != "" && matchesPattern(host.MAC, value)
+	}, nil
+}
+
+func netMatcher(value string) (hostMatcher, error) {
+	var networks []*net.IPNet
+	for _, part := range strings.Split(value, ",") {
+		if !strings.Contains(part, "/") {
+			part += "/32"
+		}
+		_, ipnet, err := net.ParseCIDR(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q", part)
+		}
+		networks = append(networks, ipnet)
+	}
+
+	return func(host *HostInfo) bool {
+		ip := net.ParseIP(host.IP)
+		for _, ipnet := range networks {
+			if ip != nil && ipnet.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func versionMatcher(value string) (hostMatcher, error) {
+	op := ""
+	for _, candidate := range []string{"<=", ">=", "!=", "<", ">", "="} {
+		if rest, ok := strings.CutPrefix(value, candidate); ok {
+			op, value = candidate, rest
+			break
+		}
+	}
+
+	if op == "" {
+		return func(host *HostInfo) bool {
+			for _, service := range host.Services {
+				if matchesPattern(service.Version, value) || matchesPattern(service.Product+" "+service.Version, value) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	want := parseVersionNumber(value)
+	if want == nil {
+		return nil, fmt.Errorf("invalid version %q", value)
+	}
+
+	return func(host *HostInfo) bool {
+		for _, service := range host.Services {
+			have := parseVersionNumber(service.Version)
+			if have == nil {
+				continue
+			}
+			c := compareVersions(have, want)
+			switch op {
+			case "<":
+				if c < 0 {
+					return true
+				}
+			case "<=":
+				if c <= 0 {
+					return true
+				}
+			case ">":
+				if c > 0 {
+					return true
+				}
+			case ">=":
+				if c >= 0 {
+					return true
+				}
+			case "=":
+				if c == 0 {
+					return true
+				}
+			case "!=":
+				if c != 0 {
+					return true
+				}
+			}
+		}
+		return false
+	}, nil
+}
+
+func parseVersionNumber(s string) []int {
+	match := versionNumberPattern.FindString(s)
+	if match == "" {
+		return nil
+	}
+	var parts []int
+	for _, part := range strings.Split(match, ".") {
+		n, _ := strconv.Atoi(part)
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+func compareVersions(a, b []int) int {
+	for i := range max(len(a), len(b)) {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := cmp.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func osMatcher(value string) (hostMatcher, error) {
+	return func(host *HostInfo) bool {
+		return matchesPattern(host.OSFamily, value)
+	}, nil
+}
+
+func deviceTypeMatcher(value string) (hostMatcher, error) {
+	return func(host *HostInfo) bool {
+		return host.DeviceType != "" && (matchesPattern(host.DeviceType, value) || matchesPattern(deviceTypeLabel(host.DeviceType), value))
+	}, nil
+}
+
+func titleMatcher(value string) (hostMatcher, error) {
+	return func(host *HostInfo) bool {
+		return matchesHTTPTitle(host, value)
+	}, nil
+}
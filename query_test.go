@@ -0,0 +1,144 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func queryTestHosts() []*HostInfo {
+	return []*HostInfo{
+		{
+			IP: "10.0.1.5", Hostname: "nas.lan", Vendor: "Synology", MAC: "00:11:32:aa:bb:cc", DeviceType: "nas",
+			Services: []ServiceInfo{
+				{Port: 22, Service: "ssh", Product: "OpenSSH", Version: "7.4"},
+				{Port: 443, Service: "https", HTTP: &HTTPInfo{Title: "Synology DiskStation"}},
+			},
+		},
+		{
+			IP: "10.0.1.70", Hostname: "macbook.lan", Vendor: "Apple", MAC: "a4:83:e7:01:02:03",
+			Services: []ServiceInfo{
+				{Port: 22, Service: "ssh", Product: "OpenSSH", Version: "9.6p1"},
+			},
+		},
+		{
+			IP: "10.0.2.9", Hostname: "printer", Vendor: "",
+			Services: []ServiceInfo{
+				{Port: 23, Service: "telnet", Banner: "HP JetDirect telnet"},
+				{Port: 80, Service: "http"},
+			},
+		},
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"port:22", []string{"10.0.1.5", "10.0.1.70"}},
+		{"port:80,443", []string{"10.0.1.5", "10.0.2.9"}},
+		{"port:20-23", []string{"10.0.1.5", "10.0.1.70", "10.0.2.9"}},
+		{"!port:23", []string{"10.0.1.5", "10.0.1.70"}},
+		{"NOT port:22", []string{"10.0.2.9"}},
+		{"vendor:apple", []string{"10.0.1.70"}},
+		{"vendor:unknown", []string{"10.0.2.9"}},
+		{"host:*.lan", []string{"10.0.1.5", "10.0.1.70"}},
+		{"net:10.0.1.0/26", []string{"10.0.1.5"}},
+		{"net:10.0.2.9", []string{"10.0.2.9"}},
+		{"service:ssh", []string{"10.0.1.5", "10.0.1.70"}},
+		{"service:http", []string{"10.0.2.9"}},
+		{"service:http*", []string{"10.0.1.5", "10.0.2.9"}},
+		{"version:<8.0", []string{"10.0.1.5"}},
+		{"version:>=9", []string{"10.0.1.70"}},
+		{"version:openssh", []string{"10.0.1.5", "10.0.1.70"}},
+		{"port:22 AND vendor:apple", []string{"10.0.1.70"}},
+		{"port:22 vendor:synology", []string{"10.0.1.5"}},
+		{"vendor:apple OR port:23", []string{"10.0.1.70", "10.0.2.9"}},
+		{"(vendor:apple || vendor:synology) && !version:<8", []string{"10.0.1.70"}},
+		{"title:diskstation", []string{"10.0.1.5"}},
+		{"type:nas", []string{"10.0.1.5"}},
+		{`"jetdirect telnet"`, []string{"10.0.2.9"}},
+		{`vendor:"syno"`, []string{"10.0.1.5"}},
+		{"macbook", []string{"10.0.1.70"}},
+		{"a4:83:e7", []string{"10.0.1.70"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := parseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parseQuery(%q) error = %v", tt.query, err)
+			}
+			if got := hostIPs(filterHosts(queryTestHosts(), query)); !slices.Equal(got, tt.want) {
+				t.Errorf("parseQuery(%q) matched %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"prot:22", "unknown field"},
+		{"port:abc", "invalid port"},
+		{"port:0", "invalid port"},
+		{"port:", "needs a value"},
+		{"net:10.0.0.0/33", "invalid network"},
+		{"version:<x", "invalid version"},
+		{`vendor:"apple`, "unterminated quote"},
+		{"(port:22", "missing )"},
+		{"port:22)", "unexpected"},
+		{"port:22 OR", "ends unexpectedly"},
+	}
+
+	for _, tt := range tests {
+		if _, err := parseQuery(tt.query); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseQuery(%q) error = %v, want %q", tt.query, err, tt.want)
+		}
+	}
+
+	if query, err := parseQuery("   "); err != nil || query != nil || !query.Match(&HostInfo{}) {
+		t.Errorf("empty query should match everything, got %v %v", query, err)
+	}
+}
+
+func TestSearchQueryFeedback(t *testing.T) {
+	model := NewModularUI("10.0.0.0/16", 1, 1024, 200, false, "", nil, false)
+	model.results = queryTestHosts()
+
+	model.searchInput.SetValue("port:22 !vendor:apple")
+	filterResults(model.UIModel)
+	if model.searchErr != nil || !slices.Equal(hostIPs(model.filteredResults), []string{"10.0.1.5"}) {
+		t.Errorf("filterResults() = %v, err %v", hostIPs(model.filteredResults), model.searchErr)
+	}
+
+	model.searchInput.SetValue("prot:22")
+	filterResults(model.UIModel)
+	if model.searchErr == nil {
+		t.Fatal("expected a parse error for an unknown field")
+	}
+	if view := NewSearchComponent().View(model.UIModel); !strings.Contains(view, "unknown field") {
+		t.Errorf("search view should show the parse error:\n%s", view)
+	}
+	if len(visibleResults(model.UIModel)) != 0 {
+		t.Error("an invalid query should not fall back to showing every host")
+	}
+
+	model.searchInput.SetValue("port:3389")
+	filterResults(model.UIModel)
+	if len(visibleResults(model.UIModel)) != 0 {
+		t.Errorf("a query without matches should show no hosts, got %v", hostIPs(visibleResults(model.UIModel)))
+	}
+	if view := NewTableComponent().View(model.UIModel); !strings.Contains(view, `No hosts match "port:3389"`) {
+		t.Errorf("table should show an explicit empty state:\n%s", view)
+	}
+
+	model.searchInput.SetValue("")
+	filterResults(model.UIModel)
+	if len(visibleResults(model.UIModel)) != len(model.results) {
+		t.Error("clearing the search should show every host again")
+	}
+}
@@ -8,8 +8,19 @@ import (
 	"time"
 )
 
+type ScanOptions struct {
+	Target      string
+	StartPort   int
+	EndPort     int
+	CustomPorts []int
+	Timeout     int
+	IPsOnly     bool
+	Discovery   string
+}
+
 type ScanState struct {
 	mu           sync.RWMutex
+	options      ScanOptions
 	isScanning   bool
 	scanStart    time.Time
 	hostsScanned int
@@ -18,82 +29,114 @@ type ScanState struct {
 	openPorts    int
 	currentHost  string
 	results      []*HostInfo
+	cancel       context.CancelFunc
 }
 
-var globalScanState = &ScanState{}
+func NewScanState() *ScanState {
+	return &ScanState{results: []*HostInfo{}}
+}
 
-func GetScanProgress() ScanProgress {
-	globalScanState.mu.RLock()
-	defer globalScanState.mu.RUnlock()
+func (s *ScanState) Progress() ScanProgress {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	return ScanProgress{
-		CurrentHost:  globalScanState.currentHost,
-		HostsScanned: globalScanState.hostsScanned,
-		TotalHosts:   globalScanState.totalHosts,
-		ActiveHosts:  globalScanState.activeHosts,
-		OpenPorts:    globalScanState.openPorts,
-		StartTime:    globalScanState.scanStart,
+		CurrentHost:  s.currentHost,
+		HostsScanned: s.hostsScanned,
+		TotalHosts:   s.totalHosts,
+		ActiveHosts:  s.activeHosts,
+		OpenPorts:    s.openPorts,
+		StartTime:    s.scanStart,
 	}
 }
 
-func GetScanResults() []*HostInfo {
-	globalScanState.mu.RLock()
-	defer globalScanState.mu.RUnlock()
+func (s *ScanState) Results() []*HostInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	results := make([]*HostInfo, len(globalScanState.results))
-	copy(results, globalScanState.results)
+	results := make([]*HostInfo, len(s.results))
+	copy(results, s.results)
 	return results
 }
 
-func IsScanComplete() bool {
-	globalScanState.mu.RLock()
-	defer globalScanState.mu.RUnlock()
-	return !globalScanState.isScanning
+func (s *ScanState) IsComplete() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.isScanning
 }
 
-func StartTUIScan(targetSubnet string, startPort, endPort int, timeout time.Duration, customPorts []int, ipsOnly bool) {
-	go func() {
-		globalScanState.mu.Lock()
-		globalScanState.isScanning = true
-		globalScanState.scanStart = time.Now()
-		globalScanState.hostsScanned = 0
-		globalScanState.activeHosts = 0
-		globalScanState.openPorts = 0
-		globalScanState.results = []*HostInfo{}
-		globalScanState.mu.Unlock()
-
-		_, ipnet, err := net.ParseCIDR(targetSubnet)
-		if err != nil {
-			globalScanState.mu.Lock()
-			globalScanState.isScanning = false
-			globalScanState.mu.Unlock()
-			return
-		}
+func (s *ScanState) Options() ScanOptions {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.options
+}
 
-		var ips []string
-		for ip := ipnet.IP.Mask(ipnet.Mask); ipnet.Contains(ip); inc(ip) {
-			ips = append(ips, ip.String())
-		}
+func (s *ScanState) Start(options ScanOptions) {
+	ctx, cancel := context.WithCancel(context.Background())
 
-		globalScanState.mu.Lock()
-		globalScanState.totalHosts = len(ips)
-		globalScanState.mu.Unlock()
-		scanHostsWithTUIUpdates(ips, startPort, endPort, timeout, customPorts, ipsOnly)
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.options = options
+	s.cancel = cancel
+	s.isScanning = true
+	s.scanStart = time.Now()
+	s.hostsScanned = 0
+	s.totalHosts = 0
+	s.activeHosts = 0
+	s.openPorts = 0
+	s.currentHost = ""
+	s.results = []*HostInfo{}
+	s.mu.Unlock()
+
+	go s.run(ctx, options)
+}
+
+func (s *ScanState) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
 
-		globalScanState.mu.Lock()
-		globalScanState.isScanning = false
-		globalScanState.mu.Unlock()
+func (s *ScanState) run(ctx context.Context, options ScanOptions) {
+	defer func() {
+		s.mu.Lock()
+		s.isScanning = false
+		s.mu.Unlock()
 	}()
+
+	_, ipnet, err := net.ParseCIDR(options.Target)
+	if err != nil {
+		return
+	}
+
+	var ips []string
+	for ip := ipnet.IP.Mask(ipnet.Mask); ipnet.Contains(ip); inc(ip) {
+		ips = append(ips, ip.String())
+	}
+
+	s.mu.Lock()
+	s.totalHosts = len(ips)
+	s.mu.Unlock()
+	s.scanHosts(withDiscoveryMethod(ctx, options.Discovery), ips, options)
 }
 
-func scanHostsWithTUIUpdates(ips []string, startPort, endPort int, timeout time.Duration, customPorts []int, ipsOnly bool) {
+func (s *ScanState) scanHosts(ctx context.Context, ips []string, options ScanOptions) {
 	hostWorkers := 10
 	portWorkers := 100
 	sem := make(chan struct{}, hostWorkers)
 	var wg sync.WaitGroup
-	ctx := context.Background()
+	timeout := time.Duration(options.Timeout) * time.Millisecond
+
+	discovery := startDiscovery()
 
 	for _, ip := range ips {
+		if ctx.Err() != nil {
+			break
+		}
 		wg.Add(1)
 		sem <- struct{}{}
 
@@ -101,27 +144,48 @@ func scanHostsWithTUIUpdates(ips []string, startPort, endPort int, timeout time.
 			defer wg.Done()
 			defer func() { <-sem }()
 
-			globalScanState.mu.Lock()
-			globalScanState.currentHost = hostIP
-			globalScanState.mu.Unlock()
-			hostInfo := scanHostCustom(ctx, hostIP, startPort, endPort, timeout, portWorkers, customPorts, ipsOnly)
+			s.mu.Lock()
+			s.currentHost = hostIP
+			s.mu.Unlock()
+			hostInfo := scanHostCustom(ctx, hostIP, options.StartPort, options.EndPort, timeout, portWorkers, options.CustomPorts, options.IPsOnly)
+			discovery.Apply(hostInfo)
 
-			globalScanState.mu.Lock()
-			globalScanState.hostsScanned++
+			s.mu.Lock()
+			s.hostsScanned++
 			if hostInfo.IsReachable {
-				globalScanState.activeHosts++
-				globalScanState.openPorts += len(hostInfo.Services)
+				s.activeHosts++
+				s.openPorts += len(hostInfo.Services)
 
-				globalScanState.results = append(globalScanState.results, hostInfo)
+				s.results = append(s.results, hostInfo)
 
-				sort.Slice(globalScanState.results, func(i, j int) bool {
-					return ipToInt(globalScanState.results[i].IP) < ipToInt(globalScanState.results[j].IP)
+				sort.Slice(s.results, func(i, j int) bool {
+					return ipToInt(s.results[i].IP) < ipToInt(s.results[j].IP)
 				})
 			}
-			globalScanState.mu.Unlock()
-
+			s.mu.Unlock()
 		}(ip)
 	}
 
 	wg.Wait()
+	discovery.Close(ctx)
+
+	s.mu.RLock()
+	published := make([]*HostInfo, len(s.results))
+	copy(published, s.results)
+	s.mu.RUnlock()
+
+	updated := make(map[*HostInfo]*HostInfo, len(published))
+	for _, host := range published {
+		clone := *host
+		discovery.Apply(&clone)
+		updated[host] = &clone
+	}
+
+	s.mu.Lock()
+	for i, host := range s.results {
+		if clone, ok := updated[host]; ok {
+			s.results[i] = clone
+		}
+	}
+	s.mu.Unlock()
 }
@@ -1,12 +1,13 @@
 package main
 
 import (
+	"net"
 	"testing"
 	"time"
 )
 
 func TestScanProgress(t *testing.T) {
-	progress := GetScanProgress()
+	progress := NewScanState().Progress()
 
 	if progress.HostsScanned < 0 {
 		t.Error("HostsScanned should not be negative")
@@ -23,10 +24,10 @@ func TestScanProgress(t *testing.T) {
 }
 
 func TestScanResults(t *testing.T) {
-	results := GetScanResults()
+	results := NewScanState().Results()
 
 	if results == nil {
-		t.Error("GetScanResults() should not return nil")
+		t.Error("Results() should not return nil")
 	}
 
 	for i, host := range results {
@@ -39,14 +40,14 @@ func TestScanResults(t *testing.T) {
 	}
 }
 
-func TestIsScanComplete(t *testing.T) {
-	complete := IsScanComplete()
+func TestScanStateComplete(t *testing.T) {
+	complete := NewScanState().IsComplete()
 
 	t.Logf("scan complete: %v", complete)
 }
 
 func TestScanProgressTiming(t *testing.T) {
-	progress := GetScanProgress()
+	progress := NewScanState().Progress()
 
 	if !progress.StartTime.IsZero() {
 		if progress.StartTime.After(time.Now()) {
@@ -66,7 +67,7 @@ func TestScanProgressTiming(t *testing.T) {
 }
 
 func TestScanProgressConsistency(t *testing.T) {
-	progress := GetScanProgress()
+	progress := NewScanState().Progress()
 
 	if progress.ActiveHosts > progress.TotalHosts {
 		t.Errorf("active hosts (%d) should not exceed total hosts (%d)",
@@ -85,16 +86,16 @@ func TestScanProgressConsistency(t *testing.T) {
 }
 
 func TestScanStateThreadSafety(t *testing.T) {
-
+	state := NewScanState()
 	done := make(chan bool, 10)
 
 	for range 10 {
 		go func() {
 			defer func() { done <- true }()
 
-			GetScanProgress()
-			GetScanResults()
-			IsScanComplete()
+			state.Progress()
+			state.Results()
+			state.IsComplete()
 		}()
 	}
 
@@ -103,3 +104,75 @@ func TestScanStateThreadSafety(t *testing.T) {
 	}
 
 }
+
+func TestConcurrentScanStates(t *testing.T) {
+	targets := []string{"127.0.0.1/32", "127.0.0.2/32"}
+	states := make([]*ScanState, len(targets))
+	for i, target := range targets {
+		states[i] = NewScanState()
+		states[i].Start(ScanOptions{Target: target, CustomPorts: []int{1}, Timeout: 200, Discovery: discoveryTCP})
+		if states[i].IsComplete() {
+			t.Fatalf("scan of %s should be running right after Start", target)
+		}
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for _, state := range states {
+		for !state.IsComplete() {
+			if time.Now().After(deadline) {
+				t.Fatal("scans did not finish in time")
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	for i, state := range states {
+		progress := state.Progress()
+		if progress.TotalHosts != 1 || progress.HostsScanned != 1 {
+			t.Errorf("%s: scanned %d of %d hosts, want 1 of 1", targets[i], progress.HostsScanned, progress.TotalHosts)
+		}
+		if state.Options().Target != targets[i] {
+			t.Errorf("state %d kept options for %s", i, state.Options().Target)
+		}
+		for _, host := range state.Results() {
+			if host.IP+"/32" != targets[i] {
+				t.Errorf("scan of %s picked up host %s from another scan", targets[i], host.IP)
+			}
+		}
+	}
+}
+
+func TestScanStateAppliesDiscoveryToCopies(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	state := NewScanState()
+	state.Start(ScanOptions{Target: "127.0.0.1/32", CustomPorts: []int{port}, Timeout: 200, Discovery: discoveryTCP})
+
+	var published *HostInfo
+	deadline := time.Now().Add(10 * time.Second)
+	for !state.IsComplete() {
+		if time.Now().After(deadline) {
+			t.Fatal("scan did not finish in time")
+		}
+		if results := state.Results(); published == nil && len(results) > 0 {
+			published = results[0]
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	final := state.Results()
+	if published == nil || len(final) != 1 {
+		t.Fatalf("expected the host to be published while scanning, got %v and %d results", published, len(final))
+	}
+	if final[0] == published {
+		t.Error("discovery results should be applied to a copy instead of the host the UI already holds")
+	}
+	if final[0].IP != published.IP || len(final[0].Services) != len(published.Services) {
+		t.Errorf("the copy should keep the scanned data, got %+v", final[0])
+	}
+}
@@ -8,11 +8,23 @@ import (
 	"regexp"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	discoveryICMP = "icmp"
+	discoveryTCP  = "tcp"
+)
+
+var discoveryMethod = discoveryICMP
+
+var tcpDiscoveryPorts = []int{80, 443, 22, 445, 3389, 139, 8080, 53}
+
+var pingTTLPattern = regexp.MustCompile(`(?i)ttl[=:](\d+)`)
+
 var commonPorts = map[int]string{
 	21:   "FTP",
 	22:   "SSH",
@@ -39,8 +51,7 @@ func scanPortNew(ctx context.Context, ip string, port int, timeout time.Duration
 	address := fmt.Sprintf("%s:%d", ip, port)
 	start := time.Now()
 
-	d := net.Dialer{Timeout: timeout}
-	conn, err := d.DialContext(ctx, "tcp", address)
+	conn, err := dialTCP(ctx, address, timeout)
 	responseTime := time.Since(start)
 
 	if err != nil {
@@ -54,55 +65,83 @@ func scanPortNew(ctx context.Context, ip string, port int, timeout time.Duration
 	}
 	defer conn.Close()
 
-	banner := getBannerNew(conn, port, timeout)
-	service := getServiceNameNew(port)
-	version := extractVersionNew(banner, service)
-
-	return &ServiceInfo{
+	info := &ServiceInfo{
 		Port:         port,
 		Protocol:     "TCP",
-		Service:      service,
-		Version:      version,
-		Banner:       banner,
+		Service:      getServiceNameNew(port),
 		IsOpen:       true,
 		ResponseTime: responseTime,
-	}, nil
+	}
+	identifyService(ctx, ip, info, conn, timeout)
+
+	return info, nil
 }
 
-func getServiceNameNew(port int) string {
-	if service, exists := commonPorts[port]; exists {
-		return service
+func scanPort(ctx context.Context, ip string, port int, timeout time.Duration) (*ServiceInfo, error) {
+	if activeSYNScanner != nil {
+		info, err := activeSYNScanner.ScanPort(ctx, ip, port, timeout)
+		if err != nil || !info.IsOpen || !synServiceDetection {
+			return info, err
+		}
+
+		conn, err := dialTCP(ctx, net.JoinHostPort(ip, fmt.Sprintf("%d", port)), timeout)
+		if err != nil {
+			return info, nil
+		}
+		defer conn.Close()
+		identifyService(ctx, ip, info, conn, timeout)
+		return info, nil
 	}
-	return "Unknown"
+	return scanPortNew(ctx, ip, port, timeout)
 }
 
-func getBannerNew(conn net.Conn, port int, timeout time.Duration) string {
-	conn.SetReadDeadline(time.Now().Add(timeout))
+func identifyService(ctx context.Context, ip string, info *ServiceInfo, conn net.Conn, timeout time.Duration) {
+	detection := detectService(ctx, ip, info.Port, conn, timeout)
+	info.Banner = detection.Banner
 
-	switch port {
-	case 80, 8080:
-		conn.Write([]byte("GET / HTTP/1.1\r\nHost: \r\n\r\n"))
-	case 21:
-	case 22:
-	case 25:
-	default:
+	if detection.Matched {
+		info.Service = detection.Service
+		info.Product = detection.Product
+		info.ExtraInfo = detection.ExtraInfo
+		info.Version = strings.TrimSpace(detection.Product + " " + detection.Version)
+	} else {
+		info.Version = extractVersionNew(detection.Banner, info.Service)
 	}
 
-	buffer := make([]byte, 1024)
-	n, err := conn.Read(buffer)
-	if err != nil || n == 0 {
-		return ""
+	if shouldInspectTLS(info, detection.Matched) {
+		if tlsInfo, err := inspectTLS(ctx, ip, info.Port, timeout); err == nil {
+			info.TLS = tlsInfo
+			if info.Service == "Unknown" {
+				info.Service = "TLS"
+			}
+		}
 	}
 
-	banner := strings.TrimSpace(string(buffer[:n]))
-	re := regexp.MustCompile(`[[:print:]]+`)
-	cleanBanner := strings.Join(re.FindAllString(banner, -1), " ")
+	if info.Service == "SSH" {
+		if sshInfo, err := probeSSH(ctx, ip, info.Port, timeout); err == nil {
+			checkSSHHostKeys(ip, info.Port, sshInfo)
+			info.SSH = sshInfo
+		}
+	}
 
-	if len(cleanBanner) > 100 {
-		cleanBanner = cleanBanner[:100] + "..."
+	if isHTTPService(info) {
+		if httpInfo, err := probeHTTP(ctx, ip, info.Port, info.TLS != nil, timeout); err == nil {
+			info.HTTP = httpInfo
+			if info.TLS != nil && info.Service == "TLS" {
+				info.Service = "HTTPS"
+			}
+			if info.Version == "" && httpInfo.Server != "" {
+				info.Version = strings.Replace(httpInfo.Server, "/", " ", 1)
+			}
+		}
 	}
+}
 
-	return cleanBanner
+func getServiceNameNew(port int) string {
+	if service, exists := commonPorts[port]; exists {
+		return service
+	}
+	return "Unknown"
 }
 
 func extractVersionNew(banner, service string) string {
@@ -110,9 +149,14 @@ func extractVersionNew(banner, service string) string {
 		return ""
 	}
 
+	if service == "SSH" {
+		if _, software, _, ok := parseSSHIdentification(banner); ok {
+			return sshSoftwareVersion(software)
+		}
+	}
+
 	patterns := map[string]*regexp.Regexp{
 		"HTTP": regexp.MustCompile(`(?i)(Apache|nginx|IIS)/([0-9.]+)`),
-		"SSH":  regexp.MustCompile(`(?i)OpenSSH[_\s]([0-9.]+)`),
 		"FTP":  regexp.MustCompile(`(?i)(FileZilla|vsftpd|ProFTPD)\s+([0-9.]+)`),
 		"SMTP": regexp.MustCompile(`(?i)(Postfix|Sendmail|Exchange)\s+([0-9.]+)`),
 	}
@@ -139,16 +183,19 @@ func scanHostNew(ctx context.Context, ip string, startPort, endPort int, timeout
 		Services: make([]ServiceInfo, 0),
 	}
 
-	reachable, responseTime := pingHostNew(ip, timeout)
+	reachable, responseTime, ttl, method := probeHost(ctx, ip, timeout)
 	hostInfo.IsReachable = reachable
 	hostInfo.ResponseTime = responseTime
+	hostInfo.TTL = ttl
+	hostInfo.DiscoveryMethod = method
 
 	if !reachable {
 		return hostInfo
 	}
+	hostInfo.FirstSeen = time.Now()
 
 	hostInfo.Hostname = getHostnameNew(ip)
-	hostInfo.MAC, hostInfo.Vendor = getMACAddressNew(ip)
+	hostInfo.MAC, hostInfo.Vendor = resolveHostMAC(ip)
 
 	sem := make(chan struct{}, workers)
 	var wg sync.WaitGroup
@@ -162,7 +209,7 @@ func scanHostNew(ctx context.Context, ip string, startPort, endPort int, timeout
 			defer wg.Done()
 			defer func() { <-sem }()
 
-			serviceInfo, err := scanPortNew(ctx, ip, p, timeout)
+			serviceInfo, err := scanPort(ctx, ip, p, timeout)
 			if err == nil && serviceInfo.IsOpen {
 				mu.Lock()
 				hostInfo.Services = append(hostInfo.Services, *serviceInfo)
@@ -176,11 +223,12 @@ func scanHostNew(ctx context.Context, ip string, startPort, endPort int, timeout
 	sort.Slice(hostInfo.Services, func(i, j int) bool {
 		return hostInfo.Services[i].Port < hostInfo.Services[j].Port
 	})
+	hostInfo.LastSeen = time.Now()
 
 	return hostInfo
 }
 
-func pingHostNew(ip string, timeout time.Duration) (bool, time.Duration) {
+func pingHostNew(ip string, timeout time.Duration) (bool, time.Duration, int) {
 	start := time.Now()
 	
 	var cmd *exec.Cmd
@@ -194,10 +242,85 @@ func pingHostNew(ip string, timeout time.Duration) (bool, time.Duration) {
 		cmd = exec.Command("ping", "-c", "1", "-W", fmt.Sprintf("%d", timeoutSecs), ip)
 	}
 	
-	err := cmd.Run()
+	output, err := cmd.Output()
 	responseTime := time.Since(start)
 
-	return err == nil, responseTime
+	ttl := 0
+	if matches := pingTTLPattern.FindSubmatch(output); len(matches) > 1 {
+		ttl, _ = strconv.Atoi(string(matches[1]))
+	}
+
+	return err == nil, responseTime, ttl
+}
+
+type discoveryMethodKey struct{}
+
+func withDiscoveryMethod(ctx context.Context, method string) context.Context {
+	if method == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, discoveryMethodKey{}, method)
+}
+
+func discoveryMethodFor(ctx context.Context) string {
+	if method, ok := ctx.Value(discoveryMethodKey{}).(string); ok {
+		return method
+	}
+	return discoveryMethod
+}
+
+func probeHost(ctx context.Context, ip string, timeout time.Duration) (bool, time.Duration, int, string) {
+	if discoveryMethodFor(ctx) == discoveryTCP {
+		reachable, responseTime, method := tcpPingHost(ctx, ip, timeout)
+		return reachable, responseTime, 0, method
+	}
+
+	reachable, responseTime, ttl := pingHostNew(ip, timeout)
+	if !reachable {
+		return false, responseTime, ttl, ""
+	}
+	return true, responseTime, ttl, discoveryICMP
+}
+
+func tcpPingHost(ctx context.Context, ip string, timeout time.Duration) (bool, time.Duration, string) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type tcpPingResult struct {
+		port    int
+		refused bool
+		elapsed time.Duration
+	}
+
+	start := time.Now()
+	results := make(chan tcpPingResult, len(tcpDiscoveryPorts))
+	for _, port := range tcpDiscoveryPorts {
+		go func(port int) {
+			conn, err := dialTCP(ctx, net.JoinHostPort(ip, strconv.Itoa(port)), timeout)
+			switch {
+			case err == nil:
+				conn.Close()
+				results <- tcpPingResult{port: port, elapsed: time.Since(start)}
+			case isConnectionRefused(err):
+				results <- tcpPingResult{port: port, refused: true, elapsed: time.Since(start)}
+			default:
+				results <- tcpPingResult{}
+			}
+		}(port)
+	}
+
+	for range tcpDiscoveryPorts {
+		result := <-results
+		if result.port == 0 {
+			continue
+		}
+		method := fmt.Sprintf("%s/%d", discoveryTCP, result.port)
+		if result.refused {
+			method += " refused"
+		}
+		return true, result.elapsed, method
+	}
+	return false, time.Since(start), ""
 }
 
 func getHostnameNew(ip string) string {
@@ -342,18 +465,23 @@ func scanHostCustom(ctx context.Context, ip string, startPort, endPort int, time
 		Services: make([]ServiceInfo, 0),
 	}
 
-	reachable, responseTime := pingHostNew(ip, timeout)
+	reachable, responseTime, ttl, method := probeHost(ctx, ip, timeout)
 	hostInfo.IsReachable = reachable
 	hostInfo.ResponseTime = responseTime
+	hostInfo.TTL = ttl
+	hostInfo.DiscoveryMethod = method
 
 	if !reachable {
 		return hostInfo
 	}
+	hostInfo.FirstSeen = time.Now()
 
 	hostInfo.Hostname = getHostnameNew(ip)
-	hostInfo.MAC, hostInfo.Vendor = getMACAddressNew(ip)
+	hostInfo.MAC, hostInfo.Vendor = resolveHostMAC(ip)
 
 	if ipsOnly {
+		identifyHost(ctx, hostInfo, timeout)
+		hostInfo.LastSeen = time.Now()
 		return hostInfo
 	}
 
@@ -378,7 +506,7 @@ func scanHostCustom(ctx context.Context, ip string, startPort, endPort int, time
 			defer wg.Done()
 			defer func() { <-sem }()
 
-			serviceInfo, err := scanPortNew(ctx, ip, p, timeout)
+			serviceInfo, err := scanPort(ctx, ip, p, timeout)
 			if err == nil && serviceInfo.IsOpen {
 				mu.Lock()
 				hostInfo.Services = append(hostInfo.Services, *serviceInfo)
@@ -393,9 +521,69 @@ func scanHostCustom(ctx context.Context, ip string, startPort, endPort int, time
 		return hostInfo.Services[i].Port < hostInfo.Services[j].Port
 	})
 
+	identifyHost(ctx, hostInfo, timeout)
+	hostInfo.LastSeen = time.Now()
+
 	return hostInfo
 }
 
+func identifyHost(ctx context.Context, hostInfo *HostInfo, timeout time.Duration) {
+	if activeProxy == nil {
+		identifyHostUDP(ctx, hostInfo, timeout)
+	}
+
+	for _, service := range hostInfo.Services {
+		if service.Port != 445 {
+			continue
+		}
+		if smb, err := probeSMB(ctx, hostInfo.IP, service.Port, timeout); err == nil {
+			hostInfo.SMB = smb
+			if hostInfo.Hostname == "" {
+				if smb.DNSComputer != "" {
+					hostInfo.Hostname = smb.DNSComputer
+				} else {
+					hostInfo.Hostname = smb.NetBIOSComputer
+				}
+			}
+		}
+	}
+
+	classifyHost(hostInfo)
+	fingerprintOS(hostInfo)
+}
+
+func identifyHostUDP(ctx context.Context, hostInfo *HostInfo, timeout time.Duration) {
+	if netbios, err := queryNetBIOS(ctx, hostInfo.IP, timeout); err == nil {
+		hostInfo.NetBIOS = netbios
+		if hostInfo.Hostname == "" {
+			hostInfo.Hostname = netbios.Name
+		}
+		if hostInfo.MAC == "" && netbios.MAC != "" {
+			hostInfo.MAC = netbios.MAC
+			hostInfo.Vendor = getEnhancedVendor(netbios.MAC)
+		}
+	}
+
+	if len(snmpCommunities) > 0 {
+		if snmp, err := probeSNMP(ctx, hostInfo.IP, timeout); err == nil {
+			hostInfo.SNMP = snmp
+			if (hostInfo.Vendor == "" || hostInfo.Vendor == "Unknown") && snmp.EnterpriseVendor != "" {
+				hostInfo.Vendor = snmp.EnterpriseVendor
+			}
+			if hostInfo.Hostname == "" {
+				hostInfo.Hostname = snmp.SysName
+			}
+		}
+	}
+
+	if hostInfo.Hostname == "" {
+		if mdns, err := resolveMulticastName(ctx, hostInfo.IP, timeout); err == nil {
+			hostInfo.MDNS = mdns
+			hostInfo.Hostname = mdns.Hostname
+		}
+	}
+}
+
 func getCommonPorts() []int {
 	ports := make([]int, 0, len(commonPorts))
 	for port := range commonPorts {
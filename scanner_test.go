@@ -133,3 +133,21 @@ func TestBannerGrabbing(t *testing.T) {
 		}
 	}
 }
+
+func TestPingTTLPattern(t *testing.T) {
+	tests := map[string]string{
+		"64 bytes from 192.168.1.1: icmp_seq=1 ttl=64 time=0.512 ms":     "64",
+		"Reply from 192.168.1.10: bytes=32 time<1ms TTL=128":             "128",
+		"PING 10.0.0.1 (10.0.0.1) 56(84) bytes of data.\n1 packets lost": "",
+	}
+
+	for output, expected := range tests {
+		got := ""
+		if matches := pingTTLPattern.FindStringSubmatch(output); len(matches) > 1 {
+			got = matches[1]
+		}
+		if got != expected {
+			t.Errorf("TTL from %q = %q, expected %q", output, got, expected)
+		}
+	}
+}
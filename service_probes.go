@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed service_probes.json
+var defaultServiceProbes []byte
+
+var activeProbeDB *ProbeDatabase
+var probeDBOnce sync.Once
+
+const maxFallbackProbes = 2
+
+type ServiceMatch struct {
+	Service string `json:"service"`
+	Pattern string `json:"pattern"`
+	Product string `json:"product"`
+	Version string `json:"version"`
+	Info    string `json:"info"`
+
+	re *regexp.Regexp
+}
+
+type ServiceProbe struct {
+	Name    string          `json:"name"`
+	Payload string          `json:"payload"`
+	Ports   []int           `json:"ports"`
+	Matches []*ServiceMatch `json:"matches"`
+
+	payload []byte
+}
+
+type ProbeDatabase struct {
+	Probes []*ServiceProbe `json:"probes"`
+}
+
+type serviceDetection struct {
+	Service   string
+	Product   string
+	Version   string
+	ExtraInfo string
+	Banner    string
+	Matched   bool
+}
+
+func LoadProbeDatabase(path string) (*ProbeDatabase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read probe database: %v", err)
+	}
+	return parseProbeDatabase(data)
+}
+
+func parseProbeDatabase(data []byte) (*ProbeDatabase, error) {
+	var db ProbeDatabase
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, fmt.Errorf("invalid probe database: %v", err)
+	}
+
+	for _, probe := range db.Probes {
+		payload, err := latin1Bytes(probe.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("probe %s: %v", probe.Name, err)
+		}
+		probe.payload = payload
+
+		for _, match := range probe.Matches {
+			re, err := regexp.Compile(match.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("probe %s: invalid pattern %q: %v", probe.Name, match.Pattern, err)
+			}
+			match.re = re
+		}
+	}
+
+	return &db, nil
+}
+
+func getProbeDatabase() *ProbeDatabase {
+	probeDBOnce.Do(func() {
+		if activeProbeDB != nil {
+			return
+		}
+		db, err := parseProbeDatabase(defaultServiceProbes)
+		if err != nil {
+			panic(fmt.Sprintf("bundled service probe database is invalid: %v", err))
+		}
+		activeProbeDB = db
+	})
+	return activeProbeDB
+}
+
+func latin1Bytes(s string) ([]byte, error) {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xff {
+			return nil, fmt.Errorf("payload character %q is outside the 0x00-0xff range", r)
+		}
+		b = append(b, byte(r))
+	}
+	return b, nil
+}
+
+func latin1String(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func (m *ServiceMatch) apply(response string) (serviceDetection, bool) {
+	submatches := m.re.FindStringSubmatchIndex(response)
+	if submatches == nil {
+		return serviceDetection{}, false
+	}
+
+	expand := func(template string) string {
+		if template == "" {
+			return ""
+		}
+		value := m.re.ExpandString(nil, template, response, submatches)
+		return strings.Join(strings.Fields(string(value)), " ")
+	}
+
+	return serviceDetection{
+		Service:   m.Service,
+		Product:   expand(m.Product),
+		Version:   expand(m.Version),
+		ExtraInfo: expand(m.Info),
+		Matched:   true,
+	}, true
+}
+
+func (db *ProbeDatabase) match(probe *ServiceProbe, response []byte) (serviceDetection, bool) {
+	text := latin1String(response)
+
+	for _, match := range probe.Matches {
+		if result, ok := match.apply(text); ok {
+			return result, true
+		}
+	}
+
+	for _, other := range db.Probes {
+		if other == probe {
+			continue
+		}
+		for _, match := range other.Matches {
+			if result, ok := match.apply(text); ok {
+				return result, true
+			}
+		}
+	}
+
+	return serviceDetection{}, false
+}
+
+func (db *ProbeDatabase) orderedProbes(port int) []*ServiceProbe {
+	var preferred, fallback []*ServiceProbe
+	for _, probe := range db.Probes {
+		if len(probe.payload) == 0 {
+			continue
+		}
+		if slices.Contains(probe.Ports, port) {
+			preferred = append(preferred, probe)
+		} else if len(fallback) < maxFallbackProbes {
+			fallback = append(fallback, probe)
+		}
+	}
+	return append(preferred, fallback...)
+}
+
+func (db *ProbeDatabase) nullProbe() *ServiceProbe {
+	for _, probe := range db.Probes {
+		if len(probe.payload) == 0 {
+			return probe
+		}
+	}
+	return &ServiceProbe{Name: "NULL"}
+}
+
+func detectService(ctx context.Context, ip string, port int, conn net.Conn, timeout time.Duration) serviceDetection {
+	db := getProbeDatabase()
+	var banner string
+
+	response := readProbeResponse(conn, timeout)
+	if len(response) > 0 {
+		banner = cleanBanner(response)
+		if result, ok := db.match(db.nullProbe(), response); ok {
+			result.Banner = banner
+			return result
+		}
+	}
+
+	address := net.JoinHostPort(ip, fmt.Sprintf("%d", port))
+	for _, probe := range db.orderedProbes(port) {
+		if ctx.Err() != nil {
+			break
+		}
+
+		probeConn, err := dialTCP(ctx, address, timeout)
+		if err != nil {
+			break
+		}
+
+		probeConn.SetWriteDeadline(time.Now().Add(timeout))
+		_, err = probeConn.Write(probe.payload)
+		if err == nil {
+			response = readProbeResponse(probeConn, timeout)
+		} else {
+			response = nil
+		}
+		probeConn.Close()
+
+		if len(response) == 0 {
+			continue
+		}
+		if banner == "" {
+			banner = cleanBanner(response)
+		}
+		if result, ok := db.match(probe, response); ok {
+			result.Banner = cleanBanner(response)
+			return result
+		}
+	}
+
+	return serviceDetection{Banner: banner}
+}
+
+func readProbeResponse(conn net.Conn, timeout time.Duration) []byte {
+	conn.SetReadDeadline(time.Now().Add(timeout))
+
+	buffer := make([]byte, 4096)
+	total := 0
+	for total < len(buffer) {
+		n, err := conn.Read(buffer[total:])
+		total += n
+		if err != nil || n == 0 {
+			break
+		}
+		conn.SetReadDeadline(time.Now().Add(timeout / 4))
+	}
+
+	return buffer[:total]
+}
+
+func cleanBanner(data []byte) string {
+	banner := strings.TrimSpace(string(data))
+	re := regexp.MustCompile(`[[:print:]]+`)
+	cleaned := strings.Join(re.FindAllString(banner, -1), " ")
+
+	if len(cleaned) > 100 {
+		cleaned = cleaned[:100] + "..."
+	}
+
+	return cleaned
+}
+
+func sanitizeText(s string, limit int) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+
+	if limit > 0 && utf8.RuneCountInString(s) > limit {
+		s = string([]rune(s)[:limit]) + "..."
+	}
+	return s
+}
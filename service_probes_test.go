@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func startTestListener(t *testing.T, handle func(conn net.Conn)) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestBundledProbeDatabase(t *testing.T) {
+	db, err := parseProbeDatabase(defaultServiceProbes)
+	if err != nil {
+		t.Fatalf("bundled probe database failed to parse: %v", err)
+	}
+
+	if len(db.Probes) == 0 {
+		t.Fatal("bundled probe database has no probes")
+	}
+
+	if db.nullProbe().Name != "NULL" {
+		t.Errorf("expected NULL probe, got %s", db.nullProbe().Name)
+	}
+
+	for _, probe := range db.orderedProbes(8000) {
+		if len(probe.payload) == 0 {
+			t.Errorf("probe %s without payload should not be ordered", probe.Name)
+		}
+	}
+	if first := db.orderedProbes(8000)[0]; first.Name != "GetRequest" {
+		t.Errorf("expected GetRequest first for port 8000, got %s", first.Name)
+	}
+
+	if probes := db.orderedProbes(41234); len(probes) != maxFallbackProbes {
+		t.Errorf("an unknown port should only get %d fallback probes, got %d", maxFallbackProbes, len(probes))
+	}
+	redis := db.orderedProbes(6379)
+	if redis[0].Name != "RedisPing" || len(redis) != 1+maxFallbackProbes {
+		t.Errorf("expected RedisPing plus %d fallbacks for port 6379, got %d probes starting with %s",
+			maxFallbackProbes, len(redis), redis[0].Name)
+	}
+}
+
+func TestParseProbeDatabaseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"invalid json", `{"probes": [`},
+		{"invalid regex", `{"probes": [{"name": "X", "matches": [{"service": "X", "pattern": "("}]}]}`},
+		{"payload out of range", `{"probes": [{"name": "X", "payload": "☃"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseProbeDatabase([]byte(tt.data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestServiceMatchBinary(t *testing.T) {
+	db := getProbeDatabase()
+
+	greeting := []byte("\x4a\x00\x00\x00\x0a8.0.36-0ubuntu0.22.04.1\x00\x08\x00\x00\x00")
+	result, ok := db.match(db.nullProbe(), greeting)
+	if !ok {
+		t.Fatal("expected MySQL greeting to match")
+	}
+	if result.Service != "MySQL" || result.Version != "8.0.36-0ubuntu0.22.04.1" {
+		t.Errorf("got service=%q version=%q", result.Service, result.Version)
+	}
+
+	telnet := []byte{0xff, 0xfd, 0x18, 0xff, 0xfd, 0x20}
+	if result, ok := db.match(db.nullProbe(), telnet); !ok || result.Service != "Telnet" {
+		t.Errorf("expected telnet negotiation to match, got %+v", result)
+	}
+}
+
+func TestDetectSSHOnNonStandardPort(t *testing.T) {
+	port := startTestListener(t, func(conn net.Conn) {
+		conn.Write([]byte("SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13\r\n"))
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	info, err := scanPortNew(context.Background(), "127.0.0.1", port, 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("scanPortNew() error: %v", err)
+	}
+
+	if info.Service != "SSH" {
+		t.Errorf("service = %q, expected SSH", info.Service)
+	}
+	if info.Product != "OpenSSH" {
+		t.Errorf("product = %q, expected OpenSSH", info.Product)
+	}
+	if info.Version != "OpenSSH 9.6p1" {
+		t.Errorf("version = %q, expected \"OpenSSH 9.6p1\"", info.Version)
+	}
+	if !strings.Contains(info.ExtraInfo, "Ubuntu-3ubuntu13") {
+		t.Errorf("extra info = %q, expected distribution suffix", info.ExtraInfo)
+	}
+}
+
+func TestDetectHTTPOnNonStandardPort(t *testing.T) {
+	port := startTestListener(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil || line == "\r\n" {
+				break
+			}
+		}
+		conn.Write([]byte("HTTP/1.1 200 OK\r\nServer: nginx/1.24.0\r\nContent-Length: 0\r\n\r\n"))
+	})
+
+	info, err := scanPortNew(context.Background(), "127.0.0.1", port, 300*time.Millisecond)
+	if err != nil {
+		t.Fatalf("scanPortNew() error: %v", err)
+	}
+
+	if info.Service != "HTTP" {
+		t.Errorf("service = %q, expected HTTP", info.Service)
+	}
+	if info.Version != "nginx 1.24.0" {
+		t.Errorf("version = %q, expected \"nginx 1.24.0\"", info.Version)
+	}
+}
+
+func TestLoadProbeDatabaseFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "probes.json")
+	data := `{"probes": [{"name": "Hello", "payload": "HELLO\r\n", "ports": [7777],
+		"matches": [{"service": "Greeter", "pattern": "^WELCOME v([\\d.]+)", "product": "Greeter", "version": "$1"}]}]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write probe file: %v", err)
+	}
+
+	db, err := LoadProbeDatabase(path)
+	if err != nil {
+		t.Fatalf("LoadProbeDatabase() error: %v", err)
+	}
+
+	result, ok := db.match(db.Probes[0], []byte("WELCOME v1.2\n"))
+	if !ok {
+		t.Fatal("expected custom matcher to match")
+	}
+	if result.Service != "Greeter" || result.Version != "1.2" {
+		t.Errorf("got service=%q version=%q", result.Service, result.Version)
+	}
+
+	if _, err := LoadProbeDatabase(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,483 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	smb2CommandNegotiate    = 0x0000
+	smb2CommandSessionSetup = 0x0001
+
+	smbStatusSuccess        = 0x00000000
+	smbStatusMoreProcessing = 0xc0000016
+	smb2SigningEnabled      = 0x01
+	smb2SigningRequired     = 0x02
+	smbMaxMessageSize       = 64 * 1024
+	ntlmsspNegotiateFlags   = 0xa2088207
+	smbPreauthIntegrityCaps = 0x0001
+	smbPreauthHashSHA512    = 0x0001
+	ntlmAvEOL               = 0
+	ntlmAvNbComputerName    = 1
+	ntlmAvNbDomainName      = 2
+	ntlmAvDNSComputerName   = 3
+	ntlmAvDNSDomainName     = 4
+	ntlmAvDNSTreeName       = 5
+)
+
+var smb2Dialects = []struct {
+	code uint16
+	name string
+}{
+	{0x0202, "2.0.2"},
+	{0x0210, "2.1"},
+	{0x0300, "3.0"},
+	{0x0302, "3.0.2"},
+	{0x0311, "3.1.1"},
+}
+
+var ntlmsspSignature = []byte("NTLMSSP\x00")
+
+type SMBInfo struct {
+	Dialects        []string
+	SMB1            bool
+	SigningEnabled  bool
+	SigningRequired bool
+	NetBIOSComputer string
+	NetBIOSDomain   string
+	DNSComputer     string
+	DNSDomain       string
+	DNSForest       string
+	OSVersion       string
+	OSHint          string
+}
+
+type smb2NegotiateResponse struct {
+	SecurityMode uint16
+	Dialect      uint16
+}
+
+type ntlmChallenge struct {
+	NetBIOSComputer string
+	NetBIOSDomain   string
+	DNSComputer     string
+	DNSDomain       string
+	DNSForest       string
+	Major           int
+	Minor           int
+	Build           int
+}
+
+func buildSMB2Header(command uint16, messageID uint64) []byte {
+	header := make([]byte, 64)
+	copy(header[0:4], "\xfeSMB")
+	binary.LittleEndian.PutUint16(header[4:6], 64)
+	binary.LittleEndian.PutUint16(header[12:14], command)
+	binary.LittleEndian.PutUint16(header[14:16], 1)
+	binary.LittleEndian.PutUint64(header[24:32], messageID)
+	return header
+}
+
+func buildSMB2NegotiateRequest(dialects []uint16) []byte {
+	body := make([]byte, 36)
+	binary.LittleEndian.PutUint16(body[0:2], 36)
+	binary.LittleEndian.PutUint16(body[2:4], uint16(len(dialects)))
+	binary.LittleEndian.PutUint16(body[4:6], smb2SigningEnabled)
+	rand.Read(body[12:28])
+
+	for _, dialect := range dialects {
+		body = binary.LittleEndian.AppendUint16(body, dialect)
+	}
+
+	hasSMB311 := false
+	for _, dialect := range dialects {
+		if dialect == 0x0311 {
+			hasSMB311 = true
+		}
+	}
+
+	if hasSMB311 {
+		for (64+len(body))%8 != 0 {
+			body = append(body, 0)
+		}
+		binary.LittleEndian.PutUint32(body[28:32], uint32(64+len(body)))
+		binary.LittleEndian.PutUint16(body[32:34], 1)
+
+		salt := make([]byte, 32)
+		rand.Read(salt)
+		data := binary.LittleEndian.AppendUint16(nil, 1)
+		data = binary.LittleEndian.AppendUint16(data, uint16(len(salt)))
+		data = binary.LittleEndian.AppendUint16(data, smbPreauthHashSHA512)
+		data = append(data, salt...)
+
+		body = binary.LittleEndian.AppendUint16(body, smbPreauthIntegrityCaps)
+		body = binary.LittleEndian.AppendUint16(body, uint16(len(data)))
+		body = append(body, 0, 0, 0, 0)
+		body = append(body, data...)
+	}
+
+	return append(buildSMB2Header(smb2CommandNegotiate, 0), body...)
+}
+
+func buildSMB1NegotiateRequest() []byte {
+	header := make([]byte, 32)
+	copy(header[0:4], "\xffSMB")
+	header[4] = 0x72
+	header[9] = 0x18
+	binary.LittleEndian.PutUint16(header[10:12], 0xc801)
+
+	dialect := append([]byte{0x02}, "NT LM 0.12\x00"...)
+	body := []byte{0}
+	body = binary.LittleEndian.AppendUint16(body, uint16(len(dialect)))
+	body = append(body, dialect...)
+
+	return append(header, body...)
+}
+
+func buildNTLMSSPNegotiate() []byte {
+	msg := append([]byte{}, ntlmsspSignature...)
+	msg = binary.LittleEndian.AppendUint32(msg, 1)
+	msg = binary.LittleEndian.AppendUint32(msg, ntlmsspNegotiateFlags)
+	msg = append(msg, make([]byte, 16)...)
+	return append(msg, 6, 1, 0xb1, 0x1d, 0, 0, 0, 0x0f)
+}
+
+func derWrap(tag byte, content []byte) []byte {
+	length := len(content)
+	var encoded []byte
+	switch {
+	case length < 0x80:
+		encoded = []byte{tag, byte(length)}
+	case length < 0x100:
+		encoded = []byte{tag, 0x81, byte(length)}
+	default:
+		encoded = []byte{tag, 0x82, byte(length >> 8), byte(length)}
+	}
+	return append(encoded, content...)
+}
+
+func buildSPNEGONegTokenInit(mechToken []byte) []byte {
+	spnegoOID := []byte{0x06, 0x06, 0x2b, 0x06, 0x01, 0x05, 0x05, 0x02}
+	ntlmOID := []byte{0x06, 0x0a, 0x2b, 0x06, 0x01, 0x04, 0x01, 0x82, 0x37, 0x02, 0x02, 0x0a}
+
+	mechTypes := derWrap(0xa0, derWrap(0x30, ntlmOID))
+	token := derWrap(0xa2, derWrap(0x04, mechToken))
+	negTokenInit := derWrap(0xa0, derWrap(0x30, append(mechTypes, token...)))
+
+	return derWrap(0x60, append(spnegoOID, negTokenInit...))
+}
+
+func buildSMB2SessionSetupRequest(messageID uint64, securityBlob []byte) []byte {
+	body := make([]byte, 24)
+	binary.LittleEndian.PutUint16(body[0:2], 25)
+	body[3] = smb2SigningEnabled
+	binary.LittleEndian.PutUint16(body[12:14], 64+24)
+	binary.LittleEndian.PutUint16(body[14:16], uint16(len(securityBlob)))
+	body = append(body, securityBlob...)
+
+	return append(buildSMB2Header(smb2CommandSessionSetup, messageID), body...)
+}
+
+func writeSMBMessage(conn net.Conn, message []byte) error {
+	frame := make([]byte, 4, 4+len(message))
+	binary.BigEndian.PutUint32(frame, uint32(len(message)))
+	_, err := conn.Write(append(frame, message...))
+	return err
+}
+
+func readSMBMessage(conn net.Conn) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[:]) & 0x00ffffff
+	if length < 4 || length > smbMaxMessageSize {
+		return nil, fmt.Errorf("invalid SMB message length %d", length)
+	}
+
+	message := make([]byte, length)
+	if _, err := io.ReadFull(conn, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func parseSMB2Status(message []byte) (uint32, error) {
+	if len(message) < 64 || !bytes.Equal(message[0:4], []byte("\xfeSMB")) {
+		return 0, fmt.Errorf("not an SMB2 message")
+	}
+	return binary.LittleEndian.Uint32(message[8:12]), nil
+}
+
+func parseSMB2NegotiateResponse(message []byte) (*smb2NegotiateResponse, error) {
+	status, err := parseSMB2Status(message)
+	if err != nil {
+		return nil, err
+	}
+	if status != smbStatusSuccess {
+		return nil, fmt.Errorf("negotiate failed with status 0x%08x", status)
+	}
+	if len(message) < 64+8 {
+		return nil, fmt.Errorf("truncated negotiate response")
+	}
+
+	body := message[64:]
+	return &smb2NegotiateResponse{
+		SecurityMode: binary.LittleEndian.Uint16(body[2:4]),
+		Dialect:      binary.LittleEndian.Uint16(body[4:6]),
+	}, nil
+}
+
+func parseSMB2SecurityBuffer(message []byte) ([]byte, error) {
+	if len(message) < 64+8 {
+		return nil, fmt.Errorf("truncated session setup response")
+	}
+	body := message[64:]
+	offset := int(binary.LittleEndian.Uint16(body[4:6]))
+	length := int(binary.LittleEndian.Uint16(body[6:8]))
+	if offset+length > len(message) || offset < 64 {
+		return nil, fmt.Errorf("invalid security buffer")
+	}
+	return message[offset : offset+length], nil
+}
+
+func parseNTLMChallenge(blob []byte) (*ntlmChallenge, error) {
+	start := bytes.Index(blob, ntlmsspSignature)
+	if start < 0 {
+		return nil, fmt.Errorf("no NTLMSSP message found")
+	}
+	msg := blob[start:]
+	if len(msg) < 48 || binary.LittleEndian.Uint32(msg[8:12]) != 2 {
+		return nil, fmt.Errorf("not an NTLMSSP challenge")
+	}
+
+	challenge := &ntlmChallenge{}
+	flags := binary.LittleEndian.Uint32(msg[20:24])
+
+	infoLen := int(binary.LittleEndian.Uint16(msg[40:42]))
+	infoOffset := int(binary.LittleEndian.Uint32(msg[44:48]))
+	if infoOffset+infoLen <= len(msg) && infoLen > 0 {
+		info := msg[infoOffset : infoOffset+infoLen]
+		for len(info) >= 4 {
+			id := binary.LittleEndian.Uint16(info[0:2])
+			length := int(binary.LittleEndian.Uint16(info[2:4]))
+			if id == ntlmAvEOL || 4+length > len(info) {
+				break
+			}
+			value := sanitizeText(decodeUTF16LE(info[4:4+length]), 0)
+			switch id {
+			case ntlmAvNbComputerName:
+				challenge.NetBIOSComputer = value
+			case ntlmAvNbDomainName:
+				challenge.NetBIOSDomain = value
+			case ntlmAvDNSComputerName:
+				challenge.DNSComputer = value
+			case ntlmAvDNSDomainName:
+				challenge.DNSDomain = value
+			case ntlmAvDNSTreeName:
+				challenge.DNSForest = value
+			}
+			info = info[4+length:]
+		}
+	}
+
+	if flags&0x02000000 != 0 && len(msg) >= 56 {
+		challenge.Major = int(msg[48])
+		challenge.Minor = int(msg[49])
+		challenge.Build = int(binary.LittleEndian.Uint16(msg[50:52]))
+	}
+
+	return challenge, nil
+}
+
+func decodeUTF16LE(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[i*2:])
+	}
+	return string(utf16.Decode(units))
+}
+
+func windowsVersionHint(major, minor, build int) string {
+	switch {
+	case major == 10 && build >= 22000:
+		return "Windows 11 / Server 2022+"
+	case major == 10 && build >= 20348:
+		return "Windows Server 2022"
+	case major == 10:
+		return "Windows 10 / Server 2016-2019"
+	case major == 6 && minor == 3:
+		return "Windows 8.1 / Server 2012 R2"
+	case major == 6 && minor == 2:
+		return "Windows 8 / Server 2012"
+	case major == 6 && minor == 1:
+		return "Windows 7 / Server 2008 R2"
+	case major == 6 && minor == 0:
+		return "Windows Vista / Server 2008"
+	case major == 5 && minor == 2:
+		return "Windows Server 2003 / XP x64"
+	case major == 5 && minor == 1:
+		return "Windows XP"
+	}
+	return ""
+}
+
+func dialSMB(ctx context.Context, ip string, port int, timeout time.Duration) (net.Conn, error) {
+	conn, err := dialTCP(ctx, net.JoinHostPort(ip, fmt.Sprintf("%d", port)), timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(timeout * 10))
+	return conn, nil
+}
+
+func negotiateSMB2(ctx context.Context, ip string, port int, dialects []uint16, timeout time.Duration) (*smb2NegotiateResponse, net.Conn, error) {
+	conn, err := dialSMB(ctx, ip, port, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := writeSMBMessage(conn, buildSMB2NegotiateRequest(dialects)); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	message, err := readSMBMessage(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	response, err := parseSMB2NegotiateResponse(message)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return response, conn, nil
+}
+
+func probeSMB1(ctx context.Context, ip string, port int, timeout time.Duration) bool {
+	conn, err := dialSMB(ctx, ip, port, timeout)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	if err := writeSMBMessage(conn, buildSMB1NegotiateRequest()); err != nil {
+		return false
+	}
+	message, err := readSMBMessage(conn)
+	if err != nil || len(message) < 37 {
+		return false
+	}
+
+	return bytes.Equal(message[0:4], []byte("\xffSMB")) &&
+		binary.LittleEndian.Uint32(message[5:9]) == smbStatusSuccess &&
+		binary.LittleEndian.Uint16(message[33:35]) != 0xffff
+}
+
+func probeSMB(ctx context.Context, ip string, port int, timeout time.Duration) (*SMBInfo, error) {
+	info := &SMBInfo{}
+
+	for _, dialect := range smb2Dialects {
+		response, conn, err := negotiateSMB2(ctx, ip, port, []uint16{dialect.code}, timeout)
+		if err != nil {
+			continue
+		}
+		conn.Close()
+
+		if response.Dialect == dialect.code {
+			info.Dialects = append(info.Dialects, dialect.name)
+		}
+		info.SigningEnabled = info.SigningEnabled || response.SecurityMode&smb2SigningEnabled != 0
+		info.SigningRequired = info.SigningRequired || response.SecurityMode&smb2SigningRequired != 0
+	}
+
+	info.SMB1 = probeSMB1(ctx, ip, port, timeout)
+	if info.SMB1 {
+		info.Dialects = append([]string{"1.0"}, info.Dialects...)
+	}
+
+	if len(info.Dialects) == 0 {
+		return nil, fmt.Errorf("no SMB dialects negotiated on %s:%d", ip, port)
+	}
+
+	if challenge, err := requestNTLMChallenge(ctx, ip, port, timeout); err == nil {
+		info.NetBIOSComputer = challenge.NetBIOSComputer
+		info.NetBIOSDomain = challenge.NetBIOSDomain
+		info.DNSComputer = challenge.DNSComputer
+		info.DNSDomain = challenge.DNSDomain
+		info.DNSForest = challenge.DNSForest
+		if challenge.Major > 0 {
+			info.OSVersion = fmt.Sprintf("%d.%d.%d", challenge.Major, challenge.Minor, challenge.Build)
+			info.OSHint = windowsVersionHint(challenge.Major, challenge.Minor, challenge.Build)
+		}
+	}
+
+	return info, nil
+}
+
+func requestNTLMChallenge(ctx context.Context, ip string, port int, timeout time.Duration) (*ntlmChallenge, error) {
+	_, conn, err := negotiateSMB2(ctx, ip, port, []uint16{0x0202, 0x0210, 0x0300, 0x0302}, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	blob := buildSPNEGONegTokenInit(buildNTLMSSPNegotiate())
+	if err := writeSMBMessage(conn, buildSMB2SessionSetupRequest(1, blob)); err != nil {
+		return nil, err
+	}
+
+	message, err := readSMBMessage(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := parseSMB2Status(message)
+	if err != nil {
+		return nil, err
+	}
+	if status != smbStatusMoreProcessing {
+		return nil, fmt.Errorf("session setup returned status 0x%08x", status)
+	}
+
+	securityBuffer, err := parseSMB2SecurityBuffer(message)
+	if err != nil {
+		return nil, err
+	}
+	return parseNTLMChallenge(securityBuffer)
+}
+
+func (s *SMBInfo) Summary() string {
+	parts := []string{"SMB " + strings.Join(s.Dialects, ",")}
+	switch {
+	case s.SigningRequired:
+		parts = append(parts, "signing required")
+	case s.SigningEnabled:
+		parts = append(parts, "signing enabled (not required)")
+	default:
+		parts = append(parts, "signing disabled")
+	}
+	if s.NetBIOSDomain != "" {
+		parts = append(parts, "domain "+s.NetBIOSDomain)
+	}
+	if s.OSHint != "" {
+		parts = append(parts, fmt.Sprintf("%s (%s)", s.OSHint, s.OSVersion))
+	} else if s.OSVersion != "" {
+		parts = append(parts, "version "+s.OSVersion)
+	}
+	if s.SMB1 {
+		parts = append(parts, "⚠️ SMBv1 enabled")
+	}
+	return strings.Join(parts, " | ")
+}
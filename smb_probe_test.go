@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"slices"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+func encodeTestUTF16LE(s string) []byte {
+	var b []byte
+	for _, unit := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, unit)
+	}
+	return b
+}
+
+func buildTestNTLMChallenge(computer, domain string) []byte {
+	var info []byte
+	for _, pair := range []struct {
+		id    uint16
+		value string
+	}{
+		{ntlmAvNbDomainName, domain},
+		{ntlmAvNbComputerName, computer},
+		{ntlmAvDNSDomainName, "corp.example.com"},
+		{ntlmAvDNSComputerName, "filesrv01.corp.example.com"},
+		{ntlmAvDNSTreeName, "example.com"},
+	} {
+		value := encodeTestUTF16LE(pair.value)
+		info = binary.LittleEndian.AppendUint16(info, pair.id)
+		info = binary.LittleEndian.AppendUint16(info, uint16(len(value)))
+		info = append(info, value...)
+	}
+	info = append(info, 0, 0, 0, 0)
+
+	msg := make([]byte, 56)
+	copy(msg, ntlmsspSignature)
+	binary.LittleEndian.PutUint32(msg[8:12], 2)
+	binary.LittleEndian.PutUint32(msg[20:24], 0x02800000)
+	binary.LittleEndian.PutUint16(msg[40:42], uint16(len(info)))
+	binary.LittleEndian.PutUint32(msg[44:48], 56)
+	msg[48], msg[49] = 10, 0
+	binary.LittleEndian.PutUint16(msg[50:52], 20348)
+	msg[55] = 0x0f
+
+	return append(msg, info...)
+}
+
+func buildTestSMB2Response(command uint16, status uint32, body []byte) []byte {
+	header := buildSMB2Header(command, 0)
+	header[12] = byte(command)
+	binary.LittleEndian.PutUint32(header[8:12], status)
+	return append(header, body...)
+}
+
+func startTestSMBServer(t *testing.T, supported []uint16, securityMode uint16) int {
+	return startTestListener(t, func(conn net.Conn) {
+		conn.SetDeadline(time.Now().Add(2 * time.Second))
+		for {
+			message, err := readSMBMessage(conn)
+			if err != nil {
+				return
+			}
+
+			if message[0] == 0xff {
+				reply := make([]byte, 37)
+				copy(reply, "\xffSMB")
+				binary.LittleEndian.PutUint32(reply[5:9], 0xc0000002)
+				writeSMBMessage(conn, reply)
+				return
+			}
+
+			switch binary.LittleEndian.Uint16(message[12:14]) {
+			case smb2CommandNegotiate:
+				body := message[64:]
+				count := int(binary.LittleEndian.Uint16(body[2:4]))
+				chosen := uint16(0)
+				for i := range count {
+					dialect := binary.LittleEndian.Uint16(body[36+i*2:])
+					if slices.Contains(supported, dialect) {
+						chosen = dialect
+					}
+				}
+				if chosen == 0 {
+					writeSMBMessage(conn, buildTestSMB2Response(smb2CommandNegotiate, 0xc00000bb, make([]byte, 9)))
+					return
+				}
+				reply := make([]byte, 65)
+				binary.LittleEndian.PutUint16(reply[0:2], 65)
+				binary.LittleEndian.PutUint16(reply[2:4], securityMode)
+				binary.LittleEndian.PutUint16(reply[4:6], chosen)
+				writeSMBMessage(conn, buildTestSMB2Response(smb2CommandNegotiate, smbStatusSuccess, reply))
+			case smb2CommandSessionSetup:
+				challenge := buildTestNTLMChallenge("FILESRV01", "CORP")
+				reply := make([]byte, 8)
+				binary.LittleEndian.PutUint16(reply[0:2], 9)
+				binary.LittleEndian.PutUint16(reply[4:6], 64+8)
+				binary.LittleEndian.PutUint16(reply[6:8], uint16(len(challenge)))
+				writeSMBMessage(conn, buildTestSMB2Response(smb2CommandSessionSetup, smbStatusMoreProcessing, append(reply, challenge...)))
+				return
+			}
+		}
+	})
+}
+
+func TestParseNTLMChallenge(t *testing.T) {
+	blob := append([]byte{0xa1, 0x81, 0x00}, buildTestNTLMChallenge("FILESRV01", "CORP")...)
+
+	challenge, err := parseNTLMChallenge(blob)
+	if err != nil {
+		t.Fatalf("parseNTLMChallenge() error: %v", err)
+	}
+
+	if challenge.NetBIOSComputer != "FILESRV01" || challenge.NetBIOSDomain != "CORP" {
+		t.Errorf("NetBIOS names = %q / %q", challenge.NetBIOSComputer, challenge.NetBIOSDomain)
+	}
+	if challenge.DNSComputer != "filesrv01.corp.example.com" || challenge.DNSForest != "example.com" {
+		t.Errorf("DNS names = %q / %q", challenge.DNSComputer, challenge.DNSForest)
+	}
+	if challenge.Major != 10 || challenge.Build != 20348 {
+		t.Errorf("version = %d.%d.%d", challenge.Major, challenge.Minor, challenge.Build)
+	}
+
+	if _, err := parseNTLMChallenge([]byte("garbage")); err == nil {
+		t.Error("expected error without NTLMSSP signature")
+	}
+
+	injected, err := parseNTLMChallenge(buildTestNTLMChallenge("FILE\x1b[2JSRV\u009b", "CO\x1b]0;x\x07RP"))
+	if err != nil {
+		t.Fatalf("parseNTLMChallenge() error: %v", err)
+	}
+	if injected.NetBIOSComputer != "FILE[2JSRV" || injected.NetBIOSDomain != "CO]0;xRP" {
+		t.Errorf("control characters should be stripped, got %q / %q", injected.NetBIOSComputer, injected.NetBIOSDomain)
+	}
+}
+
+func TestWindowsVersionHint(t *testing.T) {
+	tests := []struct {
+		major, minor, build int
+		expected            string
+	}{
+		{10, 0, 22631, "Windows 11 / Server 2022+"},
+		{10, 0, 19045, "Windows 10 / Server 2016-2019"},
+		{6, 1, 7601, "Windows 7 / Server 2008 R2"},
+		{3, 0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		if hint := windowsVersionHint(tt.major, tt.minor, tt.build); hint != tt.expected {
+			t.Errorf("windowsVersionHint(%d, %d, %d) = %q, expected %q", tt.major, tt.minor, tt.build, hint, tt.expected)
+		}
+	}
+}
+
+func TestProbeSMB(t *testing.T) {
+	port := startTestSMBServer(t, []uint16{0x0210, 0x0300, 0x0311}, smb2SigningEnabled)
+
+	info, err := probeSMB(context.Background(), "127.0.0.1", port, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("probeSMB() error: %v", err)
+	}
+
+	if !slices.Equal(info.Dialects, []string{"2.1", "3.0", "3.1.1"}) {
+		t.Errorf("dialects = %v", info.Dialects)
+	}
+	if info.SMB1 {
+		t.Error("SMB1 should not be reported when the server rejects it")
+	}
+	if !info.SigningEnabled || info.SigningRequired {
+		t.Errorf("signing enabled=%v required=%v", info.SigningEnabled, info.SigningRequired)
+	}
+	if info.NetBIOSComputer != "FILESRV01" || info.NetBIOSDomain != "CORP" {
+		t.Errorf("names = %q / %q", info.NetBIOSComputer, info.NetBIOSDomain)
+	}
+	if info.OSVersion != "10.0.20348" || info.OSHint != "Windows Server 2022" {
+		t.Errorf("os = %q (%q)", info.OSVersion, info.OSHint)
+	}
+}
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berNull        = 0x05
+	berOID         = 0x06
+	berSequence    = 0x30
+	berIPAddress   = 0x40
+	berCounter32   = 0x41
+	berGauge32     = 0x42
+	berTimeTicks   = 0x43
+
+	snmpGetRequest     = 0xa0
+	snmpGetResponse    = 0xa2
+	snmpNoSuchObject   = 0x80
+	snmpNoSuchInstance = 0x81
+
+	snmpVersion1  = 0
+	snmpVersion2c = 1
+)
+
+const (
+	oidSysDescr    = "1.3.6.1.2.1.1.1.0"
+	oidSysObjectID = "1.3.6.1.2.1.1.2.0"
+	oidSysUpTime   = "1.3.6.1.2.1.1.3.0"
+	oidSysContact  = "1.3.6.1.2.1.1.4.0"
+	oidSysName     = "1.3.6.1.2.1.1.5.0"
+	oidSysLocation = "1.3.6.1.2.1.1.6.0"
+	oidEnterprises = "1.3.6.1.4.1."
+)
+
+var snmpCommunities = []string{"public"}
+
+var snmpSystemOIDs = []string{oidSysDescr, oidSysObjectID, oidSysUpTime, oidSysContact, oidSysName, oidSysLocation}
+
+var snmpEnterpriseVendors = map[int]string{
+	2:     "IBM",
+	9:     "Cisco",
+	11:    "Hewlett-Packard",
+	42:    "Sun Microsystems",
+	171:   "D-Link",
+	253:   "Xerox",
+	311:   "Microsoft",
+	367:   "Ricoh",
+	641:   "Lexmark",
+	674:   "Dell",
+	1248:  "Epson",
+	1588:  "Brocade",
+	1602:  "Canon",
+	1916:  "Extreme Networks",
+	2011:  "Huawei",
+	2021:  "UC Davis (net-snmp)",
+	2435:  "Brother",
+	2636:  "Juniper Networks",
+	3375:  "F5 Networks",
+	4413:  "Broadcom",
+	4526:  "Netgear",
+	6574:  "Synology",
+	6876:  "VMware",
+	8072:  "net-snmp",
+	11863: "TP-Link",
+	12356: "Fortinet",
+	14823: "Aruba Networks",
+	14988: "MikroTik",
+	18334: "Konica Minolta",
+	24681: "QNAP",
+	25461: "Palo Alto Networks",
+	25506: "H3C",
+	30065: "Arista Networks",
+	41112: "Ubiquiti",
+}
+
+type SNMPInfo struct {
+	Version          string
+	Community        string
+	SysDescr         string
+	SysObjectID      string
+	SysUpTime        time.Duration
+	SysContact       string
+	SysName          string
+	SysLocation      string
+	EnterpriseVendor string
+}
+
+type berValue struct {
+	Tag     byte
+	Content []byte
+}
+
+func berEncodeLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+
+	var encoded []byte
+	for length > 0 {
+		encoded = append([]byte{byte(length)}, encoded...)
+		length >>= 8
+	}
+	return append([]byte{0x80 | byte(len(encoded))}, encoded...)
+}
+
+func berEncode(tag byte, content []byte) []byte {
+	encoded := append([]byte{tag}, berEncodeLength(len(content))...)
+	return append(encoded, content...)
+}
+
+func berEncodeInteger(value int64) []byte {
+	var content []byte
+	for {
+		content = append([]byte{byte(value)}, content...)
+		if (value >= -128 && value < 128) || len(content) == 8 {
+			break
+		}
+		value >>= 8
+	}
+	return berEncode(berInteger, content)
+}
+
+func berEncodeOID(oid string) ([]byte, error) {
+	parts := strings.Split(strings.TrimPrefix(oid, "."), ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid OID %q", oid)
+	}
+
+	arcs := make([]uint64, len(parts))
+	for i, part := range parts {
+		arc, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q", oid)
+		}
+		arcs[i] = arc
+	}
+
+	var content []byte
+	for _, arc := range append([]uint64{arcs[0]*40 + arcs[1]}, arcs[2:]...) {
+		chunk := []byte{byte(arc & 0x7f)}
+		for arc >>= 7; arc > 0; arc >>= 7 {
+			chunk = append([]byte{byte(arc&0x7f) | 0x80}, chunk...)
+		}
+		content = append(content, chunk...)
+	}
+	return berEncode(berOID, content), nil
+}
+
+func berDecode(data []byte) (berValue, []byte, error) {
+	if len(data) < 2 {
+		return berValue{}, nil, fmt.Errorf("truncated BER value")
+	}
+
+	tag := data[0]
+	length := int(data[1])
+	offset := 2
+	if length&0x80 != 0 {
+		size := length & 0x7f
+		if size == 0 || size > 4 || offset+size > len(data) {
+			return berValue{}, nil, fmt.Errorf("invalid BER length")
+		}
+		length = 0
+		for _, b := range data[offset : offset+size] {
+			length = length<<8 | int(b)
+		}
+		offset += size
+	}
+
+	if length < 0 || offset+length > len(data) {
+		return berValue{}, nil, fmt.Errorf("truncated BER content")
+	}
+	return berValue{Tag: tag, Content: data[offset : offset+length]}, data[offset+length:], nil
+}
+
+func berDecodeSequence(data []byte) ([]berValue, error) {
+	var values []berValue
+	for len(data) > 0 {
+		value, rest, err := berDecode(data)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		data = rest
+	}
+	return values, nil
+}
+
+func berDecodeInteger(content []byte) int64 {
+	if len(content) == 0 {
+		return 0
+	}
+	value := int64(int8(content[0]))
+	for _, b := range content[1:] {
+		value = value<<8 | int64(b)
+	}
+	return value
+}
+
+func berDecodeUnsigned(content []byte) uint64 {
+	var value uint64
+	for _, b := range content {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func berDecodeOID(content []byte) string {
+	var subidentifiers []uint64
+	var arc uint64
+	for _, b := range content {
+		arc = arc<<7 | uint64(b&0x7f)
+		if b&0x80 == 0 {
+			subidentifiers = append(subidentifiers, arc)
+			arc = 0
+		}
+	}
+	if len(subidentifiers) == 0 {
+		return ""
+	}
+
+	first := min(subidentifiers[0]/40, 2)
+	arcs := []string{strconv.FormatUint(first, 10), strconv.FormatUint(subidentifiers[0]-first*40, 10)}
+	for _, sub := range subidentifiers[1:] {
+		arcs = append(arcs, strconv.FormatUint(sub, 10))
+	}
+	return strings.Join(arcs, ".")
+}
+
+func buildSNMPGetRequest(version int, community string, requestID int32, oids []string) ([]byte, error) {
+	var varbinds []byte
+	for _, oid := range oids {
+		encoded, err := berEncodeOID(oid)
+		if err != nil {
+			return nil, err
+		}
+		varbinds = append(varbinds, berEncode(berSequence, append(encoded, berNull, 0))...)
+	}
+
+	pdu := berEncodeInteger(int64(requestID))
+	pdu = append(pdu, berEncodeInteger(0)...)
+	pdu = append(pdu, berEncodeInteger(0)...)
+	pdu = append(pdu, berEncode(berSequence, varbinds)...)
+
+	message := berEncodeInteger(int64(version))
+	message = append(message, berEncode(berOctetString, []byte(community))...)
+	message = append(message, berEncode(snmpGetRequest, pdu)...)
+
+	return berEncode(berSequence, message), nil
+}
+
+func parseSNMPResponse(packet []byte, requestID int32) (map[string]berValue, error) {
+	message, _, err := berDecode(packet)
+	if err != nil {
+		return nil, err
+	}
+	if message.Tag != berSequence {
+		return nil, fmt.Errorf("SNMP message is not a sequence")
+	}
+
+	fields, err := berDecodeSequence(message.Content)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 3 || fields[2].Tag != snmpGetResponse {
+		return nil, fmt.Errorf("not an SNMP GetResponse")
+	}
+
+	pdu, err := berDecodeSequence(fields[2].Content)
+	if err != nil {
+		return nil, err
+	}
+	if len(pdu) != 4 {
+		return nil, fmt.Errorf("malformed SNMP PDU")
+	}
+	if int32(berDecodeInteger(pdu[0].Content)) != requestID {
+		return nil, fmt.Errorf("SNMP request ID mismatch")
+	}
+	if status := berDecodeInteger(pdu[1].Content); status != 0 {
+		return nil, fmt.Errorf("SNMP error status %d", status)
+	}
+
+	varbinds, err := berDecodeSequence(pdu[3].Content)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]berValue)
+	for _, varbind := range varbinds {
+		pair, err := berDecodeSequence(varbind.Content)
+		if err != nil || len(pair) != 2 || pair[0].Tag != berOID {
+			continue
+		}
+		if pair[1].Tag == berNull || pair[1].Tag == snmpNoSuchObject || pair[1].Tag == snmpNoSuchInstance {
+			continue
+		}
+		values[berDecodeOID(pair[0].Content)] = pair[1]
+	}
+	return values, nil
+}
+
+func snmpString(value berValue) string {
+	switch value.Tag {
+	case berOctetString:
+		text := strings.TrimRight(string(value.Content), "\x00")
+		if !utf8.ValidString(text) {
+			return fmt.Sprintf("%x", value.Content)
+		}
+		return sanitizeText(text, 0)
+	case berOID:
+		return berDecodeOID(value.Content)
+	case berInteger:
+		return strconv.FormatInt(berDecodeInteger(value.Content), 10)
+	case berCounter32, berGauge32, berTimeTicks:
+		return strconv.FormatUint(berDecodeUnsigned(value.Content), 10)
+	case berIPAddress:
+		if len(value.Content) == 4 {
+			return net.IP(value.Content).String()
+		}
+	}
+	return ""
+}
+
+func snmpEnterpriseVendor(objectID string) string {
+	rest, ok := strings.CutPrefix(objectID, oidEnterprises)
+	if !ok {
+		return ""
+	}
+	number, _, _ := strings.Cut(rest, ".")
+	enterprise, err := strconv.Atoi(number)
+	if err != nil {
+		return ""
+	}
+	return snmpEnterpriseVendors[enterprise]
+}
+
+func probeSNMP(ctx context.Context, ip string, timeout time.Duration) (*SNMPInfo, error) {
+	return querySNMPAddr(ctx, net.JoinHostPort(ip, "161"), snmpCommunities, timeout)
+}
+
+func querySNMPAddr(ctx context.Context, address string, communities []string, timeout time.Duration) (*SNMPInfo, error) {
+	d := newDialer("udp", timeout)
+	conn, err := d.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	buffer := make([]byte, 65535)
+	for _, community := range communities {
+		for _, version := range []int{snmpVersion2c, snmpVersion1} {
+			requestID := rand.Int32()
+			request, err := buildSNMPGetRequest(version, community, requestID, snmpSystemOIDs)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := conn.Write(request); err != nil {
+				return nil, err
+			}
+
+			conn.SetReadDeadline(time.Now().Add(timeout))
+			n, err := conn.Read(buffer)
+			if err != nil {
+				continue
+			}
+
+			values, err := parseSNMPResponse(buffer[:n], requestID)
+			if err != nil || len(values) == 0 {
+				continue
+			}
+			return newSNMPInfo(version, community, values), nil
+		}
+	}
+
+	return nil, fmt.Errorf("no SNMP response from %s", address)
+}
+
+func newSNMPInfo(version int, community string, values map[string]berValue) *SNMPInfo {
+	info := &SNMPInfo{
+		Version:     "v1",
+		Community:   community,
+		SysDescr:    snmpString(values[oidSysDescr]),
+		SysObjectID: snmpString(values[oidSysObjectID]),
+		SysContact:  snmpString(values[oidSysContact]),
+		SysName:     snmpString(values[oidSysName]),
+		SysLocation: snmpString(values[oidSysLocation]),
+	}
+	if version == snmpVersion2c {
+		info.Version = "v2c"
+	}
+	if ticks, ok := values[oidSysUpTime]; ok && ticks.Tag == berTimeTicks {
+		info.SysUpTime = time.Duration(berDecodeUnsigned(ticks.Content)) * 10 * time.Millisecond
+	}
+	info.EnterpriseVendor = snmpEnterpriseVendor(info.SysObjectID)
+	return info
+}
+
+func (s *SNMPInfo) Summary() string {
+	parts := []string{fmt.Sprintf("SNMP %s (%s)", s.Version, s.Community)}
+	if s.SysName != "" {
+		parts = append(parts, s.SysName)
+	}
+	if s.SysDescr != "" {
+		parts = append(parts, sanitizeText(s.SysDescr, 80))
+	}
+	if s.SysLocation != "" {
+		parts = append(parts, "location "+s.SysLocation)
+	}
+	if s.SysContact != "" {
+		parts = append(parts, "contact "+s.SysContact)
+	}
+	if s.SysUpTime > 0 {
+		parts = append(parts, "up "+s.SysUpTime.Truncate(time.Second).String())
+	}
+	return strings.Join(parts, " | ")
+}
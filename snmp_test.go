@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestBEREncodeInteger(t *testing.T) {
+	tests := []struct {
+		value    int64
+		expected []byte
+	}{
+		{0, []byte{0x02, 0x01, 0x00}},
+		{127, []byte{0x02, 0x01, 0x7f}},
+		{128, []byte{0x02, 0x02, 0x00, 0x80}},
+		{-1, []byte{0x02, 0x01, 0xff}},
+		{-129, []byte{0x02, 0x02, 0xff, 0x7f}},
+		{65536, []byte{0x02, 0x03, 0x01, 0x00, 0x00}},
+	}
+
+	for _, tt := range tests {
+		encoded := berEncodeInteger(tt.value)
+		if !bytes.Equal(encoded, tt.expected) {
+			t.Errorf("berEncodeInteger(%d) = % x, expected % x", tt.value, encoded, tt.expected)
+		}
+		value, _, err := berDecode(encoded)
+		if err != nil || berDecodeInteger(value.Content) != tt.value {
+			t.Errorf("round trip of %d failed: %v", tt.value, err)
+		}
+	}
+}
+
+func TestBEROIDRoundTrip(t *testing.T) {
+	for _, oid := range []string{oidSysDescr, "1.3.6.1.4.1.41112.1.6", "1.3.6.1.4.1.9.1.2066", "2.999.3"} {
+		encoded, err := berEncodeOID(oid)
+		if err != nil {
+			t.Fatalf("berEncodeOID(%s) error: %v", oid, err)
+		}
+		value, rest, err := berDecode(encoded)
+		if err != nil || len(rest) != 0 || value.Tag != berOID {
+			t.Fatalf("berDecode(%s) failed: %v", oid, err)
+		}
+		if decoded := berDecodeOID(value.Content); decoded != oid {
+			t.Errorf("round trip = %s, expected %s", decoded, oid)
+		}
+	}
+
+	if _, err := berEncodeOID("1.3.x"); err == nil {
+		t.Error("expected error for invalid OID")
+	}
+}
+
+func TestBERLongFormLength(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 300)
+	encoded := berEncode(berOctetString, content)
+	if !bytes.Equal(encoded[:4], []byte{0x04, 0x82, 0x01, 0x2c}) {
+		t.Errorf("header = % x", encoded[:4])
+	}
+
+	value, _, err := berDecode(encoded)
+	if err != nil || len(value.Content) != 300 {
+		t.Errorf("decode failed: %v", err)
+	}
+	if _, _, err := berDecode(encoded[:100]); err == nil {
+		t.Error("expected error for truncated content")
+	}
+}
+
+func TestSNMPEnterpriseVendor(t *testing.T) {
+	tests := map[string]string{
+		"1.3.6.1.4.1.9.1.2066":  "Cisco",
+		"1.3.6.1.4.1.41112.1.6": "Ubiquiti",
+		"1.3.6.1.4.1.99999.1":   "",
+		"1.3.6.1.2.1.1":         "",
+	}
+	for oid, expected := range tests {
+		if vendor := snmpEnterpriseVendor(oid); vendor != expected {
+			t.Errorf("snmpEnterpriseVendor(%s) = %q, expected %q", oid, vendor, expected)
+		}
+	}
+}
+
+func buildTestSNMPResponse(request []byte) []byte {
+	message, _, _ := berDecode(request)
+	fields, _ := berDecodeSequence(message.Content)
+	pdu, _ := berDecodeSequence(fields[2].Content)
+
+	values := map[string][]byte{
+		oidSysDescr:    berEncode(berOctetString, []byte("Linux UAP-AC-Pro 4.4.153 #1 SMP")),
+		oidSysUpTime:   berEncode(berTimeTicks, []byte{0x01, 0x5f, 0x90}),
+		oidSysContact:  berEncode(berOctetString, []byte("noc@example.com")),
+		oidSysName:     berEncode(berOctetString, []byte("ap-office")),
+		oidSysLocation: berEncode(berOctetString, []byte("2nd floor")),
+	}
+	objectID, _ := berEncodeOID("1.3.6.1.4.1.41112.1.6")
+	values[oidSysObjectID] = objectID
+
+	requested, _ := berDecodeSequence(pdu[3].Content)
+	var varbinds []byte
+	for _, varbind := range requested {
+		pair, _ := berDecodeSequence(varbind.Content)
+		oid := berDecodeOID(pair[0].Content)
+		encodedOID, _ := berEncodeOID(oid)
+		varbinds = append(varbinds, berEncode(berSequence, append(encodedOID, values[oid]...))...)
+	}
+
+	body := berEncode(berInteger, pdu[0].Content)
+	body = append(body, berEncodeInteger(0)...)
+	body = append(body, berEncodeInteger(0)...)
+	body = append(body, berEncode(berSequence, varbinds)...)
+
+	response := berEncode(berInteger, fields[0].Content)
+	response = append(response, berEncode(berOctetString, fields[1].Content)...)
+	response = append(response, berEncode(snmpGetResponse, body)...)
+	return berEncode(berSequence, response)
+}
+
+func TestQuerySNMP(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		buffer := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			message, _, err := berDecode(buffer[:n])
+			if err != nil {
+				continue
+			}
+			fields, _ := berDecodeSequence(message.Content)
+			if len(fields) != 3 || string(fields[1].Content) != "private" {
+				continue
+			}
+			conn.WriteTo(buildTestSNMPResponse(buffer[:n]), addr)
+		}
+	}()
+
+	info, err := querySNMPAddr(context.Background(), conn.LocalAddr().String(), []string{"public", "private"}, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("querySNMPAddr() error: %v", err)
+	}
+
+	if info.Community != "private" || info.Version != "v2c" {
+		t.Errorf("community/version = %s/%s", info.Community, info.Version)
+	}
+	if info.SysName != "ap-office" || info.SysLocation != "2nd floor" || info.SysContact != "noc@example.com" {
+		t.Errorf("system info = %+v", info)
+	}
+	if info.SysObjectID != "1.3.6.1.4.1.41112.1.6" || info.EnterpriseVendor != "Ubiquiti" {
+		t.Errorf("object ID = %s (%s)", info.SysObjectID, info.EnterpriseVendor)
+	}
+	if info.SysUpTime != 900*time.Second {
+		t.Errorf("uptime = %v", info.SysUpTime)
+	}
+}
+
+func TestSNMPStringStripsControlCharacters(t *testing.T) {
+	value := berValue{Tag: berOctetString, Content: []byte("Linux\x1b]0;pwned\x07 router\r\n\x00")}
+	if got := snmpString(value); got != "Linux]0;pwned router" {
+		t.Errorf("snmpString() = %q", got)
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ssdpSearchTarget   = "ssdp:all"
+	ssdpDescriptionMax = 512 * 1024
+	ssdpFetchTimeout   = 3 * time.Second
+)
+
+var (
+	ssdpGroupAddr   = &net.UDPAddr{IP: net.IPv4(239, 255, 255, 250), Port: 1900}
+	ssdpSearchRuns  = []time.Duration{0, time.Second}
+	upnpWANServices = []string{"WANIPConnection", "WANPPPConnection"}
+)
+
+type UPnPInfo struct {
+	FriendlyName string
+	Manufacturer string
+	ModelName    string
+	ModelNumber  string
+	SerialNumber string
+	DeviceType   string
+	Server       string
+	Location     string
+	Services     []string
+	WANExposed   bool
+}
+
+type ssdpResponse struct {
+	Location string
+	Server   string
+	ST       string
+	USN      string
+}
+
+type upnpDevice struct {
+	DeviceType   string        `xml:"deviceType"`
+	FriendlyName string        `xml:"friendlyName"`
+	Manufacturer string        `xml:"manufacturer"`
+	ModelName    string        `xml:"modelName"`
+	ModelNumber  string        `xml:"modelNumber"`
+	SerialNumber string        `xml:"serialNumber"`
+	Services     []upnpService `xml:"serviceList>service"`
+	Devices      []upnpDevice  `xml:"deviceList>device"`
+}
+
+type upnpService struct {
+	ServiceType string `xml:"serviceType"`
+}
+
+type upnpDescription struct {
+	Device upnpDevice `xml:"device"`
+}
+
+type SSDPDiscovery struct {
+	mu        sync.Mutex
+	conn      *net.UDPConn
+	client    *http.Client
+	locations map[string]bool
+	devices   map[string]*UPnPInfo
+	done      chan struct{}
+	wg        sync.WaitGroup
+	fetches   sync.WaitGroup
+}
+
+func newSSDPDiscovery() *SSDPDiscovery {
+	return &SSDPDiscovery{
+		client:    &http.Client{Timeout: ssdpFetchTimeout},
+		locations: make(map[string]bool),
+		devices:   make(map[string]*UPnPInfo),
+		done:      make(chan struct{}),
+	}
+}
+
+func StartSSDPDiscovery() (*SSDPDiscovery, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: scanSourceIP()})
+	if err != nil {
+		return nil, err
+	}
+
+	d := newSSDPDiscovery()
+	d.conn = conn
+
+	d.wg.Add(2)
+	go d.receiveLoop()
+	go d.searchLoop()
+
+	return d, nil
+}
+
+func (d *SSDPDiscovery) Close() {
+	close(d.done)
+	d.conn.Close()
+	d.wg.Wait()
+	d.fetches.Wait()
+}
+
+func buildSSDPSearch(target string, mx int) []byte {
+	return []byte(fmt.Sprintf("M-SEARCH * HTTP/1.1\r\n"+
+		"HOST: 239.255.255.250:1900\r\n"+
+		"MAN: \"ssdp:discover\"\r\n"+
+		"MX: %d\r\n"+
+		"ST: %s\r\n"+
+		"USER-AGENT: viewnet UPnP/1.1\r\n\r\n", mx, target))
+}
+
+func (d *SSDPDiscovery) searchLoop() {
+	defer d.wg.Done()
+
+	start := time.Now()
+	for _, at := range ssdpSearchRuns {
+		select {
+		case <-d.done:
+			return
+		case <-time.After(time.Until(start.Add(at))):
+		}
+		d.conn.WriteToUDP(buildSSDPSearch(ssdpSearchTarget, 2), ssdpGroupAddr)
+	}
+}
+
+func (d *SSDPDiscovery) receiveLoop() {
+	defer d.wg.Done()
+
+	buffer := make([]byte, 4096)
+	for {
+		n, addr, err := d.conn.ReadFromUDP(buffer)
+		if err != nil {
+			return
+		}
+
+		response, err := parseSSDPResponse(buffer[:n])
+		if err != nil {
+			continue
+		}
+		d.handleResponse(response, addr.IP.String())
+	}
+}
+
+func parseSSDPResponse(packet []byte) (*ssdpResponse, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(packet)), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("SSDP response status %d", resp.StatusCode)
+	}
+
+	response := &ssdpResponse{
+		Location: strings.TrimSpace(resp.Header.Get("Location")),
+		Server:   sanitizeText(resp.Header.Get("Server"), 100),
+		ST:       resp.Header.Get("St"),
+		USN:      resp.Header.Get("Usn"),
+	}
+	if response.Location == "" {
+		return nil, fmt.Errorf("SSDP response without LOCATION")
+	}
+	return response, nil
+}
+
+func (d *SSDPDiscovery) handleResponse(response *ssdpResponse, source string) {
+	location, err := url.Parse(response.Location)
+	if err != nil || (location.Scheme != "http" && location.Scheme != "https") {
+		return
+	}
+
+	host := location.Hostname()
+	if net.ParseIP(host) == nil {
+		host = source
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.locations[response.Location] {
+		return
+	}
+	d.locations[response.Location] = true
+
+	d.fetches.Add(1)
+	go func() {
+		defer d.fetches.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), ssdpFetchTimeout)
+		defer cancel()
+
+		info, err := fetchUPnPDescription(ctx, d.client, response.Location)
+		if err != nil {
+			return
+		}
+		info.Server = response.Server
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if existing, ok := d.devices[host]; ok {
+			mergeUPnPInfo(existing, info)
+		} else {
+			d.devices[host] = info
+		}
+	}()
+}
+
+func fetchUPnPDescription(ctx context.Context, client *http.Client, location string) (*UPnPInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "viewnet")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("description returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, ssdpDescriptionMax))
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := parseUPnPDescription(body)
+	if err != nil {
+		return nil, err
+	}
+	info.Location = location
+	return info, nil
+}
+
+func parseUPnPDescription(data []byte) (*UPnPInfo, error) {
+	var description upnpDescription
+	if err := xml.Unmarshal(data, &description); err != nil {
+		return nil, err
+	}
+
+	device := description.Device
+	if device.DeviceType == "" && device.FriendlyName == "" {
+		return nil, fmt.Errorf("description has no root device")
+	}
+
+	info := &UPnPInfo{
+		FriendlyName: sanitizeText(device.FriendlyName, 100),
+		Manufacturer: sanitizeText(device.Manufacturer, 100),
+		ModelName:    sanitizeText(device.ModelName, 100),
+		ModelNumber:  sanitizeText(device.ModelNumber, 100),
+		SerialNumber: sanitizeText(device.SerialNumber, 100),
+		DeviceType:   upnpShortType(device.DeviceType),
+	}
+	collectUPnPServices(&device, info)
+	return info, nil
+}
+
+func collectUPnPServices(device *upnpDevice, info *UPnPInfo) {
+	for _, service := range device.Services {
+		name := upnpShortType(service.ServiceType)
+		if name == "" || slices.Contains(info.Services, name) {
+			continue
+		}
+		info.Services = append(info.Services, name)
+		for _, wan := range upnpWANServices {
+			if strings.HasPrefix(name, wan) {
+				info.WANExposed = true
+			}
+		}
+	}
+	for i := range device.Devices {
+		collectUPnPServices(&device.Devices[i], info)
+	}
+}
+
+func upnpShortType(urn string) string {
+	urn = sanitizeText(urn, 0)
+	parts := strings.Split(urn, ":")
+	if len(parts) >= 5 && parts[0] == "urn" {
+		return parts[3] + ":" + parts[4]
+	}
+	return urn
+}
+
+func mergeUPnPInfo(existing, other *UPnPInfo) {
+	for _, service := range other.Services {
+		if !slices.Contains(existing.Services, service) {
+			existing.Services = append(existing.Services, service)
+		}
+	}
+	existing.WANExposed = existing.WANExposed || other.WANExposed
+	if existing.FriendlyName == "" {
+		existing.FriendlyName = other.FriendlyName
+	}
+}
+
+func (d *SSDPDiscovery) Lookup(ip string) *UPnPInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	info, ok := d.devices[ip]
+	if !ok {
+		return nil
+	}
+	copied := *info
+	copied.Services = slices.Clone(info.Services)
+	return &copied
+}
+
+func (d *SSDPDiscovery) Apply(host *HostInfo) {
+	if info := d.Lookup(host.IP); info != nil {
+		host.UPnP = info
+	}
+}
+
+func (u *UPnPInfo) Summary() string {
+	var parts []string
+	if u.FriendlyName != "" {
+		parts = append(parts, fmt.Sprintf("%q", u.FriendlyName))
+	}
+	model := strings.TrimSpace(strings.Join([]string{u.Manufacturer, u.ModelName, u.ModelNumber}, " "))
+	if model != "" {
+		parts = append(parts, model)
+	}
+	if u.DeviceType != "" {
+		parts = append(parts, u.DeviceType)
+	}
+	if u.SerialNumber != "" {
+		parts = append(parts, "S/N "+u.SerialNumber)
+	}
+	if u.WANExposed {
+		parts = append(parts, "⚠️ WAN port mapping exposed")
+	}
+	return "UPnP " + strings.Join(parts, " | ")
+}
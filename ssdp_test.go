@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestParseSSDPResponse(t *testing.T) {
+	packet := []byte("HTTP/1.1 200 OK\r\n" +
+		"CACHE-CONTROL: max-age=1800\r\n" +
+		"EXT:\r\n" +
+		"LOCATION: http://192.168.178.1:49000/igddesc.xml\r\n" +
+		"SERVER: FRITZ!Box 7590 UPnP/1.0 AVM FRITZ!Box 7590 154.07.57\r\n" +
+		"ST: upnp:rootdevice\r\n" +
+		"USN: uuid:75802409-bccb-40e7-8e6c-3ca62f001122::upnp:rootdevice\r\n\r\n")
+
+	response, err := parseSSDPResponse(packet)
+	if err != nil {
+		t.Fatalf("parseSSDPResponse() error: %v", err)
+	}
+	if response.Location != "http://192.168.178.1:49000/igddesc.xml" || response.ST != "upnp:rootdevice" {
+		t.Errorf("response = %+v", response)
+	}
+
+	injected := []byte("HTTP/1.1 200 OK\r\nLOCATION: http://192.168.178.1/d.xml\r\nSERVER: Linux\u009b2J\r\n\r\n")
+	if response, err := parseSSDPResponse(injected); err != nil || response.Server != "Linux2J" {
+		t.Errorf("control characters should be stripped from the Server header, got %+v (%v)", response, err)
+	}
+
+	if _, err := parseSSDPResponse(buildSSDPSearch(ssdpSearchTarget, 2)); err == nil {
+		t.Error("M-SEARCH request should not parse as a response")
+	}
+}
+
+func TestParseUPnPDescription(t *testing.T) {
+	data, err := os.ReadFile("testdata/upnp_router.xml")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	info, err := parseUPnPDescription(data)
+	if err != nil {
+		t.Fatalf("parseUPnPDescription() error: %v", err)
+	}
+
+	if info.FriendlyName != "FRITZ!Box 7590" || info.Manufacturer != "AVM Berlin" || info.ModelNumber != "avm" {
+		t.Errorf("device = %+v", info)
+	}
+	if info.SerialNumber != "3C:A6:2F:00:11:22" || info.DeviceType != "InternetGatewayDevice:1" {
+		t.Errorf("serial/type = %q / %q", info.SerialNumber, info.DeviceType)
+	}
+	if !slices.Contains(info.Services, "WANIPConnection:1") {
+		t.Errorf("nested services not collected: %v", info.Services)
+	}
+	if !info.WANExposed {
+		t.Error("WANIPConnection should be flagged")
+	}
+
+	injected, err := parseUPnPDescription([]byte("<root><device><deviceType>urn:x:device:Cam&#x9b;2J:1</deviceType>" +
+		"<friendlyName>Cam&#x9b;2J</friendlyName></device></root>"))
+	if err != nil || injected.FriendlyName != "Cam2J" || injected.DeviceType != "Cam2J:1" {
+		t.Errorf("control characters should be stripped from descriptions, got %+v (%v)", injected, err)
+	}
+
+	if _, err := parseUPnPDescription([]byte("<html></html>")); err == nil {
+		t.Error("expected error for non-UPnP document")
+	}
+}
+
+func TestSSDPDiscoveryFetchesDescription(t *testing.T) {
+	data, _ := os.ReadFile("testdata/upnp_router.xml")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	d := newSSDPDiscovery()
+	response := &ssdpResponse{Location: server.URL + "/igddesc.xml", Server: "Linux UPnP/1.0"}
+	d.handleResponse(response, "10.0.0.1")
+	d.handleResponse(response, "10.0.0.1")
+	d.fetches.Wait()
+
+	host := &HostInfo{IP: "127.0.0.1", IsReachable: true}
+	d.Apply(host)
+	if host.UPnP == nil {
+		t.Fatal("expected UPnP info for the LOCATION host")
+	}
+	if host.UPnP.Server != "Linux UPnP/1.0" || !host.UPnP.WANExposed {
+		t.Errorf("UPnP = %+v", host.UPnP)
+	}
+	if !matchesSearch(host, "fritz") {
+		t.Error("UPnP friendly name should be searchable")
+	}
+}
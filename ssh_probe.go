@@ -0,0 +1,566 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sshMsgDisconnect   = 1
+	sshMsgIgnore       = 2
+	sshMsgDebug        = 4
+	sshMsgKexInit      = 20
+	sshMsgKexECDHInit  = 30
+	sshMsgKexECDHReply = 31
+	sshMaxPacketSize   = 256 * 1024
+	sshClientIdent     = "SSH-2.0-viewnet"
+)
+
+var sshHostKeyFile = defaultSSHHostKeyPath()
+var sshHostKeyStore map[string]map[string]string
+var sshHostKeyMutex sync.Mutex
+var sshHostKeyErr error
+var sshAcceptHostKeys bool
+
+var sshWeakAlgorithms = map[string]bool{
+	"diffie-hellman-group1-sha1":            true,
+	"diffie-hellman-group14-sha1":           true,
+	"diffie-hellman-group-exchange-sha1":    true,
+	"gss-gex-sha1-toWM5Slw5Ew8Mqkay+al2g==": true,
+	"rsa1024-sha1":                          true,
+	"ssh-dss":                               true,
+	"ssh-dss-cert-v01@openssh.com":          true,
+	"ssh-rsa1":                              true,
+	"3des-cbc":                              true,
+	"des-cbc":                               true,
+	"blowfish-cbc":                          true,
+	"cast128-cbc":                           true,
+	"arcfour":                               true,
+	"arcfour128":                            true,
+	"arcfour256":                            true,
+	"aes128-cbc":                            true,
+	"aes192-cbc":                            true,
+	"aes256-cbc":                            true,
+	"rijndael-cbc@lysator.liu.se":           true,
+	"hmac-md5":                              true,
+	"hmac-md5-96":                           true,
+	"hmac-md5-etm@openssh.com":              true,
+	"hmac-md5-96-etm@openssh.com":           true,
+	"hmac-sha1-96":                          true,
+	"hmac-sha1-96-etm@openssh.com":          true,
+	"hmac-ripemd160":                        true,
+	"none":                                  true,
+}
+
+var sshKexPreference = []string{
+	"curve25519-sha256",
+	"curve25519-sha256@libssh.org",
+	"ecdh-sha2-nistp256",
+	"ecdh-sha2-nistp384",
+	"ecdh-sha2-nistp521",
+}
+
+var sshHostKeyPreference = []string{
+	"ssh-ed25519",
+	"ecdsa-sha2-nistp256",
+	"ecdsa-sha2-nistp384",
+	"ecdsa-sha2-nistp521",
+	"rsa-sha2-512",
+	"rsa-sha2-256",
+	"ssh-rsa",
+	"ssh-dss",
+}
+
+type SSHHostKey struct {
+	Type        string
+	Fingerprint string
+}
+
+type SSHInfo struct {
+	ProtocolVersion   string
+	SoftwareVersion   string
+	Comments          string
+	KexAlgorithms     []string
+	HostKeyAlgorithms []string
+	Ciphers           []string
+	MACs              []string
+	Compression       []string
+	HostKeys          []SSHHostKey
+	WeakAlgorithms    []string
+	KeyChanged        bool
+	ChangedKeys       []string
+}
+
+type sshKexInit struct {
+	Kex         []string
+	HostKey     []string
+	CiphersC2S  []string
+	CiphersS2C  []string
+	MACsC2S     []string
+	MACsS2C     []string
+	CompC2S     []string
+	CompS2C     []string
+	LangC2S     []string
+	LangS2C     []string
+	FirstFollow bool
+}
+
+type sshConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	ident  string
+}
+
+func parseSSHIdentification(line string) (protocol, software, comments string, ok bool) {
+	line = strings.TrimRight(line, "\r\n")
+	rest, found := strings.CutPrefix(line, "SSH-")
+	if !found {
+		return "", "", "", false
+	}
+
+	protocol, rest, found = strings.Cut(rest, "-")
+	if !found || protocol == "" {
+		return "", "", "", false
+	}
+
+	software, comments, _ = strings.Cut(rest, " ")
+	protocol, software, comments = sanitizeText(protocol, 20), sanitizeText(software, 100), sanitizeText(comments, 100)
+	return protocol, software, comments, protocol != "" && software != ""
+}
+
+func sshSoftwareVersion(software string) string {
+	return strings.Replace(software, "_", " ", 1)
+}
+
+func dialSSH(ctx context.Context, ip string, port int, timeout time.Duration) (*sshConn, error) {
+	conn, err := dialTCP(ctx, net.JoinHostPort(ip, fmt.Sprintf("%d", port)), timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(timeout * 10))
+
+	c := &sshConn{conn: conn, reader: bufio.NewReader(conn)}
+	if _, err := conn.Write([]byte(sshClientIdent + "\r\n")); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	for range 20 {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to read SSH identification: %v", err)
+		}
+		if strings.HasPrefix(line, "SSH-") {
+			c.ident = strings.TrimRight(line, "\r\n")
+			return c, nil
+		}
+	}
+
+	conn.Close()
+	return nil, fmt.Errorf("no SSH identification received")
+}
+
+func (c *sshConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *sshConn) readPacket() ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[:4])
+	padding := uint32(header[4])
+	if length < padding+1 || length > sshMaxPacketSize {
+		return nil, fmt.Errorf("invalid SSH packet length %d", length)
+	}
+
+	body := make([]byte, length-1)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return nil, err
+	}
+
+	return body[:len(body)-int(padding)], nil
+}
+
+func (c *sshConn) readMessage(expected byte) ([]byte, error) {
+	for {
+		payload, err := c.readPacket()
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) == 0 {
+			continue
+		}
+
+		switch payload[0] {
+		case expected:
+			return payload, nil
+		case sshMsgIgnore, sshMsgDebug:
+			continue
+		case sshMsgDisconnect:
+			return nil, fmt.Errorf("server disconnected during key exchange")
+		default:
+			return nil, fmt.Errorf("unexpected SSH message %d", payload[0])
+		}
+	}
+}
+
+func (c *sshConn) writePacket(payload []byte) error {
+	padding := 8 - (len(payload)+5)%8
+	if padding < 4 {
+		padding += 8
+	}
+
+	packet := make([]byte, 5+len(payload)+padding)
+	binary.BigEndian.PutUint32(packet[:4], uint32(1+len(payload)+padding))
+	packet[4] = byte(padding)
+	copy(packet[5:], payload)
+	rand.Read(packet[5+len(payload):])
+
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+func parseKexInit(payload []byte) (*sshKexInit, error) {
+	if len(payload) < 17 || payload[0] != sshMsgKexInit {
+		return nil, fmt.Errorf("not a KEXINIT message")
+	}
+
+	data := payload[17:]
+	lists := make([][]string, 10)
+	for i := range lists {
+		value, rest, err := sshReadString(data)
+		if err != nil {
+			return nil, fmt.Errorf("truncated KEXINIT name-list %d", i)
+		}
+		if len(value) > 0 {
+			lists[i] = strings.Split(string(value), ",")
+		}
+		data = rest
+	}
+
+	kex := &sshKexInit{
+		Kex:        lists[0],
+		HostKey:    lists[1],
+		CiphersC2S: lists[2],
+		CiphersS2C: lists[3],
+		MACsC2S:    lists[4],
+		MACsS2C:    lists[5],
+		CompC2S:    lists[6],
+		CompS2C:    lists[7],
+		LangC2S:    lists[8],
+		LangS2C:    lists[9],
+	}
+	if len(data) > 0 {
+		kex.FirstFollow = data[0] != 0
+	}
+
+	return kex, nil
+}
+
+func buildKexInit(kex, hostKey string, server *sshKexInit) []byte {
+	payload := []byte{sshMsgKexInit}
+	cookie := make([]byte, 16)
+	rand.Read(cookie)
+	payload = append(payload, cookie...)
+
+	lists := [][]string{
+		{kex}, {hostKey},
+		server.CiphersC2S, server.CiphersS2C,
+		server.MACsC2S, server.MACsS2C,
+		server.CompC2S, server.CompS2C,
+		nil, nil,
+	}
+	for _, list := range lists {
+		payload = sshAppendString(payload, []byte(strings.Join(list, ",")))
+	}
+
+	return append(payload, 0, 0, 0, 0, 0)
+}
+
+func sshReadString(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	length := binary.BigEndian.Uint32(data[:4])
+	if uint32(len(data)-4) < length {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	return data[4 : 4+length], data[4+length:], nil
+}
+
+func sshAppendString(b, value []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(value)))
+	return append(b, value...)
+}
+
+func sshECDHCurve(kex string) ecdh.Curve {
+	switch kex {
+	case "curve25519-sha256", "curve25519-sha256@libssh.org":
+		return ecdh.X25519()
+	case "ecdh-sha2-nistp256":
+		return ecdh.P256()
+	case "ecdh-sha2-nistp384":
+		return ecdh.P384()
+	case "ecdh-sha2-nistp521":
+		return ecdh.P521()
+	}
+	return nil
+}
+
+func sshHostKeyType(algorithm string) string {
+	switch algorithm {
+	case "rsa-sha2-256", "rsa-sha2-512":
+		return "ssh-rsa"
+	}
+	return algorithm
+}
+
+func sshFingerprint(blob []byte) string {
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+func probeSSH(ctx context.Context, ip string, port int, timeout time.Duration) (*SSHInfo, error) {
+	conn, err := dialSSH(ctx, ip, port, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &SSHInfo{}
+	info.ProtocolVersion, info.SoftwareVersion, info.Comments, _ = parseSSHIdentification(conn.ident)
+
+	payload, err := conn.readMessage(sshMsgKexInit)
+	conn.Close()
+	if err != nil {
+		return info, nil
+	}
+
+	server, err := parseKexInit(payload)
+	if err != nil {
+		return info, nil
+	}
+
+	info.KexAlgorithms = server.Kex
+	info.HostKeyAlgorithms = server.HostKey
+	info.Ciphers = server.CiphersC2S
+	info.MACs = server.MACsC2S
+	info.Compression = server.CompC2S
+	info.WeakAlgorithms = findWeakSSHAlgorithms(server)
+
+	kex := ""
+	for _, candidate := range sshKexPreference {
+		if slices.Contains(server.Kex, candidate) {
+			kex = candidate
+			break
+		}
+	}
+	if kex == "" {
+		return info, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, algorithm := range sshHostKeyPreference {
+		keyType := sshHostKeyType(algorithm)
+		if seen[keyType] || !slices.Contains(server.HostKey, algorithm) {
+			continue
+		}
+
+		blob, err := fetchSSHHostKey(ctx, ip, port, kex, algorithm, timeout)
+		if err != nil {
+			continue
+		}
+		seen[keyType] = true
+
+		if blobType, _, err := sshReadString(blob); err == nil {
+			keyType = string(blobType)
+		}
+		info.HostKeys = append(info.HostKeys, SSHHostKey{Type: keyType, Fingerprint: sshFingerprint(blob)})
+	}
+
+	return info, nil
+}
+
+func fetchSSHHostKey(ctx context.Context, ip string, port int, kex, hostKeyAlgorithm string, timeout time.Duration) ([]byte, error) {
+	curve := sshECDHCurve(kex)
+	if curve == nil {
+		return nil, fmt.Errorf("unsupported key exchange %s", kex)
+	}
+
+	conn, err := dialSSH(ctx, ip, port, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	payload, err := conn.readMessage(sshMsgKexInit)
+	if err != nil {
+		return nil, err
+	}
+	server, err := parseKexInit(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.writePacket(buildKexInit(kex, hostKeyAlgorithm, server)); err != nil {
+		return nil, err
+	}
+
+	key, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	initMsg := sshAppendString([]byte{sshMsgKexECDHInit}, key.PublicKey().Bytes())
+	if err := conn.writePacket(initMsg); err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.readMessage(sshMsgKexECDHReply)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, _, err := sshReadString(reply[1:])
+	if err != nil {
+		return nil, fmt.Errorf("truncated KEX reply")
+	}
+	return blob, nil
+}
+
+func findWeakSSHAlgorithms(kex *sshKexInit) []string {
+	var weak []string
+	seen := make(map[string]bool)
+	for _, list := range [][]string{kex.Kex, kex.HostKey, kex.CiphersC2S, kex.CiphersS2C, kex.MACsC2S, kex.MACsS2C} {
+		for _, algorithm := range list {
+			if sshWeakAlgorithms[algorithm] && !seen[algorithm] {
+				seen[algorithm] = true
+				weak = append(weak, algorithm)
+			}
+		}
+	}
+	return weak
+}
+
+func defaultSSHHostKeyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "viewnet", "ssh_hostkeys.json")
+}
+
+func loadSSHHostKeyStore() error {
+	sshHostKeyStore = make(map[string]map[string]string)
+	if sshHostKeyFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(sshHostKeyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read SSH host key store: %v", err)
+	}
+	if err := json.Unmarshal(data, &sshHostKeyStore); err != nil {
+		sshHostKeyStore = make(map[string]map[string]string)
+		return fmt.Errorf("invalid SSH host key store %s: %v", sshHostKeyFile, err)
+	}
+	return nil
+}
+
+func saveSSHHostKeyStore() error {
+	if sshHostKeyFile == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(sshHostKeyStore, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode SSH host key store: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(sshHostKeyFile), 0755); err != nil {
+		return fmt.Errorf("failed to create SSH host key store directory: %v", err)
+	}
+	if err := os.WriteFile(sshHostKeyFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write SSH host key store: %v", err)
+	}
+	return nil
+}
+
+func sshHostKeyStoreError() error {
+	sshHostKeyMutex.Lock()
+	defer sshHostKeyMutex.Unlock()
+	return sshHostKeyErr
+}
+
+func checkSSHHostKeys(ip string, port int, info *SSHInfo) {
+	if len(info.HostKeys) == 0 {
+		return
+	}
+
+	sshHostKeyMutex.Lock()
+	defer sshHostKeyMutex.Unlock()
+
+	if sshHostKeyStore == nil {
+		sshHostKeyErr = loadSSHHostKeyStore()
+	}
+
+	key := net.JoinHostPort(ip, fmt.Sprintf("%d", port))
+	known := sshHostKeyStore[key]
+	updated := maps.Clone(known)
+	if updated == nil {
+		updated = make(map[string]string)
+	}
+
+	for _, hostKey := range info.HostKeys {
+		previous, ok := known[hostKey.Type]
+		if ok && previous != hostKey.Fingerprint {
+			info.KeyChanged = true
+			info.ChangedKeys = append(info.ChangedKeys, fmt.Sprintf("%s: %s -> %s", hostKey.Type, previous, hostKey.Fingerprint))
+		}
+		if !ok || sshAcceptHostKeys {
+			updated[hostKey.Type] = hostKey.Fingerprint
+		}
+	}
+
+	if known == nil || !maps.Equal(known, updated) {
+		sshHostKeyStore[key] = updated
+		if sshHostKeyErr == nil {
+			sshHostKeyErr = saveSSHHostKeyStore()
+		}
+	}
+}
+
+func (s *SSHInfo) Summary() string {
+	parts := []string{fmt.Sprintf("SSH-%s %s", s.ProtocolVersion, s.SoftwareVersion)}
+	for _, hostKey := range s.HostKeys {
+		parts = append(parts, fmt.Sprintf("%s %s", hostKey.Type, hostKey.Fingerprint))
+	}
+	if len(s.WeakAlgorithms) > 0 {
+		parts = append(parts, fmt.Sprintf("⚠️ weak: %s", strings.Join(s.WeakAlgorithms, ",")))
+	}
+	if s.KeyChanged {
+		parts = append(parts, "⚠️ HOST KEY CHANGED")
+	}
+	return strings.Join(parts, " | ")
+}
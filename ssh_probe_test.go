@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSSHHostKey = "AAAAC3NzaC1lZDI1NTE5AAAAIHrEnKw9gbx8IGuAlYKfCeblFxfG++r1dVlPCYdmhNcH"
+const testSSHFingerprint = "SHA256:nI75ySClXkrMsm8qw3AgoUB2FOLWb7/DLd4LHgRxGTM"
+
+func TestParseSSHIdentification(t *testing.T) {
+	tests := []struct {
+		line     string
+		protocol string
+		software string
+		comments string
+		ok       bool
+	}{
+		{"SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13\r\n", "2.0", "OpenSSH_9.6p1", "Ubuntu-3ubuntu13", true},
+		{"SSH-2.0-dropbear_2022.83", "2.0", "dropbear_2022.83", "", true},
+		{"SSH-1.99-Cisco-1.25", "1.99", "Cisco-1.25", "", true},
+		{"SSH-2.0-ROSSSH", "2.0", "ROSSSH", "", true},
+		{"SSH-2.0-Open\x1b[2JSSH_9.6 evil\x1b]0;x\x07 \u009bcomment", "2.0", "Open[2JSSH_9.6", "evil]0;x comment", true},
+		{"HTTP/1.1 400 Bad Request", "", "", "", false},
+		{"SSH-2.0-", "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			protocol, software, comments, ok := parseSSHIdentification(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, expected %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if protocol != tt.protocol || software != tt.software || comments != tt.comments {
+				t.Errorf("got %q %q %q, expected %q %q %q", protocol, software, comments, tt.protocol, tt.software, tt.comments)
+			}
+		})
+	}
+
+	if version := extractVersionNew("SSH-2.0-OpenSSH_8.9p1 Ubuntu-3", "SSH"); version != "OpenSSH 8.9p1" {
+		t.Errorf("extractVersionNew() = %q, expected \"OpenSSH 8.9p1\"", version)
+	}
+}
+
+func TestSSHFingerprint(t *testing.T) {
+	blob, err := base64.StdEncoding.DecodeString(testSSHHostKey)
+	if err != nil {
+		t.Fatalf("invalid test key: %v", err)
+	}
+
+	if fingerprint := sshFingerprint(blob); fingerprint != testSSHFingerprint {
+		t.Errorf("sshFingerprint() = %s, expected %s", fingerprint, testSSHFingerprint)
+	}
+}
+
+func buildTestServerKexInit() []byte {
+	server := &sshKexInit{
+		CiphersC2S: []string{"aes128-ctr", "aes128-cbc"},
+		CiphersS2C: []string{"aes128-ctr", "aes128-cbc"},
+		MACsC2S:    []string{"hmac-sha2-256", "hmac-md5"},
+		MACsS2C:    []string{"hmac-sha2-256", "hmac-md5"},
+		CompC2S:    []string{"none"},
+		CompS2C:    []string{"none"},
+	}
+	payload := buildKexInit("curve25519-sha256,diffie-hellman-group1-sha1", "ssh-ed25519,ssh-dss", server)
+	return payload
+}
+
+func startTestSSHServer(t *testing.T, hostKey []byte) int {
+	return startTestListener(t, func(conn net.Conn) {
+		conn.SetDeadline(time.Now().Add(2 * time.Second))
+		c := &sshConn{conn: conn, reader: bufio.NewReader(conn)}
+
+		conn.Write([]byte("SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13\r\n"))
+		if _, err := c.reader.ReadString('\n'); err != nil {
+			return
+		}
+		if err := c.writePacket(buildTestServerKexInit()); err != nil {
+			return
+		}
+
+		if _, err := c.readMessage(sshMsgKexInit); err != nil {
+			return
+		}
+		initMsg, err := c.readMessage(sshMsgKexECDHInit)
+		if err != nil {
+			return
+		}
+		clientKey, _, _ := sshReadString(initMsg[1:])
+
+		reply := sshAppendString([]byte{sshMsgKexECDHReply}, hostKey)
+		reply = sshAppendString(reply, clientKey)
+		reply = sshAppendString(reply, []byte("signature"))
+		c.writePacket(reply)
+	})
+}
+
+func TestProbeSSH(t *testing.T) {
+	hostKey, _ := base64.StdEncoding.DecodeString(testSSHHostKey)
+	port := startTestSSHServer(t, hostKey)
+
+	info, err := probeSSH(context.Background(), "127.0.0.1", port, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("probeSSH() error: %v", err)
+	}
+
+	if info.ProtocolVersion != "2.0" || info.SoftwareVersion != "OpenSSH_9.6p1" {
+		t.Errorf("identification = %s/%s", info.ProtocolVersion, info.SoftwareVersion)
+	}
+	if !slices.Equal(info.KexAlgorithms, []string{"curve25519-sha256", "diffie-hellman-group1-sha1"}) {
+		t.Errorf("kex algorithms = %v", info.KexAlgorithms)
+	}
+	if !slices.Equal(info.HostKeyAlgorithms, []string{"ssh-ed25519", "ssh-dss"}) {
+		t.Errorf("host key algorithms = %v", info.HostKeyAlgorithms)
+	}
+
+	for _, weak := range []string{"diffie-hellman-group1-sha1", "ssh-dss", "aes128-cbc", "hmac-md5"} {
+		if !slices.Contains(info.WeakAlgorithms, weak) {
+			t.Errorf("expected %s to be flagged weak, got %v", weak, info.WeakAlgorithms)
+		}
+	}
+	if slices.Contains(info.WeakAlgorithms, "curve25519-sha256") {
+		t.Error("curve25519-sha256 should not be flagged weak")
+	}
+
+	if len(info.HostKeys) == 0 {
+		t.Fatal("expected at least one host key")
+	}
+	if info.HostKeys[0].Type != "ssh-ed25519" || info.HostKeys[0].Fingerprint != testSSHFingerprint {
+		t.Errorf("host key = %+v", info.HostKeys[0])
+	}
+}
+
+func TestCheckSSHHostKeysDetectsChange(t *testing.T) {
+	oldFile, oldStore, oldErr := sshHostKeyFile, sshHostKeyStore, sshHostKeyErr
+	sshHostKeyFile = filepath.Join(t.TempDir(), "viewnet", "ssh_hostkeys.json")
+	sshHostKeyStore = nil
+	defer func() {
+		sshHostKeyFile, sshHostKeyStore, sshHostKeyErr = oldFile, oldStore, oldErr
+	}()
+
+	first := &SSHInfo{HostKeys: []SSHHostKey{{Type: "ssh-ed25519", Fingerprint: "SHA256:first"}}}
+	checkSSHHostKeys("10.0.0.5", 22, first)
+	if first.KeyChanged {
+		t.Error("first sighting should not be reported as a change")
+	}
+
+	sshHostKeyStore = nil
+
+	same := &SSHInfo{HostKeys: []SSHHostKey{{Type: "ssh-ed25519", Fingerprint: "SHA256:first"}}}
+	checkSSHHostKeys("10.0.0.5", 22, same)
+	if same.KeyChanged {
+		t.Error("unchanged key should not be reported")
+	}
+
+	changed := &SSHInfo{HostKeys: []SSHHostKey{{Type: "ssh-ed25519", Fingerprint: "SHA256:second"}}}
+	checkSSHHostKeys("10.0.0.5", 22, changed)
+	if !changed.KeyChanged {
+		t.Fatal("changed key should be reported")
+	}
+	if len(changed.ChangedKeys) != 1 || !strings.Contains(changed.ChangedKeys[0], "SHA256:first -> SHA256:second") {
+		t.Errorf("changed keys = %v", changed.ChangedKeys)
+	}
+
+	sshHostKeyStore = nil
+	again := &SSHInfo{HostKeys: []SSHHostKey{
+		{Type: "ssh-ed25519", Fingerprint: "SHA256:second"},
+		{Type: "ssh-rsa", Fingerprint: "SHA256:rsa"},
+	}}
+	checkSSHHostKeys("10.0.0.5", 22, again)
+	if !again.KeyChanged || len(again.ChangedKeys) != 1 {
+		t.Errorf("the change should be reported until it is accepted, got %v", again.ChangedKeys)
+	}
+	sshHostKeyStore = nil
+	if err := loadSSHHostKeyStore(); err != nil {
+		t.Fatal(err)
+	}
+	stored := sshHostKeyStore["10.0.0.5:22"]
+	if stored["ssh-ed25519"] != "SHA256:first" || stored["ssh-rsa"] != "SHA256:rsa" {
+		t.Errorf("the known key should be kept and only new key types added, got %v", stored)
+	}
+
+	sshAcceptHostKeys = true
+	checkSSHHostKeys("10.0.0.5", 22, &SSHInfo{HostKeys: []SSHHostKey{{Type: "ssh-ed25519", Fingerprint: "SHA256:second"}}})
+	sshAcceptHostKeys = false
+	accepted := &SSHInfo{HostKeys: []SSHHostKey{{Type: "ssh-ed25519", Fingerprint: "SHA256:second"}}}
+	checkSSHHostKeys("10.0.0.5", 22, accepted)
+	if accepted.KeyChanged {
+		t.Error("an accepted key should no longer be reported")
+	}
+	if err := sshHostKeyStoreError(); err != nil {
+		t.Errorf("unexpected store error: %v", err)
+	}
+
+	if err := os.WriteFile(sshHostKeyFile, []byte("{corrupt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadSSHHostKeyStore(); err == nil || !strings.Contains(err.Error(), "invalid SSH host key store") {
+		t.Errorf("a corrupt store should be reported, got %v", err)
+	}
+	sshHostKeyStore = nil
+	checkSSHHostKeys("10.0.0.5", 22, changed)
+	if sshHostKeyStoreError() == nil {
+		t.Error("the load error should be kept for reporting")
+	}
+	if data, _ := os.ReadFile(sshHostKeyFile); string(data) != "{corrupt" {
+		t.Errorf("a store that failed to load must not be overwritten, got %s", data)
+	}
+}
@@ -15,6 +15,9 @@ var (
 			Foreground(lipgloss.Color("#04B575")).
 			MarginBottom(1)
 
+	contextStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#A0A0A0"))
+
 	upHostStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(lipgloss.Color("#04B575")).
@@ -31,6 +34,17 @@ var (
 			Foreground(lipgloss.Color("#04B575")).
 			Bold(true)
 
+	selectedRowStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("#3C3C5A")).
+				Bold(true)
+
+	searchErrorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FF5F87"))
+
+	detailLabelStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("14")).
+				Bold(true)
+
 	statsStyle = lipgloss.NewStyle().
 			Border(lipgloss.NormalBorder()).
 			BorderForeground(lipgloss.Color("#874BFD")).
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	tcpFlagFIN = 0x01
+	tcpFlagSYN = 0x02
+	tcpFlagRST = 0x04
+	tcpFlagPSH = 0x08
+	tcpFlagACK = 0x10
+)
+
+var errPortClosed = errors.New("port closed")
+var errPortFiltered = errors.New("port filtered")
+
+var activeSYNScanner *SYNScanner
+var synServiceDetection bool
+
+type tcpSegment struct {
+	SrcPort uint16
+	DstPort uint16
+	Seq     uint32
+	Ack     uint32
+	Flags   uint8
+	Window  uint16
+	Options []byte
+}
+
+type synReply struct {
+	Flags    uint8
+	Window   uint16
+	TTL      int
+	Options  []byte
+	Received time.Time
+}
+
+type synProbeKey struct {
+	ip   string
+	port uint16
+}
+
+type synProbe struct {
+	seq   uint32
+	reply chan synReply
+}
+
+type SYNScanner struct {
+	conn    *net.IPConn
+	srcPort uint16
+
+	mu        sync.Mutex
+	pending   map[synProbeKey]*synProbe
+	sourceIPs map[string]net.IP
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func NewSYNScanner() (*SYNScanner, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("SYN scan is not supported on %s", runtime.GOOS)
+	}
+
+	address := "0.0.0.0"
+	if src := scanSourceIP(); src != nil {
+		address = src.String()
+	}
+	pc, err := net.ListenPacket("ip4:tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open raw socket (CAP_NET_RAW required): %v", err)
+	}
+
+	s := &SYNScanner{
+		conn:      pc.(*net.IPConn),
+		srcPort:   uint16(40000 + rand.IntN(20000)),
+		pending:   make(map[synProbeKey]*synProbe),
+		sourceIPs: make(map[string]net.IP),
+		done:      make(chan struct{}),
+	}
+	go s.receiveLoop()
+
+	return s, nil
+}
+
+func (s *SYNScanner) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+func (s *SYNScanner) ScanPort(ctx context.Context, ip string, port int, timeout time.Duration) (*ServiceInfo, error) {
+	info := &ServiceInfo{
+		Port:     port,
+		Protocol: "TCP",
+		Service:  getServiceNameNew(port),
+	}
+
+	dst := net.ParseIP(ip).To4()
+	if dst == nil {
+		return info, fmt.Errorf("invalid IPv4 address: %s", ip)
+	}
+
+	src, err := s.sourceIPFor(dst)
+	if err != nil {
+		return info, err
+	}
+
+	key := synProbeKey{ip: dst.String(), port: uint16(port)}
+	probe := &synProbe{
+		seq:   rand.Uint32(),
+		reply: make(chan synReply, 1),
+	}
+
+	s.mu.Lock()
+	s.pending[key] = probe
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		if s.pending[key] == probe {
+			delete(s.pending, key)
+		}
+		s.mu.Unlock()
+	}()
+
+	syn := buildTCPSegment(src, dst, tcpSegment{
+		SrcPort: s.srcPort,
+		DstPort: uint16(port),
+		Seq:     probe.seq,
+		Flags:   tcpFlagSYN,
+		Window:  64240,
+		Options: synProbeOptions,
+	})
+
+	start := time.Now()
+	if _, err := s.conn.WriteToIP(syn, &net.IPAddr{IP: dst}); err != nil {
+		return info, fmt.Errorf("failed to send SYN: %v", err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case reply := <-probe.reply:
+		info.ResponseTime = reply.Received.Sub(start)
+		if reply.Flags&tcpFlagRST != 0 {
+			return info, errPortClosed
+		}
+
+		rst := buildTCPSegment(src, dst, tcpSegment{
+			SrcPort: s.srcPort,
+			DstPort: uint16(port),
+			Seq:     probe.seq + 1,
+			Flags:   tcpFlagRST,
+		})
+		s.conn.WriteToIP(rst, &net.IPAddr{IP: dst})
+
+		info.IsOpen = true
+		info.TCP = newTCPFingerprint(reply)
+		return info, nil
+	case <-timer.C:
+		info.ResponseTime = time.Since(start)
+		return info, errPortFiltered
+	case <-ctx.Done():
+		info.ResponseTime = time.Since(start)
+		return info, ctx.Err()
+	case <-s.done:
+		return info, net.ErrClosed
+	}
+}
+
+func (s *SYNScanner) sourceIPFor(dst net.IP) (net.IP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if src, ok := s.sourceIPs[dst.String()]; ok {
+		return src, nil
+	}
+	src, err := routeSourceIP(dst)
+	if err != nil {
+		return nil, err
+	}
+	s.sourceIPs[dst.String()] = src
+	return src, nil
+}
+
+func routeSourceIP(dst net.IP) (net.IP, error) {
+	if src := scanSourceIP(); src != nil {
+		return src, nil
+	}
+
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: dst, Port: 9})
+	if err != nil {
+		return nil, fmt.Errorf("no route to %s: %v", dst, err)
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.To4(), nil
+}
+
+func (s *SYNScanner) receiveLoop() {
+	buf := make([]byte, 65535)
+	for {
+		n, _, _, addr, err := s.conn.ReadMsgIP(buf, nil)
+		if err != nil {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		received := time.Now()
+		packet, ttl := stripIPv4Packet(buf[:n])
+		seg, err := parseTCPSegment(packet)
+		if err != nil || seg.DstPort != s.srcPort {
+			continue
+		}
+		if seg.Flags&(tcpFlagSYN|tcpFlagACK) != tcpFlagSYN|tcpFlagACK && seg.Flags&tcpFlagRST == 0 {
+			continue
+		}
+
+		key := synProbeKey{ip: addr.IP.String(), port: seg.SrcPort}
+		s.mu.Lock()
+		probe, ok := s.pending[key]
+		s.mu.Unlock()
+		if !ok || seg.Ack != probe.seq+1 {
+			continue
+		}
+
+		select {
+		case probe.reply <- synReply{
+			Flags:    seg.Flags,
+			Window:   seg.Window,
+			TTL:      ttl,
+			Options:  append([]byte{}, seg.Options...),
+			Received: received,
+		}:
+		default:
+		}
+	}
+}
+
+func stripIPv4Packet(b []byte) ([]byte, int) {
+	if len(b) < 20 || b[0]>>4 != 4 {
+		return b, 0
+	}
+	hdrLen := int(b[0]&0x0f) * 4
+	if hdrLen < 20 || hdrLen > len(b) {
+		return b, 0
+	}
+	return b[hdrLen:], int(b[8])
+}
+
+func buildTCPSegment(src, dst net.IP, seg tcpSegment) []byte {
+	options := seg.Options
+	if pad := len(options) % 4; pad != 0 {
+		options = append(append([]byte{}, options...), make([]byte, 4-pad)...)
+	}
+
+	hdrLen := 20 + len(options)
+	b := make([]byte, hdrLen)
+	binary.BigEndian.PutUint16(b[0:2], seg.SrcPort)
+	binary.BigEndian.PutUint16(b[2:4], seg.DstPort)
+	binary.BigEndian.PutUint32(b[4:8], seg.Seq)
+	binary.BigEndian.PutUint32(b[8:12], seg.Ack)
+	b[12] = byte(hdrLen/4) << 4
+	b[13] = seg.Flags
+	binary.BigEndian.PutUint16(b[14:16], seg.Window)
+	copy(b[20:], options)
+
+	binary.BigEndian.PutUint16(b[16:18], tcpChecksum(src, dst, b))
+	return b
+}
+
+func parseTCPSegment(b []byte) (tcpSegment, error) {
+	if len(b) < 20 {
+		return tcpSegment{}, fmt.Errorf("TCP segment too short: %d bytes", len(b))
+	}
+
+	hdrLen := int(b[12]>>4) * 4
+	if hdrLen < 20 || hdrLen > len(b) {
+		return tcpSegment{}, fmt.Errorf("invalid TCP header length: %d", hdrLen)
+	}
+
+	return tcpSegment{
+		SrcPort: binary.BigEndian.Uint16(b[0:2]),
+		DstPort: binary.BigEndian.Uint16(b[2:4]),
+		Seq:     binary.BigEndian.Uint32(b[4:8]),
+		Ack:     binary.BigEndian.Uint32(b[8:12]),
+		Flags:   b[13],
+		Window:  binary.BigEndian.Uint16(b[14:16]),
+		Options: b[20:hdrLen],
+	}, nil
+}
+
+func tcpChecksum(src, dst net.IP, segment []byte) uint16 {
+	var sum uint32
+
+	pseudo := make([]byte, 12)
+	copy(pseudo[0:4], src.To4())
+	copy(pseudo[4:8], dst.To4())
+	pseudo[9] = 6
+	binary.BigEndian.PutUint16(pseudo[10:12], uint16(len(segment)))
+
+	for _, data := range [][]byte{pseudo, segment} {
+		for i := 0; i+1 < len(data); i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(data[i : i+2]))
+		}
+		if len(data)%2 == 1 {
+			sum += uint32(data[len(data)-1]) << 8
+		}
+	}
+
+	for sum>>16 != 0 {
+		sum = (sum & 0xffff) + (sum >> 16)
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestTCPSegmentRoundTrip(t *testing.T) {
+	src := net.ParseIP("192.168.1.10")
+	dst := net.ParseIP("192.168.1.1")
+
+	seg := tcpSegment{
+		SrcPort: 45000,
+		DstPort: 443,
+		Seq:     0xdeadbeef,
+		Flags:   tcpFlagSYN,
+		Window:  64240,
+		Options: []byte{2, 4, 0x05, 0xb4},
+	}
+
+	packet := buildTCPSegment(src, dst, seg)
+	if len(packet) != 24 {
+		t.Fatalf("expected 24 byte segment, got %d", len(packet))
+	}
+
+	if tcpChecksum(src, dst, packet) != 0 {
+		t.Error("checksum of a finished segment should verify to zero")
+	}
+
+	parsed, err := parseTCPSegment(packet)
+	if err != nil {
+		t.Fatalf("parseTCPSegment() error: %v", err)
+	}
+
+	if parsed.SrcPort != seg.SrcPort || parsed.DstPort != seg.DstPort {
+		t.Errorf("ports = %d->%d, expected %d->%d", parsed.SrcPort, parsed.DstPort, seg.SrcPort, seg.DstPort)
+	}
+	if parsed.Seq != seg.Seq {
+		t.Errorf("seq = %#x, expected %#x", parsed.Seq, seg.Seq)
+	}
+	if parsed.Flags != tcpFlagSYN {
+		t.Errorf("flags = %#x, expected SYN", parsed.Flags)
+	}
+	if parsed.Window != seg.Window {
+		t.Errorf("window = %d, expected %d", parsed.Window, seg.Window)
+	}
+	if len(parsed.Options) != 4 {
+		t.Errorf("expected 4 option bytes, got %d", len(parsed.Options))
+	}
+}
+
+func TestParseTCPSegmentInvalid(t *testing.T) {
+	if _, err := parseTCPSegment(make([]byte, 10)); err == nil {
+		t.Error("expected error for truncated segment")
+	}
+
+	bad := make([]byte, 20)
+	bad[12] = 0xf0
+	if _, err := parseTCPSegment(bad); err == nil {
+		t.Error("expected error for header length beyond buffer")
+	}
+}
+
+func TestStripIPv4Packet(t *testing.T) {
+	packet := make([]byte, 24)
+	packet[0] = 0x45
+	packet[8] = 64
+	packet[20] = 0xaa
+
+	payload, ttl := stripIPv4Packet(packet)
+	if ttl != 64 {
+		t.Errorf("ttl = %d, expected 64", ttl)
+	}
+	if len(payload) != 4 || payload[0] != 0xaa {
+		t.Errorf("unexpected payload %v", payload)
+	}
+
+	raw := []byte{0x12, 0x34}
+	if payload, ttl := stripIPv4Packet(raw); ttl != 0 || len(payload) != 2 {
+		t.Error("non-IP data should be returned unchanged")
+	}
+}
+
+func TestSYNScannerLoopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping raw socket test in short mode")
+	}
+
+	scanner, err := NewSYNScanner()
+	if err != nil {
+		t.Skipf("SYN scanning unavailable: %v", err)
+	}
+	defer scanner.Close()
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	openPort := listener.Addr().(*net.TCPAddr).Port
+
+	closedListener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closedPort := closedListener.Addr().(*net.TCPAddr).Port
+	closedListener.Close()
+	defer listener.Close()
+
+	ctx := context.Background()
+
+	info, err := scanner.ScanPort(ctx, "127.0.0.1", openPort, time.Second)
+	if err != nil {
+		t.Fatalf("expected port %d to be open: %v", openPort, err)
+	}
+	if !info.IsOpen {
+		t.Errorf("port %d not reported open", openPort)
+	}
+	if info.TCP == nil || info.TCP.TTL != 64 || info.TCP.Options != "M,S,T,N,W" {
+		t.Errorf("unexpected loopback TCP fingerprint: %+v", info.TCP)
+	}
+
+	info, err = scanner.ScanPort(ctx, "127.0.0.1", closedPort, time.Second)
+	if err != errPortClosed {
+		t.Errorf("expected errPortClosed for port %d, got %v", closedPort, err)
+	}
+	if info.IsOpen {
+		t.Errorf("port %d reported open", closedPort)
+	}
+}
+
+func TestSYNScanServiceDetection(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping raw socket test in short mode")
+	}
+
+	scanner, err := NewSYNScanner()
+	if err != nil {
+		t.Skipf("SYN scanning unavailable: %v", err)
+	}
+	defer scanner.Close()
+
+	oldScanner, oldDetection := activeSYNScanner, synServiceDetection
+	activeSYNScanner = scanner
+	defer func() { activeSYNScanner, synServiceDetection = oldScanner, oldDetection }()
+
+	accepted := make(chan struct{}, 10)
+	port := startTestListener(t, func(conn net.Conn) {
+		accepted <- struct{}{}
+		conn.Write([]byte("SSH-2.0-OpenSSH_9.6p1\r\n"))
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	synServiceDetection = false
+	info, err := scanPort(context.Background(), "127.0.0.1", port, time.Second)
+	if err != nil || !info.IsOpen {
+		t.Fatalf("expected port %d to be open: %v", port, err)
+	}
+	select {
+	case <-accepted:
+		t.Fatal("a SYN scan without -syn-detect must not complete a connection")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	synServiceDetection = true
+	info, err = scanPort(context.Background(), "127.0.0.1", port, time.Second)
+	if err != nil || info.Service != "SSH" {
+		t.Fatalf("-syn-detect should identify the service, got %+v (%v)", info, err)
+	}
+	select {
+	case <-accepted:
+	case <-time.After(time.Second):
+		t.Error("-syn-detect should connect to the open port")
+	}
+}
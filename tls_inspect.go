@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+var tlsPorts = map[int]bool{
+	443:  true,
+	465:  true,
+	636:  true,
+	993:  true,
+	995:  true,
+	8443: true,
+}
+
+type TLSInfo struct {
+	Version     string
+	CipherSuite string
+	ALPN        string
+	Subject     string
+	Issuer      string
+	SANs        []string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	KeyType     string
+	KeyBits     int
+	Fingerprint string
+	SelfSigned  bool
+}
+
+type certReportEntry struct {
+	Host     string
+	Port     int
+	TLS      *TLSInfo
+	DaysLeft int
+	Expired  bool
+}
+
+func inspectTLS(ctx context.Context, ip string, port int, timeout time.Duration) (*TLSInfo, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS10,
+		NextProtos:         []string{"h2", "http/1.1"},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout*5)
+	defer cancel()
+
+	rawConn, err := dialTCP(ctx, net.JoinHostPort(ip, fmt.Sprintf("%d", port)), timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn := tls.Client(rawConn, config)
+	defer conn.Close()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+
+	state := conn.ConnectionState()
+	info := &TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ALPN:        state.NegotiatedProtocol,
+	}
+
+	if len(state.PeerCertificates) > 0 {
+		fillCertificateInfo(info, state.PeerCertificates[0])
+	}
+
+	return info, nil
+}
+
+func fillCertificateInfo(info *TLSInfo, cert *x509.Certificate) {
+	info.Subject = cert.Subject.String()
+	info.Issuer = cert.Issuer.String()
+	info.NotBefore = cert.NotBefore
+	info.NotAfter = cert.NotAfter
+
+	info.SANs = append(info.SANs, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	info.SANs = append(info.SANs, cert.EmailAddresses...)
+
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		info.KeyType = "RSA"
+		info.KeyBits = key.N.BitLen()
+	case *ecdsa.PublicKey:
+		info.KeyType = "ECDSA"
+		info.KeyBits = key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		info.KeyType = "Ed25519"
+		info.KeyBits = 256
+	default:
+		info.KeyType = cert.PublicKeyAlgorithm.String()
+	}
+
+	sum := sha256.Sum256(cert.Raw)
+	hexParts := make([]string, len(sum))
+	for i, b := range sum {
+		hexParts[i] = fmt.Sprintf("%02X", b)
+	}
+	info.Fingerprint = strings.Join(hexParts, ":")
+
+	info.SelfSigned = bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+func shouldInspectTLS(info *ServiceInfo, matched bool) bool {
+	switch {
+	case tlsPorts[info.Port]:
+		return true
+	case strings.HasPrefix(info.Service, "HTTPS"), info.Service == "IMAPS", info.Service == "POP3S":
+		return true
+	}
+	return !matched && info.Banner == ""
+}
+
+func (t *TLSInfo) Summary() string {
+	parts := []string{t.Version}
+	if t.ALPN != "" {
+		parts = append(parts, t.ALPN)
+	}
+	if cn := certCommonName(t.Subject); cn != "" {
+		parts = append(parts, cn)
+	}
+	if !t.NotAfter.IsZero() {
+		parts = append(parts, "expires "+t.NotAfter.Format("2006-01-02"))
+	}
+	if t.SelfSigned {
+		parts = append(parts, "self-signed")
+	}
+	return strings.Join(parts, " | ")
+}
+
+func certCommonName(dn string) string {
+	for part := range strings.SplitSeq(dn, ",") {
+		if name, ok := strings.CutPrefix(part, "CN="); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+func certificateReport(hosts []*HostInfo, days int, now time.Time) []certReportEntry {
+	var entries []certReportEntry
+	deadline := now.Add(time.Duration(days) * 24 * time.Hour)
+
+	for _, host := range hosts {
+		for _, service := range host.Services {
+			if service.TLS == nil || service.TLS.NotAfter.IsZero() {
+				continue
+			}
+			if !service.TLS.SelfSigned && service.TLS.NotAfter.After(deadline) {
+				continue
+			}
+
+			entries = append(entries, certReportEntry{
+				Host:     host.IP,
+				Port:     service.Port,
+				TLS:      service.TLS,
+				DaysLeft: int(math.Floor(service.TLS.NotAfter.Sub(now).Hours() / 24)),
+				Expired:  service.TLS.NotAfter.Before(now),
+			})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].TLS.NotAfter.Before(entries[j].TLS.NotAfter)
+	})
+
+	return entries
+}
+
+func printCertificateReport(hosts []*HostInfo, days int) {
+	now := time.Now()
+	entries := certificateReport(hosts, days, now)
+
+	fmt.Printf("\n🔒 Certificates expiring within %d days or self-signed: %d\n", days, len(entries))
+	for _, entry := range entries {
+		var flags []string
+		if entry.Expired {
+			flags = append(flags, fmt.Sprintf("EXPIRED %d days ago", -entry.DaysLeft))
+		} else if entry.TLS.NotAfter.Before(now.Add(time.Duration(days) * 24 * time.Hour)) {
+			flags = append(flags, fmt.Sprintf("expires in %d days", entry.DaysLeft))
+		}
+		if entry.TLS.SelfSigned {
+			flags = append(flags, "self-signed")
+		}
+
+		name := certCommonName(entry.TLS.Subject)
+		if name == "" {
+			name = entry.TLS.Subject
+		}
+
+		fmt.Printf("   %s:%d  %s  (%s, not after %s)\n",
+			entry.Host, entry.Port, name, strings.Join(flags, ", "), entry.TLS.NotAfter.Format("2006-01-02"))
+	}
+}
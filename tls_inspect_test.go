@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestCertificate(t *testing.T, notAfter time.Time) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "router.lan", Organization: []string{"ViewNet Test"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		DNSNames:     []string{"router.lan"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func startTLSListener(t *testing.T, cert tls.Certificate) int {
+	t.Helper()
+
+	listener, err := tls.Listen("tcp4", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"http/1.1"},
+	})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.(*tls.Conn).Handshake()
+				time.Sleep(50 * time.Millisecond)
+			}()
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestInspectTLS(t *testing.T) {
+	notAfter := time.Now().Add(10 * 24 * time.Hour).Truncate(time.Second)
+	port := startTLSListener(t, newTestCertificate(t, notAfter))
+
+	info, err := inspectTLS(context.Background(), "127.0.0.1", port, time.Second)
+	if err != nil {
+		t.Fatalf("inspectTLS() error: %v", err)
+	}
+
+	if info.Version != "TLS 1.3" {
+		t.Errorf("version = %q, expected TLS 1.3", info.Version)
+	}
+	if info.CipherSuite == "" {
+		t.Error("cipher suite should be set")
+	}
+	if info.ALPN != "http/1.1" {
+		t.Errorf("ALPN = %q, expected http/1.1", info.ALPN)
+	}
+	if certCommonName(info.Subject) != "router.lan" {
+		t.Errorf("subject = %q, expected CN router.lan", info.Subject)
+	}
+	if !slices.Contains(info.SANs, "router.lan") || !slices.Contains(info.SANs, "127.0.0.1") {
+		t.Errorf("SANs = %v, expected DNS and IP entries", info.SANs)
+	}
+	if info.KeyType != "ECDSA" || info.KeyBits != 256 {
+		t.Errorf("key = %s/%d, expected ECDSA/256", info.KeyType, info.KeyBits)
+	}
+	if !info.NotAfter.Equal(notAfter.UTC()) {
+		t.Errorf("not after = %v, expected %v", info.NotAfter, notAfter)
+	}
+	if len(info.Fingerprint) != 95 {
+		t.Errorf("fingerprint %q should be 32 colon-separated bytes", info.Fingerprint)
+	}
+	if !info.SelfSigned {
+		t.Error("certificate should be detected as self-signed")
+	}
+}
+
+func TestSelfSignedDetection(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ViewNet Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "printer.lan"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	tests := []struct {
+		name       string
+		template   *x509.Certificate
+		key        *ecdsa.PrivateKey
+		parent     *x509.Certificate
+		signer     *ecdsa.PrivateKey
+		selfSigned bool
+	}{
+		{"self-signed CA", caTemplate, caKey, caTemplate, caKey, true},
+		{"self-signed leaf without CA flag", leafTemplate, leafKey, leafTemplate, leafKey, true},
+		{"leaf issued by a CA", leafTemplate, leafKey, caTemplate, caKey, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			der, err := x509.CreateCertificate(rand.Reader, tt.template, tt.parent, &tt.key.PublicKey, tt.signer)
+			if err != nil {
+				t.Fatalf("failed to create certificate: %v", err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				t.Fatalf("failed to parse certificate: %v", err)
+			}
+
+			var info TLSInfo
+			fillCertificateInfo(&info, cert)
+			if info.SelfSigned != tt.selfSigned {
+				t.Errorf("self-signed = %v, expected %v", info.SelfSigned, tt.selfSigned)
+			}
+		})
+	}
+}
+
+func TestDetectTLSOnUnknownPort(t *testing.T) {
+	port := startTLSListener(t, newTestCertificate(t, time.Now().Add(365*24*time.Hour)))
+
+	info, err := scanPortNew(context.Background(), "127.0.0.1", port, 300*time.Millisecond)
+	if err != nil {
+		t.Fatalf("scanPortNew() error: %v", err)
+	}
+
+	if info.TLS == nil {
+		t.Fatal("expected TLS details for TLS listener")
+	}
+	if info.Service != "TLS" {
+		t.Errorf("service = %q, expected TLS", info.Service)
+	}
+}
+
+func TestCertificateReport(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	hosts := []*HostInfo{
+		{
+			IP: "10.0.0.1",
+			Services: []ServiceInfo{
+				{Port: 443, TLS: &TLSInfo{Subject: "CN=soon", NotAfter: now.Add(5 * 24 * time.Hour)}},
+				{Port: 8443, TLS: &TLSInfo{Subject: "CN=later", NotAfter: now.Add(90 * 24 * time.Hour)}},
+				{Port: 22},
+			},
+		},
+		{
+			IP: "10.0.0.2",
+			Services: []ServiceInfo{
+				{Port: 443, TLS: &TLSInfo{Subject: "CN=self", NotAfter: now.Add(400 * 24 * time.Hour), SelfSigned: true}},
+				{Port: 993, TLS: &TLSInfo{Subject: "CN=expired", NotAfter: now.Add(-2 * 24 * time.Hour)}},
+			},
+		},
+	}
+
+	entries := certificateReport(hosts, 30, now)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 report entries, got %d", len(entries))
+	}
+
+	expected := []string{"expired", "soon", "self"}
+	for i, name := range expected {
+		if got := certCommonName(entries[i].TLS.Subject); got != name {
+			t.Errorf("entry %d = %q, expected %q", i, got, name)
+		}
+	}
+
+	if entries[0].DaysLeft != -2 || !entries[0].Expired {
+		t.Errorf("expired certificate days left = %d (expired %v), expected -2", entries[0].DaysLeft, entries[0].Expired)
+	}
+	if entries[1].Expired {
+		t.Error("a certificate valid for 5 more days should not be expired")
+	}
+
+	recent := []*HostInfo{{IP: "10.0.0.3", Services: []ServiceInfo{
+		{Port: 443, TLS: &TLSInfo{Subject: "CN=hours", NotAfter: now.Add(-3 * time.Hour)}},
+	}}}
+	entries = certificateReport(recent, 30, now)
+	if len(entries) != 1 || !entries[0].Expired || entries[0].DaysLeft != -1 {
+		t.Errorf("a certificate expired 3 hours ago should be expired with -1 days left, got %+v", entries)
+	}
+}
@@ -0,0 +1,672 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	traceUDP  = "udp"
+	traceICMP = "icmp"
+	traceTCP  = "tcp"
+
+	traceDefaultMaxHops = 30
+	traceDefaultProbes  = 3
+	traceDefaultTimeout = time.Second
+	traceUDPBasePort    = 33434
+	traceTCPDefaultPort = 80
+	traceProbeGap       = 5 * time.Millisecond
+
+	icmpEchoReply       = 0
+	icmpDestUnreachable = 3
+	icmpEchoRequest     = 8
+	icmpTimeExceeded    = 11
+
+	ipProtoICMP = 1
+	ipProtoTCP  = 6
+	ipProtoUDP  = 17
+)
+
+var traceUnreachableNotes = map[byte]string{
+	0:  "!N",
+	1:  "!H",
+	2:  "!P",
+	4:  "!F",
+	9:  "!X",
+	10: "!X",
+	13: "!X",
+}
+
+type TraceOptions struct {
+	Method  string
+	MaxHops int
+	Probes  int
+	Port    int
+	Timeout time.Duration
+}
+
+type TraceHop struct {
+	TTL      int
+	Address  string
+	Hostname string
+	RTTs     []time.Duration
+	Sent     int
+	Note     string
+}
+
+type TraceResult struct {
+	Target  string
+	Method  string
+	Hops    []TraceHop
+	Reached bool
+}
+
+type traceProbe struct {
+	index int
+	ttl   int
+	sent  time.Time
+}
+
+type traceReply struct {
+	index    int
+	from     string
+	received time.Time
+	reached  bool
+	terminal bool
+	note     string
+}
+
+type tracer struct {
+	opts    TraceOptions
+	target  net.IP
+	source  net.IP
+	id      uint16
+	seqBase uint32
+	srcPort uint16
+
+	icmp *net.IPConn
+	udp  *net.UDPConn
+	tcp  *net.IPConn
+
+	mu         sync.Mutex
+	probes     []traceProbe
+	replies    []traceReply
+	reachedTTL int
+}
+
+func (o TraceOptions) withDefaults() TraceOptions {
+	if o.Method == "" {
+		o.Method = traceUDP
+	}
+	if o.MaxHops <= 0 {
+		o.MaxHops = traceDefaultMaxHops
+	}
+	if o.Probes <= 0 {
+		o.Probes = traceDefaultProbes
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = traceDefaultTimeout
+	}
+	if o.Port <= 0 {
+		if o.Method == traceTCP {
+			o.Port = traceTCPDefaultPort
+		} else {
+			o.Port = traceUDPBasePort
+		}
+	}
+	return o
+}
+
+func traceRoute(ctx context.Context, target string, opts TraceOptions) (*TraceResult, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("traceroute is not supported on %s", runtime.GOOS)
+	}
+	if activeProxy != nil {
+		return nil, fmt.Errorf("traceroute cannot run through a proxy")
+	}
+
+	opts = opts.withDefaults()
+	switch opts.Method {
+	case traceUDP, traceICMP, traceTCP:
+	default:
+		return nil, fmt.Errorf("unknown trace method %q (use udp, icmp or tcp)", opts.Method)
+	}
+	if opts.Port > 65535 {
+		return nil, fmt.Errorf("invalid port %d", opts.Port)
+	}
+
+	dst := net.ParseIP(target).To4()
+	if dst == nil {
+		return nil, fmt.Errorf("invalid IPv4 address: %s", target)
+	}
+
+	t, err := newTracer(dst, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer t.close()
+
+	t.run(ctx)
+
+	t.mu.Lock()
+	result := assembleTrace(dst.String(), opts, t.probes, t.replies)
+	t.mu.Unlock()
+
+	resolveTraceNames(result)
+	return result, ctx.Err()
+}
+
+func newTracer(dst net.IP, opts TraceOptions) (*tracer, error) {
+	src, err := routeSourceIP(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tracer{
+		opts:    opts,
+		target:  dst,
+		source:  src,
+		id:      uint16(rand.IntN(0xffff)),
+		seqBase: rand.Uint32(),
+		srcPort: uint16(40000 + rand.IntN(20000)),
+	}
+
+	pc, err := net.ListenPacket("ip4:icmp", src.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ICMP socket (root or CAP_NET_RAW required): %v", err)
+	}
+	t.icmp = pc.(*net.IPConn)
+
+	switch opts.Method {
+	case traceUDP:
+		t.udp, err = net.ListenUDP("udp4", &net.UDPAddr{IP: src})
+	case traceTCP:
+		var raw net.PacketConn
+		raw, err = net.ListenPacket("ip4:tcp", src.String())
+		if err == nil {
+			t.tcp = raw.(*net.IPConn)
+		}
+	}
+	if err != nil {
+		t.close()
+		return nil, fmt.Errorf("failed to open %s probe socket: %v", opts.Method, err)
+	}
+
+	return t, nil
+}
+
+func (t *tracer) close() {
+	t.icmp.Close()
+	if t.udp != nil {
+		t.udp.Close()
+	}
+	if t.tcp != nil {
+		t.tcp.Close()
+	}
+}
+
+func (t *tracer) run(ctx context.Context) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t.receiveICMP(done)
+	}()
+	if t.tcp != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.receiveTCP(done)
+		}()
+	}
+
+	for round := 0; round < t.opts.Probes && ctx.Err() == nil; round++ {
+		for ttl := 1; ttl <= t.opts.MaxHops && ctx.Err() == nil; ttl++ {
+			t.mu.Lock()
+			reached := t.reachedTTL
+			t.mu.Unlock()
+			if reached > 0 && ttl > reached {
+				break
+			}
+
+			index := round*t.opts.MaxHops + ttl - 1
+			if err := t.send(index, ttl); err != nil {
+				continue
+			}
+			time.Sleep(traceProbeGap)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(t.opts.Timeout):
+		}
+	}
+
+	close(done)
+	t.icmp.SetReadDeadline(time.Now())
+	if t.tcp != nil {
+		t.tcp.SetReadDeadline(time.Now())
+	}
+	wg.Wait()
+}
+
+func (t *tracer) send(index, ttl int) error {
+	var err error
+	t.mu.Lock()
+	t.probes = append(t.probes, traceProbe{index: index, ttl: ttl, sent: time.Now()})
+	t.mu.Unlock()
+
+	switch t.opts.Method {
+	case traceUDP:
+		if err = setSocketTTL(t.udp, ttl); err == nil {
+			_, err = t.udp.WriteToUDP([]byte("viewnet"), &net.UDPAddr{IP: t.target, Port: t.udpPort(index)})
+		}
+	case traceICMP:
+		if err = setSocketTTL(t.icmp, ttl); err == nil {
+			_, err = t.icmp.WriteToIP(buildICMPEcho(t.id, uint16(index), []byte("viewnet")), &net.IPAddr{IP: t.target})
+		}
+	case traceTCP:
+		syn := buildTCPSegment(t.source, t.target, tcpSegment{
+			SrcPort: t.srcPort,
+			DstPort: uint16(t.opts.Port),
+			Seq:     t.seqBase + uint32(index),
+			Flags:   tcpFlagSYN,
+			Window:  64240,
+			Options: synProbeOptions,
+		})
+		if err = setSocketTTL(t.tcp, ttl); err == nil {
+			_, err = t.tcp.WriteToIP(syn, &net.IPAddr{IP: t.target})
+		}
+	}
+	return err
+}
+
+func (t *tracer) receiveICMP(done chan struct{}) {
+	buf := make([]byte, 1500)
+	for {
+		n, _, _, addr, err := t.icmp.ReadMsgIP(buf, nil)
+		if err != nil {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		message, _ := stripIPv4Packet(buf[:n])
+		if reply, ok := t.matchICMP(message, addr.IP, time.Now()); ok {
+			t.record(reply)
+		}
+	}
+}
+
+func (t *tracer) receiveTCP(done chan struct{}) {
+	buf := make([]byte, 65535)
+	for {
+		n, _, _, addr, err := t.tcp.ReadMsgIP(buf, nil)
+		if err != nil {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		packet, _ := stripIPv4Packet(buf[:n])
+		seg, err := parseTCPSegment(packet)
+		if err != nil || !addr.IP.Equal(t.target) {
+			continue
+		}
+		if reply, ok := t.matchTCP(seg, addr.IP, time.Now()); ok {
+			t.record(reply)
+		}
+	}
+}
+
+func (t *tracer) record(reply traceReply) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.replies = append(t.replies, reply)
+	if reply.reached || reply.terminal {
+		ttl := reply.index%t.opts.MaxHops + 1
+		if t.reachedTTL == 0 || ttl < t.reachedTTL {
+			t.reachedTTL = ttl
+		}
+	}
+}
+
+func (t *tracer) matchICMP(message []byte, from net.IP, received time.Time) (traceReply, bool) {
+	if len(message) < 8 {
+		return traceReply{}, false
+	}
+	kind, code := message[0], message[1]
+	reply := traceReply{from: from.String(), received: received}
+
+	if kind == icmpEchoReply {
+		if t.opts.Method != traceICMP || !from.Equal(t.target) || binary.BigEndian.Uint16(message[4:6]) != t.id {
+			return traceReply{}, false
+		}
+		reply.index = int(binary.BigEndian.Uint16(message[6:8]))
+		reply.reached = true
+		return reply, t.validIndex(reply.index)
+	}
+	if kind != icmpTimeExceeded && kind != icmpDestUnreachable {
+		return traceReply{}, false
+	}
+
+	inner := message[8:]
+	if len(inner) < 20 || inner[0]>>4 != 4 {
+		return traceReply{}, false
+	}
+	hdrLen := int(inner[0]&0x0f) * 4
+	if hdrLen < 20 || len(inner) < hdrLen+8 || !net.IP(inner[16:20]).Equal(t.target) {
+		return traceReply{}, false
+	}
+	proto, original := inner[9], inner[hdrLen:]
+
+	switch t.opts.Method {
+	case traceUDP:
+		if proto != ipProtoUDP {
+			return traceReply{}, false
+		}
+		reply.index = (int(binary.BigEndian.Uint16(original[2:4])) - t.opts.Port + 65535) % 65535
+	case traceICMP:
+		if proto != ipProtoICMP || original[0] != icmpEchoRequest || binary.BigEndian.Uint16(original[4:6]) != t.id {
+			return traceReply{}, false
+		}
+		reply.index = int(binary.BigEndian.Uint16(original[6:8]))
+	case traceTCP:
+		if proto != ipProtoTCP || binary.BigEndian.Uint16(original[0:2]) != t.srcPort {
+			return traceReply{}, false
+		}
+		reply.index = int(binary.BigEndian.Uint32(original[4:8]) - t.seqBase)
+	}
+
+	if kind == icmpDestUnreachable {
+		if t.opts.Method == traceUDP && code == 3 && from.Equal(t.target) {
+			reply.reached = true
+		} else {
+			reply.terminal = true
+			reply.note = traceUnreachableNotes[code]
+		}
+	}
+	return reply, t.validIndex(reply.index)
+}
+
+func (t *tracer) matchTCP(seg tcpSegment, from net.IP, received time.Time) (traceReply, bool) {
+	if seg.SrcPort != uint16(t.opts.Port) || seg.DstPort != t.srcPort {
+		return traceReply{}, false
+	}
+	if seg.Flags&(tcpFlagSYN|tcpFlagACK) != tcpFlagSYN|tcpFlagACK && seg.Flags&tcpFlagRST == 0 {
+		return traceReply{}, false
+	}
+
+	reply := traceReply{
+		index:    int(seg.Ack - 1 - t.seqBase),
+		from:     from.String(),
+		received: received,
+		reached:  true,
+	}
+	return reply, t.validIndex(reply.index)
+}
+
+func (t *tracer) udpPort(index int) int {
+	return (t.opts.Port-1+index)%65535 + 1
+}
+
+func (t *tracer) validIndex(index int) bool {
+	return index >= 0 && index < t.opts.MaxHops*t.opts.Probes
+}
+
+func buildICMPEcho(id, seq uint16, payload []byte) []byte {
+	b := make([]byte, 8+len(payload))
+	b[0] = icmpEchoRequest
+	binary.BigEndian.PutUint16(b[4:6], id)
+	binary.BigEndian.PutUint16(b[6:8], seq)
+	copy(b[8:], payload)
+	binary.BigEndian.PutUint16(b[2:4], internetChecksum(b))
+	return b
+}
+
+func internetChecksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(b[i : i+2]))
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = (sum & 0xffff) + (sum >> 16)
+	}
+	return ^uint16(sum)
+}
+
+func assembleTrace(target string, opts TraceOptions, probes []traceProbe, replies []traceReply) *TraceResult {
+	result := &TraceResult{Target: target, Method: opts.Method}
+
+	sent := make(map[int]traceProbe)
+	for _, probe := range probes {
+		sent[probe.index] = probe
+	}
+
+	answered := make(map[int]traceReply)
+	lastTTL := 0
+	for _, reply := range replies {
+		probe, ok := sent[reply.index]
+		if !ok {
+			continue
+		}
+		if _, seen := answered[reply.index]; seen {
+			continue
+		}
+		answered[reply.index] = reply
+
+		if reply.reached || reply.terminal {
+			if !result.Reached || probe.ttl < lastTTL {
+				lastTTL = probe.ttl
+			}
+			result.Reached = result.Reached || reply.reached
+		} else if !result.Reached && probe.ttl > lastTTL {
+			lastTTL = probe.ttl
+		}
+	}
+
+	hops := make(map[int]*TraceHop)
+	for ttl := 1; ttl <= lastTTL; ttl++ {
+		hops[ttl] = &TraceHop{TTL: ttl}
+	}
+
+	sort.Slice(probes, func(i, j int) bool { return probes[i].index < probes[j].index })
+	for _, probe := range probes {
+		hop, ok := hops[probe.ttl]
+		if !ok {
+			continue
+		}
+		hop.Sent++
+
+		reply, ok := answered[probe.index]
+		if !ok {
+			continue
+		}
+		if hop.Address == "" {
+			hop.Address = reply.from
+		}
+		if reply.note != "" {
+			hop.Note = reply.note
+		}
+		hop.RTTs = append(hop.RTTs, reply.received.Sub(probe.sent))
+	}
+
+	for ttl := 1; ttl <= lastTTL; ttl++ {
+		result.Hops = append(result.Hops, *hops[ttl])
+	}
+	return result
+}
+
+func resolveTraceNames(result *TraceResult) {
+	var wg sync.WaitGroup
+	for i := range result.Hops {
+		if result.Hops[i].Address == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(hop *TraceHop) {
+			defer wg.Done()
+			hop.Hostname = getHostnameNew(hop.Address)
+		}(&result.Hops[i])
+	}
+	wg.Wait()
+}
+
+func (h TraceHop) Lost() int {
+	return h.Sent - len(h.RTTs)
+}
+
+func (h TraceHop) String() string {
+	line := fmt.Sprintf("%2d  ", h.TTL)
+	if h.Address == "" {
+		return line + strings.TrimSpace(strings.Repeat("*  ", max(h.Sent, 1)))
+	}
+
+	line += h.Address
+	if h.Hostname != "" {
+		line += fmt.Sprintf(" (%s)", h.Hostname)
+	}
+	for _, rtt := range h.RTTs {
+		line += fmt.Sprintf("  %.3f ms", float64(rtt.Microseconds())/1000)
+	}
+	line += strings.Repeat("  *", h.Lost())
+	if h.Note != "" {
+		line += " " + h.Note
+	}
+	return line
+}
+
+func (r *TraceResult) Lines() []string {
+	lines := []string{fmt.Sprintf("traceroute to %s (%s), %d hops", r.Target, r.Method, len(r.Hops))}
+	for _, hop := range r.Hops {
+		lines = append(lines, hop.String())
+	}
+	if !r.Reached {
+		lines = append(lines, "    target not reached")
+	}
+	return lines
+}
+
+type TopologyNode struct {
+	Address  string
+	Hostname string
+	Targets  []string
+	Children []*TopologyNode
+}
+
+func buildTopology(traces []*TraceResult) *TopologyNode {
+	root := &TopologyNode{Address: "this host"}
+	for _, trace := range traces {
+		node := root
+		for _, hop := range trace.Hops {
+			if hop.Address == trace.Target {
+				break
+			}
+			address := hop.Address
+			if address == "" {
+				address = "*"
+			}
+			node = node.child(address, hop.Hostname)
+		}
+		node.Targets = append(node.Targets, trace.Target)
+	}
+	root.sort()
+	return root
+}
+
+func (n *TopologyNode) child(address, hostname string) *TopologyNode {
+	for _, child := range n.Children {
+		if child.Address == address {
+			return child
+		}
+	}
+	child := &TopologyNode{Address: address, Hostname: hostname}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+func (n *TopologyNode) sort() {
+	sort.Slice(n.Targets, func(i, j int) bool {
+		return lessIP(n.Targets[i], n.Targets[j])
+	})
+	sort.Slice(n.Children, func(i, j int) bool {
+		return lessIP(n.Children[i].Address, n.Children[j].Address)
+	})
+	for _, child := range n.Children {
+		child.sort()
+	}
+}
+
+func (n *TopologyNode) HostCount() int {
+	count := len(n.Targets)
+	for _, child := range n.Children {
+		count += child.HostCount()
+	}
+	return count
+}
+
+func (n *TopologyNode) label() string {
+	label := n.Address
+	if n.Hostname != "" {
+		label += " (" + n.Hostname + ")"
+	}
+	count := n.HostCount()
+	if count == 1 {
+		return label + " [1 host]"
+	}
+	return fmt.Sprintf("%s [%d hosts]", label, count)
+}
+
+func renderTopology(root *TopologyNode) []string {
+	lines := []string{root.label()}
+	var walk func(node *TopologyNode, prefix string)
+	walk = func(node *TopologyNode, prefix string) {
+		total := len(node.Children) + len(node.Targets)
+		i := 0
+		next := func() (string, string) {
+			i++
+			if i == total {
+				return prefix + "└─ ", prefix + "   "
+			}
+			return prefix + "├─ ", prefix + "│  "
+		}
+		for _, child := range node.Children {
+			branch, indent := next()
+			lines = append(lines, branch+child.label())
+			walk(child, indent)
+		}
+		for _, target := range node.Targets {
+			branch, _ := next()
+			lines = append(lines, branch+target)
+		}
+	}
+	walk(root, "")
+	return lines
+}
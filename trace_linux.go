@@ -0,0 +1,21 @@
+//go:build linux
+
+package main
+
+import "syscall"
+
+func setSocketTTL(conn syscall.Conn, ttl int) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TTL, ttl)
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+)
+
+func setSocketTTL(conn syscall.Conn, ttl int) error {
+	return fmt.Errorf("setting the IP TTL is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+func buildTimeExceeded(kind, code byte, target net.IP, proto byte, original []byte) []byte {
+	inner := make([]byte, 20)
+	inner[0] = 0x45
+	inner[9] = proto
+	copy(inner[12:16], net.IPv4(192, 168, 1, 50).To4())
+	copy(inner[16:20], target.To4())
+
+	message := []byte{kind, code, 0, 0, 0, 0, 0, 0}
+	message = append(message, inner...)
+	return append(message, original...)
+}
+
+func TestTracerMatchICMP(t *testing.T) {
+	target := net.ParseIP("10.20.0.5").To4()
+	router := net.ParseIP("10.0.0.1")
+	opts := TraceOptions{MaxHops: 30, Probes: 3}
+
+	udpHeader := func(dstPort uint16) []byte {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint16(b[2:4], dstPort)
+		return b
+	}
+	tcpHeader := func(srcPort uint16, seq uint32) []byte {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint16(b[0:2], srcPort)
+		binary.BigEndian.PutUint32(b[4:8], seq)
+		return b
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		from      net.IP
+		message   []byte
+		wantOK    bool
+		wantIndex int
+		reached   bool
+		terminal  bool
+		note      string
+	}{
+		{
+			name:      "udp time exceeded",
+			method:    traceUDP,
+			from:      router,
+			message:   buildTimeExceeded(icmpTimeExceeded, 0, target, ipProtoUDP, udpHeader(traceUDPBasePort+4)),
+			wantOK:    true,
+			wantIndex: 4,
+		},
+		{
+			name:      "udp port unreachable from target",
+			method:    traceUDP,
+			from:      target,
+			message:   buildTimeExceeded(icmpDestUnreachable, 3, target, ipProtoUDP, udpHeader(traceUDPBasePort+7)),
+			wantOK:    true,
+			wantIndex: 7,
+			reached:   true,
+		},
+		{
+			name:      "host unreachable from router",
+			method:    traceUDP,
+			from:      router,
+			message:   buildTimeExceeded(icmpDestUnreachable, 1, target, ipProtoUDP, udpHeader(traceUDPBasePort+2)),
+			wantOK:    true,
+			wantIndex: 2,
+			terminal:  true,
+			note:      "!H",
+		},
+		{
+			name:    "other destination ignored",
+			method:  traceUDP,
+			from:    router,
+			message: buildTimeExceeded(icmpTimeExceeded, 0, net.ParseIP("10.20.0.6"), ipProtoUDP, udpHeader(traceUDPBasePort+4)),
+		},
+		{
+			name:    "out of range index ignored",
+			method:  traceUDP,
+			from:    router,
+			message: buildTimeExceeded(icmpTimeExceeded, 0, target, ipProtoUDP, udpHeader(traceUDPBasePort+500)),
+		},
+		{
+			name:      "icmp echo reply",
+			method:    traceICMP,
+			from:      target,
+			message:   []byte{icmpEchoReply, 0, 0, 0, 0x12, 0x34, 0, 9},
+			wantOK:    true,
+			wantIndex: 9,
+			reached:   true,
+		},
+		{
+			name:    "foreign echo reply ignored",
+			method:  traceICMP,
+			from:    target,
+			message: []byte{icmpEchoReply, 0, 0, 0, 0x43, 0x21, 0, 9},
+		},
+		{
+			name:      "icmp time exceeded",
+			method:    traceICMP,
+			from:      router,
+			message:   buildTimeExceeded(icmpTimeExceeded, 0, target, ipProtoICMP, []byte{icmpEchoRequest, 0, 0, 0, 0x12, 0x34, 0, 3}),
+			wantOK:    true,
+			wantIndex: 3,
+		},
+		{
+			name:      "tcp time exceeded",
+			method:    traceTCP,
+			from:      router,
+			message:   buildTimeExceeded(icmpTimeExceeded, 0, target, ipProtoTCP, tcpHeader(45000, 1005)),
+			wantOK:    true,
+			wantIndex: 5,
+		},
+		{
+			name:    "tcp from other source port ignored",
+			method:  traceTCP,
+			from:    router,
+			message: buildTimeExceeded(icmpTimeExceeded, 0, target, ipProtoTCP, tcpHeader(45001, 1005)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := opts
+			o.Method = tt.method
+			tr := &tracer{opts: o.withDefaults(), target: target, id: 0x1234, seqBase: 1000, srcPort: 45000}
+
+			reply, ok := tr.matchICMP(tt.message, tt.from, time.Now())
+			if ok != tt.wantOK {
+				t.Fatalf("matchICMP() ok = %v, want %v (%+v)", ok, tt.wantOK, reply)
+			}
+			if !ok {
+				return
+			}
+			if reply.index != tt.wantIndex || reply.reached != tt.reached || reply.terminal != tt.terminal || reply.note != tt.note {
+				t.Errorf("matchICMP() = %+v", reply)
+			}
+		})
+	}
+}
+
+func TestTracerUDPPortWraps(t *testing.T) {
+	target := net.ParseIP("10.20.0.5").To4()
+	tr := &tracer{opts: TraceOptions{Method: traceUDP, MaxHops: 30, Probes: 3, Port: 65534}, target: target}
+
+	for index, want := range map[int]int{0: 65534, 1: 65535, 2: 1, 4: 3} {
+		port := tr.udpPort(index)
+		if port != want {
+			t.Errorf("udpPort(%d) = %d, want %d", index, port, want)
+		}
+		header := make([]byte, 8)
+		binary.BigEndian.PutUint16(header[2:4], uint16(port))
+		reply, ok := tr.matchICMP(buildTimeExceeded(icmpTimeExceeded, 0, target, ipProtoUDP, header), net.ParseIP("10.0.0.1"), time.Now())
+		if !ok || reply.index != index {
+			t.Errorf("reply for port %d matched index %d (%v), want %d", port, reply.index, ok, index)
+		}
+	}
+}
+
+func TestTracerMatchTCP(t *testing.T) {
+	tr := &tracer{opts: TraceOptions{Method: traceTCP}.withDefaults(), seqBase: 1000, srcPort: 45000}
+
+	synAck := tcpSegment{SrcPort: 80, DstPort: 45000, Ack: 1006, Flags: tcpFlagSYN | tcpFlagACK}
+	if reply, ok := tr.matchTCP(synAck, net.ParseIP("10.20.0.5"), time.Now()); !ok || reply.index != 5 || !reply.reached {
+		t.Errorf("matchTCP(SYN-ACK) = %+v, %v", reply, ok)
+	}
+
+	rst := tcpSegment{SrcPort: 80, DstPort: 45000, Ack: 1001, Flags: tcpFlagRST | tcpFlagACK}
+	if reply, ok := tr.matchTCP(rst, net.ParseIP("10.20.0.5"), time.Now()); !ok || reply.index != 0 {
+		t.Errorf("matchTCP(RST) = %+v, %v", reply, ok)
+	}
+
+	other := tcpSegment{SrcPort: 443, DstPort: 45000, Ack: 1006, Flags: tcpFlagSYN | tcpFlagACK}
+	if _, ok := tr.matchTCP(other, net.ParseIP("10.20.0.5"), time.Now()); ok {
+		t.Error("matchTCP() should ignore replies from other ports")
+	}
+}
+
+func TestAssembleTrace(t *testing.T) {
+	opts := TraceOptions{Method: traceUDP, MaxHops: 5, Probes: 2}
+	start := time.Now()
+
+	var probes []traceProbe
+	for round := range 2 {
+		for ttl := 1; ttl <= 5; ttl++ {
+			probes = append(probes, traceProbe{index: round*5 + ttl - 1, ttl: ttl, sent: start})
+		}
+	}
+
+	replies := []traceReply{
+		{index: 0, from: "192.168.1.1", received: start.Add(time.Millisecond)},
+		{index: 5, from: "192.168.1.1", received: start.Add(2 * time.Millisecond)},
+		{index: 2, from: "10.20.0.5", received: start.Add(9 * time.Millisecond), reached: true},
+		{index: 7, from: "10.20.0.5", received: start.Add(8 * time.Millisecond), reached: true},
+		{index: 3, from: "10.20.0.5", received: start.Add(9 * time.Millisecond), reached: true},
+		{index: 99, from: "10.9.9.9", received: start},
+	}
+
+	result := assembleTrace("10.20.0.5", opts, probes, replies)
+	if !result.Reached || len(result.Hops) != 3 {
+		t.Fatalf("assembleTrace() reached=%v hops=%d, want reached with 3 hops", result.Reached, len(result.Hops))
+	}
+
+	first := result.Hops[0]
+	if first.Address != "192.168.1.1" || len(first.RTTs) != 2 || first.Sent != 2 {
+		t.Errorf("hop 1 = %+v", first)
+	}
+	if silent := result.Hops[1]; silent.Address != "" || silent.Lost() != 2 {
+		t.Errorf("hop 2 should be silent, got %+v", silent)
+	}
+	if last := result.Hops[2]; last.Address != "10.20.0.5" || len(last.RTTs) != 2 {
+		t.Errorf("hop 3 = %+v", last)
+	}
+
+	lines := result.Lines()
+	if !strings.HasPrefix(lines[2], " 2  *  *") || !strings.Contains(lines[1], "1.000 ms") {
+		t.Errorf("unexpected trace lines:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestTopology(t *testing.T) {
+	hop := func(ttl int, address string) TraceHop {
+		return TraceHop{TTL: ttl, Address: address}
+	}
+	traces := []*TraceResult{
+		{Target: "10.20.0.5", Hops: []TraceHop{hop(1, "192.168.1.1"), hop(2, "10.0.0.1"), hop(3, "10.20.0.5")}},
+		{Target: "10.20.0.12", Hops: []TraceHop{hop(1, "192.168.1.1"), hop(2, "10.0.0.1"), hop(3, "10.20.0.12")}},
+		{Target: "10.30.0.9", Hops: []TraceHop{hop(1, "192.168.1.1"), hop(2, ""), hop(3, "10.30.0.9")}},
+	}
+
+	root := buildTopology(traces)
+	if root.HostCount() != 3 || len(root.Children) != 1 {
+		t.Fatalf("expected one shared first hop for 3 hosts, got %+v", root)
+	}
+
+	want := []string{
+		"this host [3 hosts]",
+		"└─ 192.168.1.1 [3 hosts]",
+		"   ├─ * [1 host]",
+		"   │  └─ 10.30.0.9",
+		"   └─ 10.0.0.1 [2 hosts]",
+		"      ├─ 10.20.0.5",
+		"      └─ 10.20.0.12",
+	}
+	if got := renderTopology(root); !slices.Equal(got, want) {
+		t.Errorf("renderTopology() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTraceLoopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping raw socket test in short mode")
+	}
+
+	for _, method := range traceMethods {
+		t.Run(method, func(t *testing.T) {
+			result, err := traceRoute(t.Context(), "127.0.0.1", TraceOptions{Method: method, MaxHops: 3, Probes: 1, Timeout: 300 * time.Millisecond})
+			if err != nil {
+				t.Skipf("traceroute unavailable: %v", err)
+			}
+			if !result.Reached || len(result.Hops) != 1 || result.Hops[0].Address != "127.0.0.1" {
+				t.Errorf("traceRoute(127.0.0.1) = %+v", result)
+			}
+		})
+	}
+}
+
+func TestTraceComponent(t *testing.T) {
+	model := NewModularUI("10.20.0.0/24", 1, 1024, 200, false, "", nil, false)
+	model.state = stateComplete
+	model.results = []*HostInfo{{IP: "10.20.0.5"}, {IP: "10.20.0.12"}}
+
+	if host := selectedHost(model.UIModel); host == nil || host.IP != "10.20.0.5" {
+		t.Fatalf("selectedHost() = %+v", host)
+	}
+
+	component := NewTraceComponent()
+	component.Start(model.UIModel, "10.20.0.12")
+	if !model.traceVisible || !model.tracing || model.traceMethod != traceUDP {
+		t.Fatalf("Start() did not open the trace pane: %+v", model.UIModel)
+	}
+
+	component.Update(traceResultMsg{target: "10.20.0.5", result: &TraceResult{Target: "10.20.0.5"}}, model.UIModel)
+	if !model.tracing || len(model.traces) != 0 {
+		t.Error("results for another target should be ignored")
+	}
+
+	result := &TraceResult{Target: "10.20.0.12", Method: traceUDP, Reached: true, Hops: []TraceHop{{TTL: 1, Address: "10.20.0.12", Sent: 3}}}
+	component.Update(traceResultMsg{target: "10.20.0.12", result: result}, model.UIModel)
+	if model.tracing || model.traces["10.20.0.12"] != result || !slices.Equal(model.traceOrder, []string{"10.20.0.12"}) {
+		t.Errorf("trace result not stored: %+v", model.UIModel)
+	}
+	if view := component.View(model.UIModel); !strings.Contains(view, "Trace to 10.20.0.12 (udp)") {
+		t.Errorf("unexpected trace view:\n%s", view)
+	}
+
+	component.Update(tea.KeyMsg{Type: tea.KeyEsc}, model.UIModel)
+	if model.traceVisible {
+		t.Error("esc should close the trace pane")
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type hostAction struct {
+	key   key.Binding
+	label string
+	run   func(model *UIModel, host *HostInfo) tea.Cmd
+}
+
+type HostActionsComponent struct{}
+
+func NewHostActionsComponent() *HostActionsComponent {
+	return &HostActionsComponent{}
+}
+
+func openHostActions(model *UIModel) {
+	if selectedHost(model) != nil {
+		model.actionsVisible = true
+		model.actionCursor = 0
+	}
+}
+
+func hostActions(host *HostInfo) []hostAction {
+	actions := []hostAction{
+		{key: keys.HostRescan, label: "Rescan with the current scan options", run: func(model *UIModel, host *HostInfo) tea.Cmd {
+			return startHostScan(model, host, false)
+		}},
+		{key: keys.DeepScan, label: "Deep scan all ports (1-65535) with service detection", run: func(model *UIModel, host *HostInfo) tea.Cmd {
+			return startHostScan(model, host, true)
+		}},
+		{key: keys.CopyIP, label: "Copy IP address (" + host.IP + ")", run: func(model *UIModel, host *HostInfo) tea.Cmd {
+			return copyCmd("IP address", host.IP)
+		}},
+	}
+	if host.MAC != "" {
+		actions = append(actions, hostAction{key: keys.CopyMAC, label: "Copy MAC address (" + host.MAC + ")", run: func(model *UIModel, host *HostInfo) tea.Cmd {
+			return copyCmd("MAC address", host.MAC)
+		}})
+	}
+	if host.Hostname != "" {
+		actions = append(actions, hostAction{key: keys.CopyHostname, label: "Copy hostname (" + host.Hostname + ")", run: func(model *UIModel, host *HostInfo) tea.Cmd {
+			return copyCmd("hostname", host.Hostname)
+		}})
+	}
+	for i, url := range hostURLs(host) {
+		if i == 9 {
+			break
+		}
+		actions = append(actions, hostAction{key: key.NewBinding(key.WithKeys(strconv.Itoa(i+1)), key.WithHelp(strconv.Itoa(i+1), "open URL")), label: "Open " + url, run: func(model *UIModel, host *HostInfo) tea.Cmd {
+			return func() tea.Msg {
+				if err := openBrowser(url); err != nil {
+					return statusMsg(fmt.Sprintf("❌ Could not open %s: %v", url, err))
+				}
+				return statusMsg("🌐 Opened " + url)
+			}
+		}})
+	}
+	filename := hostExportFilename(host)
+	actions = append(actions, hostAction{key: keys.ExportHost, label: "Export this host to " + filename, run: func(model *UIModel, host *HostInfo) tea.Cmd {
+		return func() tea.Msg {
+			if err := exportToCSV(filename, []*HostInfo{host}); err != nil {
+				return statusMsg(fmt.Sprintf("❌ Error exporting %s: %v", host.IP, err))
+			}
+			return statusMsg(fmt.Sprintf("✅ %s exported to %s", host.IP, filename))
+		}
+	}})
+	return actions
+}
+
+func copyCmd(what, value string) tea.Cmd {
+	return func() tea.Msg {
+		if err := copyToClipboard(value); err != nil {
+			return statusMsg(fmt.Sprintf("❌ Could not copy %s: %v", what, err))
+		}
+		return statusMsg(fmt.Sprintf("📋 Copied %s %s to the clipboard", what, value))
+	}
+}
+
+func startHostScan(model *UIModel, host *HostInfo, deep bool) tea.Cmd {
+	if model.state != stateComplete {
+		model.status = "⚠️  Wait for the scan to finish before rescanning a single host"
+		return nil
+	}
+
+	tab := model.tabs[model.activeTab]
+	if _, running := tab.hostScans[host.IP]; running {
+		model.status = fmt.Sprintf("⚠️  A scan of %s is already running", host.IP)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tab.hostScans[host.IP] = cancel
+	options := currentScanOptions(model)
+	model.status = fmt.Sprintf("⏳ %s of %s running...", hostScanLabel(deep), host.IP)
+	return func() tea.Msg {
+		return hostScanMsg{tab: tab, host: host, result: scanSingleHost(ctx, host.IP, options, deep), deep: deep}
+	}
+}
+
+func finishHostScan(model *UIModel, msg hostScanMsg) bool {
+	if cancel, ok := msg.tab.hostScans[msg.host.IP]; ok {
+		cancel()
+		delete(msg.tab.hostScans, msg.host.IP)
+	}
+	return slices.Contains(model.tabs, msg.tab)
+}
+
+func hostScanLabel(deep bool) string {
+	if deep {
+		return "Deep scan"
+	}
+	return "Rescan"
+}
+
+func applyHostScan(model *UIModel, msg hostScanMsg) string {
+	if !msg.result.IsReachable {
+		return fmt.Sprintf("⚠️  %s of %s: host did not respond, keeping previous results", hostScanLabel(msg.deep), msg.host.IP)
+	}
+
+	if msg.tab != model.tabs[model.activeTab] {
+		mergeHostInfo(msg.host, msg.result)
+		return fmt.Sprintf("✅ %s of %s finished in another tab: %d open ports", hostScanLabel(msg.deep), msg.host.IP, len(msg.host.Services))
+	}
+
+	var selectedIP string
+	if host := selectedHost(model); host != nil {
+		selectedIP = host.IP
+	}
+	mergeHostInfo(msg.host, msg.result)
+	filterResults(model)
+	selectHostByIP(model, selectedIP)
+
+	return fmt.Sprintf("✅ %s of %s finished: %d open ports", hostScanLabel(msg.deep), msg.host.IP, len(msg.host.Services))
+}
+
+func (a *HostActionsComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	host := selectedHost(model)
+	if host == nil {
+		model.actionsVisible = false
+		return nil
+	}
+
+	actions := hostActions(host)
+	switch {
+	case key.Matches(keyMsg, keys.Back, keys.Actions, keys.Quit):
+		model.actionsVisible = false
+		return nil
+	case key.Matches(keyMsg, keys.Up):
+		model.actionCursor = max(model.actionCursor-1, 0)
+		return nil
+	case key.Matches(keyMsg, keys.Down):
+		model.actionCursor = min(model.actionCursor+1, len(actions)-1)
+		return nil
+	case key.Matches(keyMsg, keys.Confirm):
+		model.actionsVisible = false
+		return actions[min(model.actionCursor, len(actions)-1)].run(model, host)
+	}
+
+	for _, action := range actions {
+		if key.Matches(keyMsg, action.key) {
+			model.actionsVisible = false
+			return action.run(model, host)
+		}
+	}
+	return nil
+}
+
+func (a *HostActionsComponent) View(model *UIModel) string {
+	host := selectedHost(model)
+	if host == nil {
+		return "🔍 No host selected."
+	}
+
+	title := "⚡ Actions for " + host.IP
+	if host.Hostname != "" {
+		title += " (" + host.Hostname + ")"
+	}
+	lines := []string{title + ":", ""}
+	for i, action := range hostActions(host) {
+		line := fmt.Sprintf("  [%s] %s", action.key.Help().Key, action.label)
+		if i == model.actionCursor {
+			line = selectedRowStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if model.state != stateComplete {
+		lines = append(lines, "", contextStyle.Render("Rescans are available once the scan has finished"))
+	}
+	return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(lines, "\n"))
+}
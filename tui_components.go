@@ -5,6 +5,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbletea"
@@ -34,7 +36,9 @@ func (h *HeaderComponent) View(model *UIModel) string {
 	content = append(content, title)
 
 	var header string
-	if model.ipsOnly {
+	if model.state == stateSelectInterface {
+		header = headerStyle.Render(fmt.Sprintf("Target: choose an interface | Timeout: %dms", model.timeout))
+	} else if model.ipsOnly {
 		header = headerStyle.Render(fmt.Sprintf(
 			"Target: %s | Mode: IP Discovery Only | Timeout: %dms",
 			model.targetSubnet, model.timeout,
@@ -60,12 +64,20 @@ func (h *HeaderComponent) View(model *UIModel) string {
 			model.targetSubnet, model.startPort, model.endPort, model.timeout,
 		))
 	}
+	if activeProxy != nil {
+		content = append(content, contextStyle.Render(fmt.Sprintf("🧦 Proxy: %s | Discovery: %s", activeProxy, model.discovery)))
+	} else if netContext := getNetworkContext(); netContext != nil {
+		content = append(content, contextStyle.Render("🌐 "+netContext.Summary()))
+	}
 	content = append(content, header)
 
 	return lipgloss.JoinVertical(lipgloss.Left, content...)
 }
 
 func (h *HeaderComponent) Height() int {
+	if activeProxy != nil || getNetworkContext() != nil {
+		return 3
+	}
 	return 2
 }
 
@@ -79,17 +91,17 @@ func (s *SearchComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if model.searchFocused {
-			switch msg.String() {
-			case "esc":
+			switch {
+			case key.Matches(msg, keys.SearchCancel):
 				model.searchFocused = false
 				model.searchInput.Blur()
 				return nil
-			case "enter":
+			case key.Matches(msg, keys.SearchConfirm):
 				model.searchFocused = false
 				model.searchInput.Blur()
 				filterResults(model)
 				return nil
-			case "ctrl+f", "alt+f":
+			case key.Matches(msg, keys.SearchMode):
 				model.searchOnlyMode = !model.searchOnlyMode
 				filterResults(model)
 				return nil
@@ -118,13 +130,16 @@ func (s *SearchComponent) View(model *UIModel) string {
 		if model.searchOnlyMode {
 			searchView += " [FOCUSED MODE] (Press '/' to search by IP or vendor only)"
 		} else {
-			searchView += " (Press '/' to search all fields, Ctrl+F for focused mode, or use -focused flag)"
+			searchView += " (Press '/' to search, e.g. port:22 vendor:apple !port:23, Ctrl+F for focused mode)"
 		}
 	} else if !model.searchFocused && model.searchInput.Value() != "" {
 		if model.searchOnlyMode {
 			searchView += " [FOCUSED MODE - showing only IP/vendor matches]"
 		}
 	}
+	if model.searchErr != nil {
+		searchView += " " + searchErrorStyle.Render("⚠️  "+model.searchErr.Error())
+	}
 	return searchView
 }
 
@@ -253,7 +268,7 @@ func (s *SummaryComponent) View(model *UIModel) string {
 	}
 
 	summaryHeader := ""
-	if len(model.filteredResults) > 0 && len(model.filteredResults) < len(model.results) {
+	if model.filterActive {
 		filteredPorts := 0
 		for _, host := range model.filteredResults {
 			filteredPorts += len(host.Services)
@@ -292,10 +307,12 @@ func (s *SummaryComponent) Height() int {
 	return 4
 }
 
-type HelpComponent struct{}
+type HelpComponent struct {
+	keys help.Model
+}
 
 func NewHelpComponent() *HelpComponent {
-	return &HelpComponent{}
+	return &HelpComponent{keys: help.New()}
 }
 
 func (h *HelpComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
@@ -303,11 +320,25 @@ func (h *HelpComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
 }
 
 func (h *HelpComponent) View(model *UIModel) string {
-	if model.state == stateScanning {
-		return "💡 Press 'q' or 'Ctrl+C' to quit"
-	} else {
-		return "💡 Navigation: ↑/↓ or j/k to scroll | Page Up/Down | Home/End | / to search | ESC to clear | 'r' to rescan | 'q' to exit"
+	h.keys.Width = max(model.windowWidth-3, 20)
+	return "💡 " + h.keys.ShortHelpView(keys.shortHelp(model))
+}
+
+func (h *HelpComponent) Overlay(model *UIModel) string {
+	h.keys.Width = 0
+	current := helpContext(model)
+	lines := []string{fmt.Sprintf("⌨️  Key bindings (%s or %s to close)", keys.Help.Help().Key, keys.ClearSearch.Help().Key)}
+	for _, section := range keys.helpSections() {
+		title := "  " + section.title
+		if section.title == current {
+			title = selectedRowStyle.Render("▸ " + section.title + " (current)")
+		}
+		lines = append(lines, "", detailLabelStyle.Render(title), h.keys.FullHelpView(section.columns))
+	}
+	if path := defaultKeyMapPath(); path != "" {
+		lines = append(lines, "", contextStyle.Render("Override bindings in "+path+" or with -keys file.json"))
 	}
+	return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(lines, "\n"))
 }
 
 func (h *HelpComponent) Height() int {
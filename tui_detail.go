@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type DetailComponent struct{}
+
+func NewDetailComponent() *DetailComponent {
+	return &DetailComponent{}
+}
+
+func (d *DetailComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	pageSize := max(model.viewHeight/2, 1)
+	switch {
+	case key.Matches(keyMsg, keys.CloseDetail):
+		model.detailVisible = false
+	case key.Matches(keyMsg, keys.Actions):
+		openHostActions(model)
+	case key.Matches(keyMsg, keys.Up):
+		model.detailScroll--
+	case key.Matches(keyMsg, keys.Down):
+		model.detailScroll++
+	case key.Matches(keyMsg, keys.PageUp):
+		model.detailScroll -= pageSize
+	case key.Matches(keyMsg, keys.PageDown):
+		model.detailScroll += pageSize
+	case key.Matches(keyMsg, keys.Home):
+		model.detailScroll = 0
+	case key.Matches(keyMsg, keys.PrevHost):
+		model.cursor--
+		model.detailScroll = 0
+		keepCursorVisible(model)
+	case key.Matches(keyMsg, keys.NextHost):
+		model.cursor++
+		model.detailScroll = 0
+		keepCursorVisible(model)
+	}
+	model.detailScroll = max(model.detailScroll, 0)
+	return nil
+}
+
+func (d *DetailComponent) View(model *UIModel) string {
+	host := selectedHost(model)
+	if host == nil {
+		return "🔍 No host selected."
+	}
+
+	lines := hostDetailLines(host, max(model.windowWidth-8, 40))
+
+	visible := max(model.viewHeight, 5)
+	maxScroll := max(len(lines)-visible, 0)
+	start := min(model.detailScroll, maxScroll)
+	end := min(start+visible, len(lines))
+
+	content := lines[start:end]
+	if len(lines) > visible {
+		content = append(content, lipgloss.NewStyle().Foreground(lipgloss.Color("240")).
+			Render(fmt.Sprintf("📜 Lines %d-%d of %d", start+1, end, len(lines))))
+	}
+	return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(content, "\n"))
+}
+
+func detailField(label, value string) string {
+	return detailLabelStyle.Render(fmt.Sprintf("%-12s", label)) + " " + value
+}
+
+func formatSeen(t time.Time) string {
+	if t.IsZero() {
+		return "N/A"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func hostDetailLines(host *HostInfo, width int) []string {
+	title := fmt.Sprintf("🖥️  %s", host.IP)
+	if host.Hostname != "" {
+		title += fmt.Sprintf(" (%s)", host.Hostname)
+	}
+	if role := getNetworkContext().Role(host.IP); role != "" {
+		title += fmt.Sprintf(" [%s]", role)
+	}
+	lines := []string{headerStyle.UnsetMarginBottom().Render(title), ""}
+
+	status := "down"
+	if host.IsReachable {
+		status = fmt.Sprintf("up, %v", host.ResponseTime.Round(time.Microsecond))
+	}
+	if host.DiscoveryMethod != "" {
+		status += " via " + host.DiscoveryMethod
+	}
+	if host.TTL > 0 {
+		status += fmt.Sprintf(", TTL %d", host.TTL)
+	}
+	lines = append(lines, detailField("Status", status))
+
+	mac := host.MAC
+	if mac == "" {
+		mac = "N/A"
+	}
+	if host.Vendor != "" && host.Vendor != "Unknown" {
+		mac += fmt.Sprintf(" (%s)", host.Vendor)
+	}
+	lines = append(lines, detailField("MAC", mac))
+
+	if host.DeviceType != "" {
+		lines = append(lines, detailField("Device", fmt.Sprintf("%s %s (%d%%)", deviceTypeIcon(host.DeviceType), deviceTypeLabel(host.DeviceType), host.DeviceConfidence)))
+	}
+	if host.OSFamily != "" {
+		osInfo := fmt.Sprintf("%s (%d%%)", host.OSFamily, host.OSConfidence)
+		if fp := hostTCPFingerprint(host); fp != nil {
+			osInfo += fmt.Sprintf(" - TTL %d, window %d, options %s", fp.TTL, fp.Window, fp.Options)
+		}
+		lines = append(lines, detailField("OS", osInfo))
+	}
+	lines = append(lines, detailField("First seen", formatSeen(host.FirstSeen)))
+	lines = append(lines, detailField("Last seen", formatSeen(host.LastSeen)))
+
+	if host.NetBIOS != nil {
+		lines = append(lines, detailField("NetBIOS", host.NetBIOS.Summary()))
+	}
+	if host.SMB != nil {
+		lines = append(lines, detailField("SMB", host.SMB.Summary()))
+	}
+	if host.MDNS != nil {
+		lines = append(lines, detailField("mDNS", host.MDNS.Summary()))
+		for _, service := range host.MDNS.Services {
+			lines = append(lines, strings.Repeat(" ", 13)+service.Summary())
+		}
+	}
+	if host.SNMP != nil {
+		lines = append(lines, detailField("SNMP", host.SNMP.Summary()))
+	}
+	if host.UPnP != nil {
+		lines = append(lines, detailField("UPnP", host.UPnP.Summary()))
+		if len(host.UPnP.Services) > 0 {
+			lines = append(lines, strings.Repeat(" ", 13)+strings.Join(host.UPnP.Services, ", "))
+		}
+	}
+
+	lines = append(lines, "", detailLabelStyle.Render(fmt.Sprintf("Services (%d open)", len(host.Services))))
+	if len(host.Services) == 0 {
+		lines = append(lines, "  none")
+	}
+
+	wrap := lipgloss.NewStyle().Width(max(width-6, 20))
+	for _, service := range host.Services {
+		protocol := strings.ToLower(service.Protocol)
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		name := service.Service
+		if name == "" {
+			name = "unknown"
+		}
+		serviceLine := fmt.Sprintf("  🔓 %d/%s  %s", service.Port, protocol, name)
+		if product := strings.TrimSpace(service.Product + " " + service.Version); product != "" {
+			serviceLine += "  " + product
+		}
+		if service.ExtraInfo != "" {
+			serviceLine += fmt.Sprintf(" (%s)", service.ExtraInfo)
+		}
+		if service.ResponseTime > 0 {
+			serviceLine += fmt.Sprintf("  %v", service.ResponseTime.Round(time.Microsecond))
+		}
+		lines = append(lines, openPortStyle.Render(serviceLine))
+
+		if service.Banner != "" {
+			for i, line := range strings.Split(wrap.Render(service.Banner), "\n") {
+				prefix := strings.Repeat(" ", 13)
+				if i == 0 {
+					prefix = "     Banner: "
+				}
+				lines = append(lines, prefix+strings.TrimRight(line, " "))
+			}
+		}
+		if service.TLS != nil {
+			lines = append(lines, fmt.Sprintf("     🔒 %s", service.TLS.Summary()))
+		}
+		if service.HTTP != nil {
+			lines = append(lines, fmt.Sprintf("     🌐 %s", service.HTTP.Summary()))
+		}
+		if service.SSH != nil {
+			lines = append(lines, fmt.Sprintf("     🔑 %s", service.SSH.Summary()))
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+func TestTableCursor(t *testing.T) {
+	model := NewModularUI("10.0.0.0/24", 1, 1024, 200, false, "", nil, false)
+	model.state = stateComplete
+	model.viewHeight = 4
+	model.windowWidth = 120
+	for i := 1; i <= 10; i++ {
+		model.results = append(model.results, &HostInfo{IP: fmt.Sprintf("10.0.0.%d", 10+i), IsReachable: true})
+	}
+
+	table := NewTableComponent()
+	for range 3 {
+		table.Update(tea.KeyMsg{Type: tea.KeyDown}, model.UIModel)
+	}
+	if model.cursor != 3 || model.scrollOffset != 2 {
+		t.Errorf("after 3 downs cursor=%d offset=%d, want 3 and 2", model.cursor, model.scrollOffset)
+	}
+
+	table.Update(tea.KeyMsg{Type: tea.KeyEnd}, model.UIModel)
+	if model.cursor != 9 || model.scrollOffset != 8 {
+		t.Errorf("end: cursor=%d offset=%d, want 9 and 8", model.cursor, model.scrollOffset)
+	}
+	table.Update(tea.KeyMsg{Type: tea.KeyDown}, model.UIModel)
+	if model.cursor != 9 {
+		t.Errorf("cursor should stop at the last row, got %d", model.cursor)
+	}
+	table.Update(tea.KeyMsg{Type: tea.KeyHome}, model.UIModel)
+	if model.cursor != 0 || model.scrollOffset != 0 {
+		t.Errorf("home: cursor=%d offset=%d", model.cursor, model.scrollOffset)
+	}
+
+	model.cursor = 4
+	selected := selectedHost(model.UIModel).IP
+	model.results = append([]*HostInfo{{IP: "10.0.0.1"}}, model.results...)
+	selectHostByIP(model.UIModel, selected)
+	if selectedHost(model.UIModel).IP != selected {
+		t.Errorf("selection should follow %s when rows are inserted above, got %s", selected, selectedHost(model.UIModel).IP)
+	}
+
+	table.Update(tea.KeyMsg{Type: tea.KeyEnter}, model.UIModel)
+	if !model.detailVisible {
+		t.Fatal("enter should open the detail pane")
+	}
+	if view := NewDetailComponent().View(model.UIModel); !strings.Contains(view, selected) {
+		t.Errorf("detail pane should show the selected host %s:\n%s", selected, view)
+	}
+}
+
+func TestHostDetailLines(t *testing.T) {
+	seen := time.Date(2026, 10, 18, 9, 30, 0, 0, time.Local)
+	host := &HostInfo{
+		IP:              "192.168.1.20",
+		Hostname:        "nas.lan",
+		MAC:             "00:11:32:aa:bb:cc",
+		Vendor:          "Synology",
+		IsReachable:     true,
+		ResponseTime:    1500 * time.Microsecond,
+		DiscoveryMethod: "tcp/22",
+		FirstSeen:       seen,
+		LastSeen:        seen.Add(4 * time.Second),
+		Services: []ServiceInfo{
+			{Port: 22, Protocol: "TCP", Service: "ssh", Product: "OpenSSH", Version: "9.6p1", Banner: "SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13", ResponseTime: 800 * time.Microsecond},
+		},
+	}
+
+	text := strings.Join(hostDetailLines(host, 120), "\n")
+	for _, want := range []string{
+		"nas.lan",
+		"via tcp/22",
+		"00:11:32:aa:bb:cc (Synology)",
+		"2026-10-18 09:30:00",
+		"2026-10-18 09:30:04",
+		"22/tcp  ssh  OpenSSH 9.6p1  800µs",
+		"Banner: SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("detail lines missing %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type ExportComponent struct{}
+
+func NewExportComponent() *ExportComponent {
+	return &ExportComponent{}
+}
+
+func searchActive(model *UIModel) bool {
+	return model.filterActive
+}
+
+func exportHosts(model *UIModel) []*HostInfo {
+	if model.exportFiltered && searchActive(model) {
+		return model.filteredResults
+	}
+	return model.results
+}
+
+func openExport(model *UIModel) {
+	input := textinput.New()
+	input.CharLimit = 200
+	input.Width = 50
+
+	model.exportInput = input
+	model.exportVisible = true
+	model.exportNaming = false
+	model.exportFiltered = searchActive(model)
+}
+
+func runExport(format exportFormat, filename string, hosts []*HostInfo) tea.Cmd {
+	return func() tea.Msg {
+		if len(hosts) == 0 {
+			return statusMsg("⚠️  No results to export")
+		}
+		if err := format.write(filename, hosts); err != nil {
+			return statusMsg(fmt.Sprintf("❌ Error exporting to %s: %v", format.Name, err))
+		}
+		count := fmt.Sprintf("%d hosts", len(hosts))
+		if len(hosts) == 1 {
+			count = "1 host"
+		}
+		return statusMsg(fmt.Sprintf("✅ %s exported to %s", count, filename))
+	}
+}
+
+func (e *ExportComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	format := exportFormats[model.exportFormat]
+	if model.exportNaming {
+		switch {
+		case key.Matches(keyMsg, keys.Back):
+			model.exportNaming = false
+			model.exportInput.Blur()
+			return nil
+		case key.Matches(keyMsg, keys.Confirm):
+			filename := strings.TrimSpace(model.exportInput.Value())
+			if filename == "" {
+				filename = model.exportInput.Placeholder
+			}
+			model.exportVisible = false
+			model.exportNaming = false
+			hosts := append([]*HostInfo(nil), exportHosts(model)...)
+			sortHosts(hosts, model.sortColumn, model.sortDesc)
+			return runExport(format, filename, hosts)
+		}
+		var cmd tea.Cmd
+		model.exportInput, cmd = model.exportInput.Update(msg)
+		return cmd
+	}
+
+	switch {
+	case key.Matches(keyMsg, keys.Back, keys.Quit):
+		model.exportVisible = false
+	case key.Matches(keyMsg, keys.Up):
+		model.exportFormat = max(model.exportFormat-1, 0)
+	case key.Matches(keyMsg, keys.Down):
+		model.exportFormat = min(model.exportFormat+1, len(exportFormats)-1)
+	case key.Matches(keyMsg, keys.ToggleOption):
+		if searchActive(model) {
+			model.exportFiltered = !model.exportFiltered
+		}
+	case key.Matches(keyMsg, keys.Confirm):
+		model.exportInput.Placeholder = defaultExportFilename(format, time.Now())
+		model.exportInput.SetValue("")
+		model.exportInput.Focus()
+		model.exportNaming = true
+	}
+	return nil
+}
+
+func (e *ExportComponent) View(model *UIModel) string {
+	scope := fmt.Sprintf("all %d results", len(model.results))
+	if model.exportFiltered && searchActive(model) {
+		scope = fmt.Sprintf("%d filtered results", len(model.filteredResults))
+	}
+	lines := []string{fmt.Sprintf("💾 Export %s:", scope), ""}
+
+	if model.exportNaming {
+		format := exportFormats[model.exportFormat]
+		lines = append(lines,
+			detailField("Format", format.Name),
+			detailField("Filename", model.exportInput.View()),
+			"",
+			contextStyle.Render("Enter to save (empty uses the suggested name) | ESC to change the format"))
+		return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(lines, "\n"))
+	}
+
+	for i, format := range exportFormats {
+		line := fmt.Sprintf("  %-10s .%s", format.Name, format.Extension)
+		if i == model.exportFormat {
+			line = selectedRowStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if searchActive(model) {
+		all, filtered := "(•)", "( )"
+		if model.exportFiltered {
+			all, filtered = "( )", "(•)"
+		}
+		lines = append(lines, "",
+			fmt.Sprintf("  %s All results (%d)   %s Filtered by %q (%d)", all, len(model.results), filtered,
+				strings.TrimSpace(model.searchInput.Value()), len(model.filteredResults)))
+	}
+	return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(lines, "\n"))
+}
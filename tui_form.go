@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	formTarget = iota
+	formPorts
+	formTimeout
+	formDiscovery
+	formIPsOnly
+	formFieldCount
+)
+
+var formLabels = []string{"Target", "Ports", "Timeout (ms)", "Discovery", "IPs only"}
+
+type ScanFormComponent struct{}
+
+func NewScanFormComponent() *ScanFormComponent {
+	return &ScanFormComponent{}
+}
+
+func formatPortSpec(options ScanOptions) string {
+	if len(options.CustomPorts) == 0 {
+		return fmt.Sprintf("%d-%d", options.StartPort, options.EndPort)
+	}
+	if isCommonPortSet(options.CustomPorts) {
+		return ""
+	}
+	ports := make([]string, len(options.CustomPorts))
+	for i, port := range options.CustomPorts {
+		ports[i] = strconv.Itoa(port)
+	}
+	return strings.Join(ports, ",")
+}
+
+func isCommonPortSet(ports []int) bool {
+	if len(ports) != len(commonPorts) {
+		return false
+	}
+	for _, port := range ports {
+		if _, ok := commonPorts[port]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func openScanForm(model *UIModel) {
+	options := currentScanOptions(model)
+	values := []string{
+		strings.TrimSuffix(options.Target, "/32"),
+		formatPortSpec(options),
+		strconv.Itoa(options.Timeout),
+		options.Discovery,
+	}
+	placeholders := []string{"192.168.1.0/24 or 10.0.0.5", "common ports, 1-1024 or 22,80,443", "1000", "icmp or tcp"}
+
+	model.formInputs = make([]textinput.Model, len(values))
+	for i, value := range values {
+		input := textinput.New()
+		input.Placeholder = placeholders[i]
+		input.CharLimit = 200
+		input.Width = 40
+		input.SetValue(value)
+		model.formInputs[i] = input
+	}
+	model.formInputs[formTarget].Focus()
+	model.formFocus = formTarget
+	model.formIPsOnly = options.IPsOnly
+	model.formErr = nil
+	model.formVisible = true
+}
+
+func parseScanForm(values []string, ipsOnly bool) (ScanOptions, error) {
+	options := ScanOptions{IPsOnly: ipsOnly}
+
+	target, err := normalizeTarget(values[formTarget])
+	if err != nil {
+		return options, err
+	}
+	options.Target = target
+
+	if !ipsOnly {
+		options.StartPort, options.EndPort, options.CustomPorts, err = parsePortSpec(values[formPorts])
+		if err != nil {
+			return options, err
+		}
+	}
+
+	options.Timeout, err = strconv.Atoi(strings.TrimSpace(values[formTimeout]))
+	if err != nil || options.Timeout <= 0 {
+		return options, fmt.Errorf("invalid timeout %q (milliseconds, e.g. 1000)", values[formTimeout])
+	}
+
+	options.Discovery, _, err = chooseDiscoveryMethod(strings.ToLower(strings.TrimSpace(values[formDiscovery])))
+	return options, err
+}
+
+func (f *ScanFormComponent) focus(model *UIModel, field int) {
+	model.formFocus = (field + formFieldCount) % formFieldCount
+	for i := range model.formInputs {
+		if i == model.formFocus {
+			model.formInputs[i].Focus()
+		} else {
+			model.formInputs[i].Blur()
+		}
+	}
+}
+
+func (f *ScanFormComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, keys.Back):
+		model.formVisible = false
+		return nil
+	case key.Matches(keyMsg, keys.NextField):
+		f.focus(model, model.formFocus+1)
+		return nil
+	case key.Matches(keyMsg, keys.PrevField):
+		f.focus(model, model.formFocus-1)
+		return nil
+	case key.Matches(keyMsg, keys.Confirm):
+		values := make([]string, len(model.formInputs))
+		for i, input := range model.formInputs {
+			values[i] = input.Value()
+		}
+		options, err := parseScanForm(values, model.formIPsOnly)
+		if err != nil {
+			model.formErr = err
+			return nil
+		}
+		model.formVisible = false
+		return func() tea.Msg { return newScanMsg{options: options} }
+	}
+
+	if model.formFocus == formIPsOnly {
+		if key.Matches(keyMsg, keys.ToggleOption) {
+			model.formIPsOnly = !model.formIPsOnly
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	model.formInputs[model.formFocus], cmd = model.formInputs[model.formFocus].Update(msg)
+	model.formErr = nil
+	return cmd
+}
+
+func (f *ScanFormComponent) View(model *UIModel) string {
+	lines := []string{"🆕 New scan (runs in its own tab next to the current one):", ""}
+	for field := range formFieldCount {
+		label := detailLabelStyle.Render(fmt.Sprintf("%-13s", formLabels[field]))
+		marker := "  "
+		if field == model.formFocus {
+			marker = "▸ "
+		}
+
+		var value string
+		if field == formIPsOnly {
+			value = "[ ]"
+			if model.formIPsOnly {
+				value = "[x]"
+			}
+			if field == model.formFocus {
+				value = selectedRowStyle.Render(value)
+			}
+		} else {
+			value = model.formInputs[field].View()
+		}
+		lines = append(lines, marker+label+" "+value)
+	}
+
+	lines = append(lines, "")
+	if model.formErr != nil {
+		lines = append(lines, searchErrorStyle.Render("⚠️  "+model.formErr.Error()))
+	} else if model.formIPsOnly {
+		lines = append(lines, contextStyle.Render("Ports are ignored in IP discovery mode"))
+	}
+	return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(lines, "\n"))
+}
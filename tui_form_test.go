@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+func TestParseScanForm(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		ipsOnly bool
+		want    ScanOptions
+		wantErr string
+	}{
+		{
+			name:   "single host with port list",
+			values: []string{"10.0.0.5", "22, 80", "500", "tcp"},
+			want:   ScanOptions{Target: "10.0.0.5/32", CustomPorts: []int{22, 80}, Timeout: 500, Discovery: discoveryTCP},
+		},
+		{
+			name:   "subnet with range",
+			values: []string{"192.168.1.0/24", "1-1024", "1000", ""},
+			want:   ScanOptions{Target: "192.168.1.0/24", StartPort: 1, EndPort: 1024, Timeout: 1000, Discovery: discoveryICMP},
+		},
+		{
+			name:   "empty ports mean common ports",
+			values: []string{"10.0.0.0/30", "", "1000", "ICMP"},
+			want:   ScanOptions{Target: "10.0.0.0/30", CustomPorts: getCommonPorts(), Timeout: 1000, Discovery: discoveryICMP},
+		},
+		{
+			name:    "ips only ignores ports",
+			values:  []string{"10.0.0.0/30", "not ports", "1000", "icmp"},
+			ipsOnly: true,
+			want:    ScanOptions{Target: "10.0.0.0/30", Timeout: 1000, IPsOnly: true, Discovery: discoveryICMP},
+		},
+		{name: "bad target", values: []string{"10.0.0", "", "1000", ""}, wantErr: "invalid target"},
+		{name: "bad port", values: []string{"10.0.0.1", "22,abc", "1000", ""}, wantErr: "invalid port"},
+		{name: "reversed range", values: []string{"10.0.0.1", "1024-1", "1000", ""}, wantErr: "invalid port range"},
+		{name: "bad timeout", values: []string{"10.0.0.1", "", "0", ""}, wantErr: "invalid timeout"},
+		{name: "bad discovery", values: []string{"10.0.0.1", "", "1000", "arp"}, wantErr: "unknown discovery method"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseScanForm(tt.values, tt.ipsOnly)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Target != tt.want.Target || got.StartPort != tt.want.StartPort || got.EndPort != tt.want.EndPort ||
+				got.Timeout != tt.want.Timeout || got.IPsOnly != tt.want.IPsOnly || got.Discovery != tt.want.Discovery ||
+				!slices.Equal(slices.Sorted(slices.Values(got.CustomPorts)), slices.Sorted(slices.Values(tt.want.CustomPorts))) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanTabsKeepIndependentState(t *testing.T) {
+	model := NewModularUI("10.0.0.0/24", 1, 1024, 200, false, "", []int{22, 80}, false)
+	model.state = stateComplete
+	for i := 1; i <= 6; i++ {
+		model.results = append(model.results, &HostInfo{IP: fmt.Sprintf("10.0.0.%d", i)})
+	}
+	model.searchInput.SetValue("10.0.0")
+	filterResults(model.UIModel)
+	model.cursor = 4
+
+	openScanForm(model.UIModel)
+	if got := model.formInputs[formTarget].Value(); got != "10.0.0.0/24" {
+		t.Errorf("form should be prefilled with the current target, got %q", got)
+	}
+	if got := model.formInputs[formPorts].Value(); got != "22,80" {
+		t.Errorf("form should be prefilled with the current ports, got %q", got)
+	}
+
+	form := NewScanFormComponent()
+	form.Update(tea.KeyMsg{Type: tea.KeyEnter}, model.UIModel)
+	if model.formVisible {
+		t.Fatal("submitting a valid form should close it")
+	}
+
+	addScanTab(model.UIModel, ScanOptions{Target: "10.0.1.0/24", Timeout: 300, IPsOnly: true})
+	if len(model.tabs) != 2 || model.activeTab != 1 {
+		t.Fatalf("expected the new scan in a second tab, got %d tabs (active %d)", len(model.tabs), model.activeTab)
+	}
+	if model.targetSubnet != "10.0.1.0/24" || !model.ipsOnly || model.state != stateScanning || len(model.results) != 0 {
+		t.Errorf("new tab should be a fresh scan of 10.0.1.0/24, got %s ipsOnly=%v state=%v results=%d",
+			model.targetSubnet, model.ipsOnly, model.state, len(model.results))
+	}
+	if model.searchInput.Value() != "" || model.cursor != 0 {
+		t.Errorf("new tab should start with an empty search and cursor, got %q at %d", model.searchInput.Value(), model.cursor)
+	}
+	if model.tabs[0].scan == model.tabs[1].scan {
+		t.Error("each tab needs its own scan state")
+	}
+
+	model.results = []*HostInfo{{IP: "10.0.1.7"}}
+	model.searchInput.SetValue("lab")
+	switchTab(model.UIModel, 0)
+	if model.targetSubnet != "10.0.0.0/24" || len(model.results) != 6 || model.state != stateComplete {
+		t.Errorf("first tab should show its own scan, got %s with %d results", model.targetSubnet, len(model.results))
+	}
+	if model.searchInput.Value() != "10.0.0" || model.cursor != 4 {
+		t.Errorf("first tab should keep its search and cursor, got %q at %d", model.searchInput.Value(), model.cursor)
+	}
+
+	switchTab(model.UIModel, 1)
+	if len(model.results) != 1 || model.results[0].IP != "10.0.1.7" || model.searchInput.Value() != "lab" {
+		t.Errorf("switching back should restore the second tab, got %d results and search %q", len(model.results), model.searchInput.Value())
+	}
+
+	closeTab(model.UIModel)
+	if len(model.tabs) != 1 || model.targetSubnet != "10.0.0.0/24" {
+		t.Errorf("closing the second tab should leave the first, got %d tabs showing %s", len(model.tabs), model.targetSubnet)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const groupBarWidth = 30
+
+type GroupComponent struct{}
+
+func NewGroupComponent() *GroupComponent {
+	return &GroupComponent{}
+}
+
+func nextGroupMode(mode string) string {
+	switch mode {
+	case "":
+		return groupByVendor
+	case groupByVendor:
+		return groupByDevice
+	default:
+		return ""
+	}
+}
+
+func (g *GroupComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	groups := groupHosts(visibleResults(model), model.groupBy)
+	switch {
+	case key.Matches(keyMsg, keys.Back):
+		model.groupBy = ""
+		return nil
+	case key.Matches(keyMsg, keys.Group):
+		model.groupBy = nextGroupMode(model.groupBy)
+		model.groupCursor = 0
+		model.groupExpanded = nil
+		return nil
+	case key.Matches(keyMsg, keys.Up):
+		model.groupCursor--
+	case key.Matches(keyMsg, keys.Down):
+		model.groupCursor++
+	case key.Matches(keyMsg, keys.Home):
+		model.groupCursor = 0
+	case key.Matches(keyMsg, keys.End):
+		model.groupCursor = len(groups) - 1
+	case key.Matches(keyMsg, keys.Expand):
+		if model.groupCursor < len(groups) {
+			if model.groupExpanded == nil {
+				model.groupExpanded = make(map[string]bool)
+			}
+			name := groups[model.groupCursor].Name
+			model.groupExpanded[name] = !model.groupExpanded[name]
+		}
+	case key.Matches(keyMsg, keys.ExpandAll):
+		allExpanded := true
+		for _, group := range groups {
+			allExpanded = allExpanded && model.groupExpanded[group.Name]
+		}
+		model.groupExpanded = make(map[string]bool)
+		for _, group := range groups {
+			model.groupExpanded[group.Name] = !allExpanded
+		}
+	}
+	model.groupCursor = min(max(model.groupCursor, 0), max(len(groups)-1, 0))
+	return nil
+}
+
+func groupBar(count, largest int) string {
+	if largest == 0 {
+		return ""
+	}
+	return strings.Repeat("█", max(count*groupBarWidth/largest, 1))
+}
+
+func (g *GroupComponent) View(model *UIModel) string {
+	hosts := visibleResults(model)
+	groups := groupHosts(hosts, model.groupBy)
+	if len(groups) == 0 {
+		return "🔍 No hosts to group."
+	}
+
+	nameWidth := 12
+	for _, group := range groups {
+		nameWidth = max(nameWidth, lipgloss.Width(group.Icon+" "+group.Name))
+	}
+	nameWidth = min(nameWidth, 32)
+
+	lines := []string{fmt.Sprintf("🏷️  Hosts by %s (%d groups, %d hosts):", model.groupBy, len(groups), len(hosts))}
+	largest := len(groups[0].Hosts)
+	cursorLine := 0
+	for i, group := range groups {
+		marker := "▸"
+		if model.groupExpanded[group.Name] {
+			marker = "▾"
+		}
+		name := group.Name
+		if group.Icon != "" {
+			name = group.Icon + " " + name
+		}
+		if runes := []rune(name); lipgloss.Width(name) > nameWidth {
+			name = string(runes[:min(len(runes), nameWidth-3)]) + "..."
+		}
+		name += strings.Repeat(" ", max(nameWidth-lipgloss.Width(name), 0))
+
+		line := fmt.Sprintf("%s %s %4d %s", marker, name, len(group.Hosts), openPortStyle.Render(groupBar(len(group.Hosts), largest)))
+		if i == model.groupCursor {
+			line = selectedRowStyle.Render(line)
+			cursorLine = len(lines)
+		}
+		lines = append(lines, line)
+
+		if !model.groupExpanded[group.Name] {
+			continue
+		}
+		for _, host := range group.Hosts {
+			detail := host.Hostname
+			if model.groupBy == groupByDevice && host.Vendor != "" {
+				detail = strings.TrimSpace(host.Vendor + " " + host.Hostname)
+			} else if model.groupBy == groupByVendor && host.DeviceType != "" {
+				detail = strings.TrimSpace(deviceTypeLabel(host.DeviceType) + " " + host.Hostname)
+			}
+			hostLine := fmt.Sprintf("    %-16s %-18s %s", host.IP, host.MAC, detail)
+			if len(host.Services) > 0 {
+				hostLine += fmt.Sprintf(" (%d open)", len(host.Services))
+			}
+			lines = append(lines, strings.TrimRight(hostLine, " "))
+		}
+	}
+
+	visible := max(model.viewHeight, 5)
+	start := 0
+	if cursorLine >= visible {
+		start = cursorLine - visible + 1
+	}
+	end := min(start+visible, len(lines))
+	return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(lines[start:end], "\n"))
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type InterfacePickerComponent struct{}
+
+func NewInterfacePickerComponent() *InterfacePickerComponent {
+	return &InterfacePickerComponent{}
+}
+
+func (p *InterfacePickerComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || len(model.interfaces) == 0 {
+		return nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, keys.Up):
+		if model.interfaceCursor > 0 {
+			model.interfaceCursor--
+		}
+	case key.Matches(keyMsg, keys.Down):
+		if model.interfaceCursor < len(model.interfaces)-1 {
+			model.interfaceCursor++
+		}
+	case key.Matches(keyMsg, keys.Confirm):
+		choice := model.interfaces[model.interfaceCursor]
+		if err := selectInterface(choice.Name); err != nil {
+			model.err = err
+			return nil
+		}
+		model.targetSubnet = choice.Subnet
+		model.state = stateScanning
+		return func() tea.Msg { return interfaceSelectedMsg{} }
+	}
+	return nil
+}
+
+func (p *InterfacePickerComponent) View(model *UIModel) string {
+	var lines []string
+	lines = append(lines, "Several networks are available. Choose the interface to scan:")
+	lines = append(lines, "")
+
+	for i, choice := range model.interfaces {
+		line := fmt.Sprintf("%-12s %-16s %s", choice.Name, choice.IP, choice.Subnet)
+		if choice.Default {
+			line += "  (default route)"
+		}
+		if i == model.interfaceCursor {
+			line = openPortStyle.Render("▶ " + line)
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+
+	if model.err != nil {
+		lines = append(lines, "", fmt.Sprintf("❌ %v", model.err))
+	}
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
+
+func (p *InterfacePickerComponent) Height() int {
+	return -1
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+func TestInterfacePickerNavigation(t *testing.T) {
+	model := NewModularUI("", 1, 1024, 200, false, "", nil, false)
+	model.promptInterface([]InterfaceSubnet{
+		{Name: "viewnet-missing0", IP: "192.168.1.10", Subnet: "192.168.1.0/24", Default: true},
+		{Name: "viewnet-missing1", IP: "172.17.0.1", Subnet: "172.17.0.0/16"},
+	})
+
+	if model.state != stateSelectInterface || model.interfaceCursor != 0 {
+		t.Fatalf("expected picker on default interface, got state %d cursor %d", model.state, model.interfaceCursor)
+	}
+
+	picker := NewInterfacePickerComponent()
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyDown}} {
+		picker.Update(key, model.UIModel)
+	}
+	if model.interfaceCursor != 1 {
+		t.Errorf("cursor should stop at last interface, got %d", model.interfaceCursor)
+	}
+	picker.Update(tea.KeyMsg{Type: tea.KeyUp}, model.UIModel)
+	if model.interfaceCursor != 0 {
+		t.Errorf("cursor should move up, got %d", model.interfaceCursor)
+	}
+
+	if cmd := picker.Update(tea.KeyMsg{Type: tea.KeyEnter}, model.UIModel); cmd != nil {
+		t.Error("selecting a missing interface should not start a scan")
+	}
+	if model.err == nil || model.state != stateSelectInterface || model.targetSubnet != "" {
+		t.Errorf("expected error and unchanged picker, got err %v state %d target %q", model.err, model.state, model.targetSubnet)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -21,6 +22,15 @@ type ModularUIModel struct {
 	summary  *SummaryComponent
 	table    *TableComponent
 	help     *HelpComponent
+	picker   *InterfacePickerComponent
+	trace    *TraceComponent
+	detail   *DetailComponent
+	ports    *PortPivotComponent
+	groups   *GroupComponent
+	tabBar   *TabsComponent
+	form     *ScanFormComponent
+	actions  *HostActionsComponent
+	export   *ExportComponent
 }
 
 func NewModularUI(targetSubnet string, startPort, endPort, timeout int, focusedSearch bool, initialSearch string, customPorts []int, ipsOnly bool) *ModularUIModel {
@@ -48,6 +58,8 @@ func NewModularUI(targetSubnet string, startPort, endPort, timeout int, focusedS
 		timeout:        timeout,
 		customPorts:    customPorts,
 		ipsOnly:        ipsOnly,
+		discovery:      discoveryMethod,
+		tabs:           []*scanTab{newScanTab(ScanOptions{})},
 		viewHeight:     20,
 		windowWidth:    80,
 		windowHeight:   24,
@@ -63,20 +75,70 @@ func NewModularUI(targetSubnet string, startPort, endPort, timeout int, focusedS
 		summary:  NewSummaryComponent(),
 		table:    NewTableComponent(),
 		help:     NewHelpComponent(),
+		picker:   NewInterfacePickerComponent(),
+		trace:    NewTraceComponent(),
+		detail:   NewDetailComponent(),
+		ports:    NewPortPivotComponent(),
+		groups:   NewGroupComponent(),
+		tabBar:   NewTabsComponent(),
+		form:     NewScanFormComponent(),
+		actions:  NewHostActionsComponent(),
+		export:   NewExportComponent(),
 	}
 }
 
+func (m *ModularUIModel) promptInterface(choices []InterfaceSubnet) {
+	m.interfaces = choices
+	m.interfaceCursor = 0
+	for i, choice := range choices {
+		if choice.Default {
+			m.interfaceCursor = i
+		}
+	}
+	m.state = stateSelectInterface
+}
+
 func (m *ModularUIModel) Init() tea.Cmd {
-	StartTUIScan(m.targetSubnet, m.startPort, m.endPort, time.Duration(m.timeout)*time.Millisecond, m.customPorts, m.ipsOnly)
+	if m.state == stateSelectInterface {
+		return tea.WindowSize()
+	}
+	return m.startScan()
+}
+
+func (m *ModularUIModel) startScan() tea.Cmd {
+	tab := m.tabs[m.activeTab]
+	tab.options = currentScanOptions(m.UIModel)
+	tab.scan.Start(tab.options)
 	return tea.Batch(
 		m.spinner.Tick,
 		m.UIModel.progress.Init(),
-		pollForUpdates(),
+		m.startPolling(),
 		animateProgress(),
 		tea.WindowSize(),
 	)
 }
 
+func (m *ModularUIModel) restartScan() tea.Cmd {
+	m.state = stateScanning
+	m.results = []*HostInfo{}
+	m.filteredResults = []*HostInfo{}
+	m.scrollOffset = 0
+	m.cursor = 0
+	m.scanEndTime = time.Time{}
+	tab := m.tabs[m.activeTab]
+	tab.options = currentScanOptions(m.UIModel)
+	tab.scan.Start(tab.options)
+	return tea.Batch(m.startPolling(), m.spinner.Tick)
+}
+
+func (m *ModularUIModel) startPolling() tea.Cmd {
+	if m.polling {
+		return nil
+	}
+	m.polling = true
+	return pollForUpdates()
+}
+
 func (m *ModularUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
@@ -85,9 +147,9 @@ func (m *ModularUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.windowWidth = msg.Width
 		m.windowHeight = msg.Height
 
-		reservedHeight := m.header.Height() + m.help.Height() + 2
+		reservedHeight := m.header.Height() + m.help.Height() + 3
 
-		reservedHeight += m.stats.Height()
+		reservedHeight += m.stats.Height() + m.tabBar.Height()
 
 		if m.state == stateScanning {
 			reservedHeight += m.progress.Height()
@@ -107,35 +169,106 @@ func (m *ModularUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 
+		if m.state == stateSelectInterface && !key.Matches(msg, keys.Quit) {
+			return m, m.picker.Update(msg, m.UIModel)
+		}
+
+		if m.helpVisible {
+			if key.Matches(msg, keys.Help, keys.ClearSearch) {
+				m.helpVisible = false
+			} else if key.Matches(msg, keys.Quit) {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		if m.formVisible {
+			return m, m.form.Update(msg, m.UIModel)
+		}
+		if m.exportVisible && m.exportNaming {
+			return m, m.export.Update(msg, m.UIModel)
+		}
+		if !m.searchFocused && key.Matches(msg, keys.Help) {
+			m.helpVisible = true
+			return m, nil
+		}
+		if m.actionsVisible {
+			return m, m.actions.Update(msg, m.UIModel)
+		}
+		if m.exportVisible {
+			return m, m.export.Update(msg, m.UIModel)
+		}
+		if m.traceVisible && !key.Matches(msg, keys.Quit) {
+			return m, m.trace.Update(msg, m.UIModel)
+		}
+		if m.detailVisible && !key.Matches(msg, keys.Quit) {
+			return m, m.detail.Update(msg, m.UIModel)
+		}
+		if m.portView && !m.searchFocused && !key.Matches(msg, keys.Quit, keys.Search) {
+			return m, m.ports.Update(msg, m.UIModel)
+		}
+		if m.groupBy != "" && !m.searchFocused && !key.Matches(msg, keys.Quit, keys.Search) {
+			return m, m.groups.Update(msg, m.UIModel)
+		}
+
 		if !m.searchFocused {
-			switch msg.String() {
-			case "q":
+			switch {
+			case key.Matches(msg, keys.Quit):
 				m.quitting = true
 				return m, tea.Quit
-			case "r":
+			case key.Matches(msg, keys.Rescan):
+				if m.state == stateComplete {
+					return m, m.restartScan()
+				}
+				return m, nil
+			case key.Matches(msg, keys.NewScan):
+				openScanForm(m.UIModel)
+				return m, nil
+			case key.Matches(msg, keys.Actions):
+				openHostActions(m.UIModel)
+				return m, nil
+			case key.Matches(msg, keys.Export):
 				if m.state == stateComplete {
-					m.state = stateScanning
-					m.results = []*HostInfo{}
-					m.filteredResults = []*HostInfo{}
-					m.scrollOffset = 0
-					m.scanEndTime = time.Time{}
-					StartTUIScan(m.targetSubnet, m.startPort, m.endPort, time.Duration(m.timeout)*time.Millisecond, m.customPorts, m.ipsOnly)
-					return m, tea.Batch(pollForUpdates(), m.spinner.Tick)
+					openExport(m.UIModel)
 				}
 				return m, nil
-			case "/", "f":
+			case key.Matches(msg, keys.NextTab, keys.PrevTab, keys.CloseTab):
+				return m, m.tabBar.Update(msg, m.UIModel)
+			case key.Matches(msg, keys.Trace):
+				if m.state == stateComplete {
+					if host := selectedHost(m.UIModel); host != nil {
+						return m, m.trace.Start(m.UIModel, host.IP)
+					}
+				}
+				return m, nil
+			case key.Matches(msg, keys.Ports):
+				m.portView = true
+				m.portCursor = 0
+				return m, nil
+			case key.Matches(msg, keys.Group):
+				m.groupBy = groupByVendor
+				m.groupCursor = 0
+				m.groupExpanded = nil
+				return m, nil
+			case key.Matches(msg, keys.Sort):
+				cycleSortColumn(m.UIModel)
+				return m, nil
+			case key.Matches(msg, keys.ReverseSort):
+				reverseSort(m.UIModel)
+				return m, nil
+			case key.Matches(msg, keys.Search):
 				if m.state == stateComplete {
 					m.searchFocused = true
 					m.searchInput.Focus()
 					return m, nil
 				}
-			case "ctrl+f":
+			case key.Matches(msg, keys.FocusedSearch):
 				if m.state == stateComplete {
 					m.searchOnlyMode = !m.searchOnlyMode
 					filterResults(m.UIModel)
 					return m, nil
 				}
-			case "esc":
+			case key.Matches(msg, keys.ClearSearch):
 				m.searchInput.SetValue("")
 				m.searchOnlyMode = false
 				filterResults(m.UIModel)
@@ -149,9 +282,19 @@ func (m *ModularUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if cmd := m.table.Update(msg, m.UIModel); cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+		m.adjustScrollBounds()
 
 	case pollMsg:
-		progress := GetScanProgress()
+		scanning := refreshBackgroundTabs(m.UIModel)
+		if m.state != stateScanning {
+			if scanning {
+				return m, pollForUpdates()
+			}
+			m.polling = false
+			return m, nil
+		}
+		scan := activeScan(m.UIModel)
+		progress := scan.Progress()
 		m.scanInfo = progress
 		if progress.TotalHosts > 0 {
 			percentage := float64(progress.HostsScanned) / float64(progress.TotalHosts)
@@ -165,19 +308,50 @@ func (m *ModularUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.UIModel.progress.SetPercent(0.0)
 		}
-		m.results = GetScanResults()
+		var selectedIP string
+		if host := selectedHost(m.UIModel); host != nil {
+			selectedIP = host.IP
+		}
+		complete := scan.IsComplete()
+		m.results = scan.Results()
 		filterResults(m.UIModel)
+		selectHostByIP(m.UIModel, selectedIP)
 		m.adjustScrollBounds()
-		if IsScanComplete() && progress.HostsScanned >= progress.TotalHosts {
+		if complete {
 			m.state = stateComplete
 			if m.scanEndTime.IsZero() {
 				m.scanEndTime = time.Now()
 			}
+			if scanning {
+				return m, pollForUpdates()
+			}
+			m.polling = false
 			return m, nil
 		}
 
 		cmds = append(cmds, pollForUpdates())
 
+	case hostScanMsg:
+		if finishHostScan(m.UIModel, msg) {
+			m.status = applyHostScan(m.UIModel, msg)
+		}
+		return m, nil
+
+	case statusMsg:
+		m.status = string(msg)
+		return m, nil
+
+	case traceResultMsg:
+		return m, m.trace.Update(msg, m.UIModel)
+
+	case newScanMsg:
+		addScanTab(m.UIModel, msg.options)
+		return m, m.restartScan()
+
+	case interfaceSelectedMsg:
+		m.err = nil
+		return m, m.startScan()
+
 	case scanErrorMsg:
 		m.err = msg.err
 		m.quitting = true
@@ -201,6 +375,20 @@ func (m *ModularUIModel) View() string {
 	var sections []string
 
 	sections = append(sections, m.header.View(m.UIModel))
+	if tabs := m.tabBar.View(m.UIModel); tabs != "" {
+		sections = append(sections, tabs)
+	}
+
+	if m.state == stateSelectInterface {
+		sections = append(sections, m.picker.View(m.UIModel))
+		sections = append(sections, m.help.View(m.UIModel))
+		return lipgloss.JoinVertical(lipgloss.Left, sections...)
+	}
+
+	if m.helpVisible {
+		sections = append(sections, m.help.Overlay(m.UIModel))
+		return lipgloss.JoinVertical(lipgloss.Left, sections...)
+	}
 
 	if m.state == stateScanning {
 		sections = append(sections, m.progress.View(m.UIModel))
@@ -215,30 +403,31 @@ func (m *ModularUIModel) View() string {
 		sections = append(sections, m.search.View(m.UIModel))
 	}
 
-	sections = append(sections, m.table.View(m.UIModel))
+	if m.formVisible {
+		sections = append(sections, m.form.View(m.UIModel))
+	} else if m.actionsVisible {
+		sections = append(sections, m.actions.View(m.UIModel))
+	} else if m.exportVisible {
+		sections = append(sections, m.export.View(m.UIModel))
+	} else if m.traceVisible {
+		sections = append(sections, m.trace.View(m.UIModel))
+	} else if m.detailVisible {
+		sections = append(sections, m.detail.View(m.UIModel))
+	} else if m.portView {
+		sections = append(sections, m.ports.View(m.UIModel))
+	} else if m.groupBy != "" {
+		sections = append(sections, m.groups.View(m.UIModel))
+	} else {
+		sections = append(sections, m.table.View(m.UIModel))
+	}
+	if m.status != "" {
+		sections = append(sections, lipgloss.NewStyle().PaddingLeft(1).Render(m.status))
+	}
 	sections = append(sections, m.help.View(m.UIModel))
 
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
 
 func (m *ModularUIModel) adjustScrollBounds() {
-	resultsToShow := m.filteredResults
-	if len(resultsToShow) == 0 {
-		resultsToShow = m.results
-	}
-
-	if len(resultsToShow) == 0 {
-		m.scrollOffset = 0
-		return
-	}
-
-	minScroll := 0
-	maxScroll := max(len(resultsToShow)-m.viewHeight, 0)
-
-	if m.scrollOffset > maxScroll {
-		m.scrollOffset = maxScroll
-	}
-	if m.scrollOffset < minScroll {
-		m.scrollOffset = minScroll
-	}
+	keepCursorVisible(m.UIModel)
 }
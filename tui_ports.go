@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type PortPivotComponent struct{}
+
+func NewPortPivotComponent() *PortPivotComponent {
+	return &PortPivotComponent{}
+}
+
+func (p *PortPivotComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+
+	pivot := buildPortPivot(visibleResults(model))
+	switch {
+	case key.Matches(keyMsg, keys.Back, keys.Ports):
+		model.portView = false
+		return nil
+	case key.Matches(keyMsg, keys.Up):
+		model.portCursor--
+	case key.Matches(keyMsg, keys.Down):
+		model.portCursor++
+	case key.Matches(keyMsg, keys.Home):
+		model.portCursor = 0
+	case key.Matches(keyMsg, keys.End):
+		model.portCursor = len(pivot) - 1
+	case key.Matches(keyMsg, keys.Expand):
+		if model.portCursor < len(pivot) {
+			if model.portExpanded == nil {
+				model.portExpanded = make(map[int]bool)
+			}
+			port := pivot[model.portCursor].Port
+			model.portExpanded[port] = !model.portExpanded[port]
+		}
+	case key.Matches(keyMsg, keys.ExpandAll):
+		allExpanded := true
+		for _, summary := range pivot {
+			allExpanded = allExpanded && model.portExpanded[summary.Port]
+		}
+		model.portExpanded = make(map[int]bool)
+		for _, summary := range pivot {
+			model.portExpanded[summary.Port] = !allExpanded
+		}
+	}
+	model.portCursor = min(max(model.portCursor, 0), max(len(pivot)-1, 0))
+	return nil
+}
+
+func (p *PortPivotComponent) View(model *UIModel) string {
+	pivot := buildPortPivot(visibleResults(model))
+	if len(pivot) == 0 {
+		return "🔍 No open ports found."
+	}
+
+	lines := []string{fmt.Sprintf("🔌 Open ports (%d ports across %d hosts):", len(pivot), len(visibleResults(model)))}
+	cursorLine := 0
+	for i, summary := range pivot {
+		marker := "▸"
+		if model.portExpanded[summary.Port] {
+			marker = "▾"
+		}
+		count := fmt.Sprintf("%d hosts", len(summary.Hosts))
+		if len(summary.Hosts) == 1 {
+			count = "1 host"
+		}
+		line := fmt.Sprintf("%s %-32s %s", marker, summary.Label(), count)
+		if i == model.portCursor {
+			line = selectedRowStyle.Render(line)
+			cursorLine = len(lines)
+		} else {
+			line = openPortStyle.Render(line)
+		}
+		lines = append(lines, line)
+
+		if !model.portExpanded[summary.Port] {
+			continue
+		}
+		for _, host := range summary.Hosts {
+			hostLine := fmt.Sprintf("    %-16s %-24s", host.IP, host.Hostname)
+			if service := hostServiceOn(host, summary.Port); service != nil {
+				if version := serviceVersion(service); version != "" {
+					hostLine += " " + version
+				} else if service.Banner != "" {
+					hostLine += " " + service.Banner
+				}
+			}
+			lines = append(lines, strings.TrimRight(hostLine, " "))
+		}
+	}
+
+	visible := max(model.viewHeight, 5)
+	start := 0
+	if cursorLine >= visible {
+		start = cursorLine - visible + 1
+	}
+	end := min(start+visible, len(lines))
+	return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(lines[start:end], "\n"))
+}
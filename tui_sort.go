@@ -0,0 +1,124 @@
+package main
+
+import (
+	"cmp"
+	"sort"
+	"strings"
+)
+
+type sortColumn int
+
+const (
+	sortByIP sortColumn = iota
+	sortByHostname
+	sortByMAC
+	sortByVendor
+	sortByPorts
+	sortByResponse
+	sortByFirstSeen
+	sortColumnCount
+)
+
+var sortColumnNames = map[sortColumn]string{
+	sortByIP:        "IP",
+	sortByHostname:  "hostname",
+	sortByMAC:       "MAC",
+	sortByVendor:    "vendor",
+	sortByPorts:     "open ports",
+	sortByResponse:  "response time",
+	sortByFirstSeen: "first seen",
+}
+
+func (c sortColumn) String() string {
+	return sortColumnNames[c]
+}
+
+func sortIndicator(model *UIModel) string {
+	if model.sortDesc {
+		return "▼"
+	}
+	return "▲"
+}
+
+func cycleSortColumn(model *UIModel) {
+	model.sortColumn = (model.sortColumn + 1) % sortColumnCount
+	model.sortDesc = model.sortColumn == sortByPorts
+	resortResults(model)
+}
+
+func reverseSort(model *UIModel) {
+	model.sortDesc = !model.sortDesc
+	resortResults(model)
+}
+
+func resortResults(model *UIModel) {
+	var selectedIP string
+	if host := selectedHost(model); host != nil {
+		selectedIP = host.IP
+	}
+	sortHosts(model.results, model.sortColumn, model.sortDesc)
+	sortHosts(model.filteredResults, model.sortColumn, model.sortDesc)
+	selectHostByIP(model, selectedIP)
+	keepCursorVisible(model)
+}
+
+func sortHosts(hosts []*HostInfo, column sortColumn, desc bool) {
+	sort.SliceStable(hosts, func(i, j int) bool {
+		a, b := hosts[i], hosts[j]
+
+		result, missing := compareHosts(a, b, column)
+		if missing != 0 {
+			return missing < 0
+		}
+		if desc {
+			result = -result
+		}
+		if result == 0 {
+			return ipToInt(a.IP) < ipToInt(b.IP)
+		}
+		return result < 0
+	})
+}
+
+func compareHosts(a, b *HostInfo, column sortColumn) (int, int) {
+	switch column {
+	case sortByHostname:
+		return compareText(a.Hostname, b.Hostname)
+	case sortByMAC:
+		return compareText(a.MAC, b.MAC)
+	case sortByVendor:
+		vendorA, vendorB := a.Vendor, b.Vendor
+		if vendorA == "Unknown" {
+			vendorA = ""
+		}
+		if vendorB == "Unknown" {
+			vendorB = ""
+		}
+		return compareText(vendorA, vendorB)
+	case sortByPorts:
+		return cmp.Compare(len(a.Services), len(b.Services)), 0
+	case sortByResponse:
+		return cmp.Compare(a.ResponseTime, b.ResponseTime), 0
+	case sortByFirstSeen:
+		if a.FirstSeen.IsZero() != b.FirstSeen.IsZero() {
+			return 0, missingOrder(a.FirstSeen.IsZero())
+		}
+		return a.FirstSeen.Compare(b.FirstSeen), 0
+	default:
+		return cmp.Compare(ipToInt(a.IP), ipToInt(b.IP)), 0
+	}
+}
+
+func compareText(a, b string) (int, int) {
+	if (a == "") != (b == "") {
+		return 0, missingOrder(a == "")
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b)), 0
+}
+
+func missingOrder(aMissing bool) int {
+	if aMissing {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func hostIPs(hosts []*HostInfo) []string {
+	var ips []string
+	for _, host := range hosts {
+		ips = append(ips, host.IP)
+	}
+	return ips
+}
+
+func TestSortHosts(t *testing.T) {
+	base := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	newHosts := func() []*HostInfo {
+		return []*HostInfo{
+			{IP: "10.0.0.20", Hostname: "beta", MAC: "00:11:22:00:00:02", Vendor: "Cisco", ResponseTime: 3 * time.Millisecond, FirstSeen: base.Add(2 * time.Second), Services: make([]ServiceInfo, 1)},
+			{IP: "10.0.0.3", Hostname: "", MAC: "", Vendor: "Unknown", ResponseTime: time.Millisecond, Services: make([]ServiceInfo, 3)},
+			{IP: "10.0.0.100", Hostname: "Alpha", MAC: "00:11:22:00:00:01", Vendor: "apple", ResponseTime: 2 * time.Millisecond, FirstSeen: base, Services: make([]ServiceInfo, 1)},
+		}
+	}
+
+	tests := []struct {
+		column sortColumn
+		desc   bool
+		want   []string
+	}{
+		{sortByIP, false, []string{"10.0.0.3", "10.0.0.20", "10.0.0.100"}},
+		{sortByIP, true, []string{"10.0.0.100", "10.0.0.20", "10.0.0.3"}},
+		{sortByHostname, false, []string{"10.0.0.100", "10.0.0.20", "10.0.0.3"}},
+		{sortByHostname, true, []string{"10.0.0.20", "10.0.0.100", "10.0.0.3"}},
+		{sortByMAC, false, []string{"10.0.0.100", "10.0.0.20", "10.0.0.3"}},
+		{sortByVendor, false, []string{"10.0.0.100", "10.0.0.20", "10.0.0.3"}},
+		{sortByPorts, true, []string{"10.0.0.3", "10.0.0.20", "10.0.0.100"}},
+		{sortByPorts, false, []string{"10.0.0.20", "10.0.0.100", "10.0.0.3"}},
+		{sortByResponse, false, []string{"10.0.0.3", "10.0.0.100", "10.0.0.20"}},
+		{sortByFirstSeen, false, []string{"10.0.0.100", "10.0.0.20", "10.0.0.3"}},
+		{sortByFirstSeen, true, []string{"10.0.0.20", "10.0.0.100", "10.0.0.3"}},
+	}
+
+	for _, tt := range tests {
+		hosts := newHosts()
+		sortHosts(hosts, tt.column, tt.desc)
+		if got := hostIPs(hosts); !slices.Equal(got, tt.want) {
+			t.Errorf("sortHosts(%s, desc=%v) = %v, want %v", tt.column, tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestSortKeepsSelection(t *testing.T) {
+	model := NewModularUI("10.0.0.0/24", 1, 1024, 200, false, "", nil, false)
+	model.results = []*HostInfo{
+		{IP: "10.0.0.1", Services: make([]ServiceInfo, 1)},
+		{IP: "10.0.0.2", Services: make([]ServiceInfo, 4)},
+		{IP: "10.0.0.3", Services: make([]ServiceInfo, 2)},
+	}
+	model.cursor = 2
+
+	cycleSortColumn(model.UIModel)
+	if model.sortColumn != sortByHostname || model.sortDesc {
+		t.Fatalf("expected hostname ascending after one cycle, got %s desc=%v", model.sortColumn, model.sortDesc)
+	}
+	for model.sortColumn != sortByPorts {
+		cycleSortColumn(model.UIModel)
+	}
+	if !model.sortDesc || !slices.Equal(hostIPs(model.results), []string{"10.0.0.2", "10.0.0.3", "10.0.0.1"}) {
+		t.Errorf("ports sort should default to most ports first, got %v desc=%v", hostIPs(model.results), model.sortDesc)
+	}
+	if host := selectedHost(model.UIModel); host.IP != "10.0.0.3" {
+		t.Errorf("selection should stay on 10.0.0.3, got %s", host.IP)
+	}
+
+	reverseSort(model.UIModel)
+	if !slices.Equal(hostIPs(model.results), []string{"10.0.0.1", "10.0.0.3", "10.0.0.2"}) {
+		t.Errorf("reverse sort = %v", hostIPs(model.results))
+	}
+
+	model.results = append(model.results, &HostInfo{IP: "10.0.0.4", Services: make([]ServiceInfo, 2)})
+	filterResults(model.UIModel)
+	if !slices.Equal(hostIPs(model.results), []string{"10.0.0.1", "10.0.0.3", "10.0.0.4", "10.0.0.2"}) {
+		t.Errorf("streamed results should keep the active sort, got %v", hostIPs(model.results))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -19,73 +20,92 @@ func (t *TableComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if !model.searchFocused {
-			switch msg.String() {
-			case "up", "k":
-				if model.scrollOffset > 0 {
-					model.scrollOffset--
+			pageSize := max(tableRows(model)/2, 1)
+			switch {
+			case key.Matches(msg, keys.Up):
+				model.cursor--
+			case key.Matches(msg, keys.Down):
+				model.cursor++
+			case key.Matches(msg, keys.Home):
+				model.cursor = 0
+			case key.Matches(msg, keys.End):
+				model.cursor = len(visibleResults(model)) - 1
+			case key.Matches(msg, keys.PageUp):
+				model.cursor -= pageSize
+			case key.Matches(msg, keys.PageDown):
+				model.cursor += pageSize
+			case key.Matches(msg, keys.Details):
+				if selectedHost(model) != nil {
+					model.detailVisible = true
+					model.detailScroll = 0
 				}
 				return nil
-			case "down", "j":
-				resultsToShow := model.filteredResults
-				if len(resultsToShow) == 0 {
-					resultsToShow = model.results
-				}
-				if len(resultsToShow) > 0 {
-					maxScroll := max(len(resultsToShow)-model.viewHeight, 0)
-					if model.scrollOffset < maxScroll {
-						model.scrollOffset++
-					}
-				}
-				return nil
-			case "home":
-				model.scrollOffset = 0
-				return nil
-			case "end":
-				resultsToShow := model.filteredResults
-				if len(resultsToShow) == 0 {
-					resultsToShow = model.results
-				}
-				if len(resultsToShow) > 0 {
-					maxScroll := max(len(resultsToShow)-model.viewHeight, 0)
-					model.scrollOffset = maxScroll
-				}
-				return nil
-			case "pageup":
-				pageSize := max(model.viewHeight/2, 1)
-				model.scrollOffset -= pageSize
-				minScroll := -4
-				if model.scrollOffset < minScroll {
-					model.scrollOffset = minScroll
-				}
-				return nil
-			case "pagedown":
-				resultsToShow := model.filteredResults
-				if len(resultsToShow) == 0 {
-					resultsToShow = model.results
-				}
-				if len(resultsToShow) > 0 {
-					pageSize := max(model.viewHeight/2, 1)
-					maxScroll := max(len(resultsToShow)-model.viewHeight, 0)
-					model.scrollOffset += pageSize
-					if model.scrollOffset > maxScroll {
-						model.scrollOffset = maxScroll
-					}
-				}
+			default:
 				return nil
 			}
+			keepCursorVisible(model)
 		}
 	}
 	return nil
 }
 
-func (t *TableComponent) View(model *UIModel) string {
-	resultsToShow := model.filteredResults
+func visibleResults(model *UIModel) []*HostInfo {
+	if !model.filterActive {
+		return model.results
+	}
+	return model.filteredResults
+}
+
+func selectedHost(model *UIModel) *HostInfo {
+	resultsToShow := visibleResults(model)
 	if len(resultsToShow) == 0 {
-		resultsToShow = model.results
+		return nil
+	}
+	return resultsToShow[min(max(model.cursor, 0), len(resultsToShow)-1)]
+}
+
+func selectHostByIP(model *UIModel, ip string) {
+	for i, host := range visibleResults(model) {
+		if host.IP == ip {
+			model.cursor = i
+			return
+		}
+	}
+}
+
+func tableRows(model *UIModel) int {
+	rows := model.viewHeight - 1
+	if rows <= 0 {
+		rows = 5
+	}
+	if model.windowWidth > 80 {
+		rows--
+	}
+	return max(rows, 1)
+}
+
+func keepCursorVisible(model *UIModel) {
+	total := len(visibleResults(model))
+	model.cursor = min(max(model.cursor, 0), max(total-1, 0))
+
+	rows := tableRows(model)
+	if model.cursor < model.scrollOffset {
+		model.scrollOffset = model.cursor
+	} else if model.cursor >= model.scrollOffset+rows {
+		model.scrollOffset = model.cursor - rows + 1
 	}
+	model.scrollOffset = min(max(model.scrollOffset, 0), max(total-rows, 0))
+}
+
+func (t *TableComponent) View(model *UIModel) string {
+	resultsToShow := visibleResults(model)
 
 	if len(resultsToShow) == 0 {
-		if model.state == stateScanning {
+		if model.filterActive && model.searchErr != nil {
+			return "🔍 Fix the search query to filter results (ESC to clear)"
+		} else if model.filterActive {
+			return fmt.Sprintf("🔍 No hosts match %q (ESC to clear)", strings.TrimSpace(model.searchInput.Value()))
+		} else if model.state == stateScanning {
 			return "🔍 Scanning for hosts... (results will appear as they're discovered)"
 		} else {
 			return "🔍 No active hosts found."
@@ -95,13 +115,14 @@ func (t *TableComponent) View(model *UIModel) string {
 	var content []string
 
 	resultHeader := ""
-	if len(model.filteredResults) > 0 && len(model.filteredResults) < len(model.results) {
-		resultHeader = "🖥️  Filtered Host Details:"
+	if model.filterActive {
+		resultHeader = "🖥️  Filtered Host Details"
 	} else if model.state == stateScanning {
-		resultHeader = fmt.Sprintf("🖥️  Live Results (%d discovered):", len(resultsToShow))
+		resultHeader = fmt.Sprintf("🖥️  Live Results (%d discovered)", len(resultsToShow))
 	} else {
-		resultHeader = "🖥️  Host Details:"
+		resultHeader = "🖥️  Host Details"
 	}
+	resultHeader += fmt.Sprintf(" - sorted by %s %s:", model.sortColumn, sortIndicator(model))
 	content = append(content, resultHeader)
 
 	availableHeight := model.viewHeight - 1
@@ -125,7 +146,7 @@ func (t *TableComponent) renderTableRows(model *UIModel, resultsToShow []*HostIn
 	endIdx := min(startIndex+maxRows, len(resultsToShow))
 
 	if model.windowWidth > 80 {
-		headerRow := t.renderTableHeader(model.windowWidth)
+		headerRow := t.renderTableHeader(model)
 		*content = append(*content, headerRow)
 		maxRows--
 		if maxRows <= 0 {
@@ -136,7 +157,7 @@ func (t *TableComponent) renderTableRows(model *UIModel, resultsToShow []*HostIn
 
 	for i := startIndex; i < endIdx && i < len(resultsToShow); i++ {
 		host := resultsToShow[i]
-		row := t.renderTableRow(host, model.windowWidth)
+		row := t.renderTableRow(host, model.windowWidth, i == model.cursor)
 		*content = append(*content, row)
 	}
 
@@ -150,12 +171,13 @@ func (t *TableComponent) Height() int {
 	return -1
 }
 
-func (t *TableComponent) renderTableHeader(width int) string {
-	if width < 80 {
+func (t *TableComponent) renderTableHeader(model *UIModel) string {
+	if model.windowWidth < 80 {
 		return ""
 	}
 
 	ipWidth := 16
+	typeWidth := 4
 	macWidth := 18
 	vendorWidth := 22
 
@@ -167,30 +189,42 @@ func (t *TableComponent) renderTableHeader(width int) string {
 		BorderForeground(lipgloss.Color("8")).
 		Padding(0, 1)
 
-	header := fmt.Sprintf("%-*s │ %-*s │ %-*s │ %s",
-		ipWidth, "IP ADDRESS",
-		macWidth, "MAC ADDRESS",
-		vendorWidth, "VENDOR",
-		"OPEN PORTS")
+	label := func(name string, column sortColumn) string {
+		if model.sortColumn == column {
+			return name + " " + sortIndicator(model)
+		}
+		return name
+	}
+
+	header := fmt.Sprintf("%-*s │ %-*s │ %-*s │ %-*s │ %s",
+		ipWidth, label("IP ADDRESS", sortByIP),
+		typeWidth, "TYPE",
+		macWidth, label("MAC ADDRESS", sortByMAC),
+		vendorWidth, label("VENDOR", sortByVendor),
+		label("OPEN PORTS", sortByPorts))
 
 	return headerStyle.Render(header)
 }
 
-func (t *TableComponent) renderTableRow(host *HostInfo, width int) string {
+func (t *TableComponent) renderTableRow(host *HostInfo, width int, selected bool) string {
 	if width < 80 {
-		return t.renderHostCard(host)
+		return t.renderHostCard(host, selected)
 	}
 
 	ipWidth := 16
+	typeWidth := 4
 	macWidth := 18
 	vendorWidth := 22
-	portsWidth := max(width-ipWidth-macWidth-vendorWidth-6, 20)
+	portsWidth := max(width-ipWidth-typeWidth-macWidth-vendorWidth-9, 20)
 
 	ipCell := host.IP
 	if len(ipCell) > ipWidth-1 {
 		ipCell = ipCell[:ipWidth-4] + "..."
 	}
 
+	typeCell := deviceTypeIcon(host.DeviceType)
+	typeCell += strings.Repeat(" ", max(typeWidth-lipgloss.Width(typeCell), 0))
+
 	macCell := host.MAC
 	if macCell == "" {
 		macCell = "N/A"
@@ -216,12 +250,16 @@ func (t *TableComponent) renderTableRow(host *HostInfo, width int) string {
 		portList = append(portList, portStr)
 	}
 	portsCell := strings.Join(portList, ", ")
+	if role := getNetworkContext().Role(host.IP); role != "" {
+		portsCell = fmt.Sprintf("[%s] %s", role, portsCell)
+	}
 	if len(portsCell) > portsWidth-1 {
 		portsCell = portsCell[:portsWidth-4] + "..."
 	}
 
-	row := fmt.Sprintf("%-*s │ %-*s │ %-*s │ %s",
+	row := fmt.Sprintf("%-*s │ %s │ %-*s │ %-*s │ %s",
 		ipWidth, ipCell,
+		typeCell,
 		macWidth, macCell,
 		vendorWidth, vendorCell,
 		portsCell)
@@ -236,16 +274,28 @@ func (t *TableComponent) renderTableRow(host *HostInfo, width int) string {
 	} else {
 		style = style.BorderForeground(lipgloss.Color("9"))
 	}
+	if selected {
+		style = style.Inherit(selectedRowStyle)
+	}
 
 	return style.Render(row)
 }
 
-func (t *TableComponent) renderHostCard(host *HostInfo) string {
+func (t *TableComponent) renderHostCard(host *HostInfo, selected bool) string {
 	hostHeader := fmt.Sprintf("🖥️  %s", host.IP)
 	if host.Hostname != "" {
 		hostHeader += fmt.Sprintf(" (%s)", host.Hostname)
 	}
 	hostHeader += fmt.Sprintf(" - %v", host.ResponseTime.Round(time.Millisecond))
+	if host.DiscoveryMethod != "" && host.DiscoveryMethod != discoveryICMP {
+		hostHeader += " via " + host.DiscoveryMethod
+	}
+	if role := getNetworkContext().Role(host.IP); role != "" {
+		hostHeader += fmt.Sprintf(" [%s]", role)
+	}
+	if host.DeviceType != "" {
+		hostHeader += fmt.Sprintf(" %s %s (%d%%)", deviceTypeIcon(host.DeviceType), deviceTypeLabel(host.DeviceType), host.DeviceConfidence)
+	}
 
 	var macInfo string
 	if host.MAC != "" {
@@ -255,6 +305,38 @@ func (t *TableComponent) renderHostCard(host *HostInfo) string {
 		}
 	}
 
+	var identity []string
+	if host.OSFamily != "" {
+		osInfo := fmt.Sprintf("   🧬 OS: %s (%d%%)", host.OSFamily, host.OSConfidence)
+		if fp := hostTCPFingerprint(host); fp != nil {
+			osInfo += fmt.Sprintf(" - TTL %d, window %d, options %s", fp.TTL, fp.Window, fp.Options)
+		} else if host.TTL > 0 {
+			osInfo += fmt.Sprintf(" - TTL %d", host.TTL)
+		}
+		identity = append(identity, osInfo)
+	}
+	if host.NetBIOS != nil {
+		identity = append(identity, fmt.Sprintf("   🪟 %s", host.NetBIOS.Summary()))
+	}
+	if host.SMB != nil {
+		identity = append(identity, fmt.Sprintf("   📂 %s", host.SMB.Summary()))
+	}
+	if host.MDNS != nil {
+		identity = append(identity, fmt.Sprintf("   📡 %s", host.MDNS.Summary()))
+		for _, service := range host.MDNS.Services {
+			identity = append(identity, fmt.Sprintf("      %s", service.Summary()))
+		}
+	}
+	if host.SNMP != nil {
+		identity = append(identity, fmt.Sprintf("   📟 %s", host.SNMP.Summary()))
+	}
+	if host.UPnP != nil {
+		identity = append(identity, fmt.Sprintf("   📺 %s", host.UPnP.Summary()))
+		if len(host.UPnP.Services) > 0 {
+			identity = append(identity, fmt.Sprintf("      %s", strings.Join(host.UPnP.Services, ", ")))
+		}
+	}
+
 	var services []string
 	for _, service := range host.Services {
 		serviceText := fmt.Sprintf("   🔓 %d/%s", service.Port, service.Service)
@@ -265,6 +347,15 @@ func (t *TableComponent) renderHostCard(host *HostInfo) string {
 			serviceText += fmt.Sprintf(" - %s", service.Banner)
 		}
 		services = append(services, openPortStyle.Render(serviceText))
+		if service.TLS != nil {
+			services = append(services, fmt.Sprintf("      🔒 %s", service.TLS.Summary()))
+		}
+		if service.HTTP != nil {
+			services = append(services, fmt.Sprintf("      🌐 %s", service.HTTP.Summary()))
+		}
+		if service.SSH != nil {
+			services = append(services, fmt.Sprintf("      🔑 %s", service.SSH.Summary()))
+		}
 	}
 
 	var hostContent []string
@@ -272,12 +363,16 @@ func (t *TableComponent) renderHostCard(host *HostInfo) string {
 	if macInfo != "" {
 		hostContent = append(hostContent, macInfo)
 	}
+	hostContent = append(hostContent, identity...)
 	hostContent = append(hostContent, services...)
 
 	style := upHostStyle
 	if !host.IsReachable {
 		style = downHostStyle
 	}
+	if selected {
+		style = style.BorderForeground(lipgloss.Color("#7D56F4"))
+	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left, hostContent...)
 	return style.Render(content)
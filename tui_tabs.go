@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type scanTab struct {
+	scan           *ScanState
+	hostScans      map[string]context.CancelFunc
+	options        ScanOptions
+	state          scanState
+	scanInfo       ScanProgress
+	results        []*HostInfo
+	scanEndTime    time.Time
+	search         string
+	searchOnlyMode bool
+	cursor         int
+	scrollOffset   int
+}
+
+func newScanTab(options ScanOptions) *scanTab {
+	return &scanTab{scan: NewScanState(), hostScans: make(map[string]context.CancelFunc), options: options, state: stateScanning, results: []*HostInfo{}}
+}
+
+func (tab *scanTab) stop() {
+	tab.scan.Stop()
+	for _, cancel := range tab.hostScans {
+		cancel()
+	}
+}
+
+func currentScanOptions(model *UIModel) ScanOptions {
+	return ScanOptions{
+		Target:      model.targetSubnet,
+		StartPort:   model.startPort,
+		EndPort:     model.endPort,
+		CustomPorts: model.customPorts,
+		Timeout:     model.timeout,
+		IPsOnly:     model.ipsOnly,
+		Discovery:   model.discovery,
+	}
+}
+
+func applyScanOptions(model *UIModel, options ScanOptions) {
+	model.targetSubnet = options.Target
+	model.startPort = options.StartPort
+	model.endPort = options.EndPort
+	model.customPorts = options.CustomPorts
+	model.timeout = options.Timeout
+	model.ipsOnly = options.IPsOnly
+	model.discovery = options.Discovery
+}
+
+func activeScan(model *UIModel) *ScanState {
+	return model.tabs[model.activeTab].scan
+}
+
+func saveTab(model *UIModel, tab *scanTab) {
+	tab.options = currentScanOptions(model)
+	tab.state = model.state
+	tab.scanInfo = model.scanInfo
+	tab.results = model.results
+	tab.scanEndTime = model.scanEndTime
+	tab.search = model.searchInput.Value()
+	tab.searchOnlyMode = model.searchOnlyMode
+	tab.cursor = model.cursor
+	tab.scrollOffset = model.scrollOffset
+}
+
+func loadTab(model *UIModel, tab *scanTab) {
+	applyScanOptions(model, tab.options)
+	model.state = tab.state
+	model.scanInfo = tab.scanInfo
+	model.results = tab.results
+	model.scanEndTime = tab.scanEndTime
+	model.searchInput.SetValue(tab.search)
+	model.searchOnlyMode = tab.searchOnlyMode
+	model.searchFocused = false
+	model.searchInput.Blur()
+	model.detailVisible = false
+	model.portView = false
+	model.groupBy = ""
+	filterResults(model)
+	model.cursor = tab.cursor
+	model.scrollOffset = tab.scrollOffset
+	keepCursorVisible(model)
+}
+
+func switchTab(model *UIModel, index int) {
+	if len(model.tabs) < 2 {
+		return
+	}
+	index = (index + len(model.tabs)) % len(model.tabs)
+	if index == model.activeTab {
+		return
+	}
+	saveTab(model, model.tabs[model.activeTab])
+	model.activeTab = index
+	loadTab(model, model.tabs[index])
+}
+
+func addScanTab(model *UIModel, options ScanOptions) *scanTab {
+	saveTab(model, model.tabs[model.activeTab])
+	tab := newScanTab(options)
+	model.tabs = append(model.tabs, tab)
+	model.activeTab = len(model.tabs) - 1
+	loadTab(model, tab)
+	return tab
+}
+
+func closeTab(model *UIModel) {
+	if len(model.tabs) < 2 {
+		return
+	}
+	model.tabs[model.activeTab].stop()
+	model.tabs = append(model.tabs[:model.activeTab], model.tabs[model.activeTab+1:]...)
+	model.activeTab = min(model.activeTab, len(model.tabs)-1)
+	loadTab(model, model.tabs[model.activeTab])
+}
+
+func refreshBackgroundTabs(model *UIModel) bool {
+	scanning := false
+	for i, tab := range model.tabs {
+		if i == model.activeTab || tab.state != stateScanning {
+			continue
+		}
+		complete := tab.scan.IsComplete()
+		tab.scanInfo = tab.scan.Progress()
+		tab.results = tab.scan.Results()
+		if complete {
+			tab.state = stateComplete
+			tab.scanEndTime = time.Now()
+		} else {
+			scanning = true
+		}
+	}
+	return scanning
+}
+
+func (tab *scanTab) label(index int) string {
+	label := fmt.Sprintf("%d: %s", index+1, strings.TrimSuffix(tab.options.Target, "/32"))
+	if tab.state == stateScanning {
+		return fmt.Sprintf("%s ⏳ %d/%d", label, tab.scanInfo.HostsScanned, tab.scanInfo.TotalHosts)
+	}
+	return fmt.Sprintf("%s (%d)", label, len(tab.results))
+}
+
+type TabsComponent struct{}
+
+func NewTabsComponent() *TabsComponent {
+	return &TabsComponent{}
+}
+
+func (t *TabsComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch {
+	case key.Matches(keyMsg, keys.NextTab):
+		switchTab(model, model.activeTab+1)
+	case key.Matches(keyMsg, keys.PrevTab):
+		switchTab(model, model.activeTab-1)
+	case key.Matches(keyMsg, keys.CloseTab):
+		closeTab(model)
+	}
+	return nil
+}
+
+func (t *TabsComponent) View(model *UIModel) string {
+	if len(model.tabs) < 2 {
+		return ""
+	}
+	labels := make([]string, len(model.tabs))
+	for i, tab := range model.tabs {
+		if i == model.activeTab {
+			active := *tab
+			active.state = model.state
+			active.scanInfo = model.scanInfo
+			active.results = model.results
+			labels[i] = selectedRowStyle.Render(" " + active.label(i) + " ")
+		} else {
+			labels[i] = contextStyle.Render(" " + tab.label(i) + " ")
+		}
+	}
+	return lipgloss.NewStyle().PaddingLeft(1).Render("📑" + strings.Join(labels, "│"))
+}
+
+func (t *TabsComponent) Height() int {
+	return 1
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var traceMethods = []string{traceUDP, traceICMP, traceTCP}
+
+type TraceComponent struct{}
+
+func NewTraceComponent() *TraceComponent {
+	return &TraceComponent{}
+}
+
+func runTrace(target, method string) tea.Cmd {
+	return func() tea.Msg {
+		result, err := traceRoute(context.Background(), target, TraceOptions{Method: method})
+		return traceResultMsg{target: target, result: result, err: err}
+	}
+}
+
+func (c *TraceComponent) Start(model *UIModel, target string) tea.Cmd {
+	if model.traceMethod == "" {
+		model.traceMethod = traceUDP
+	}
+	model.traceVisible = true
+	model.traceTarget = target
+	model.tracing = true
+	model.traceErr = nil
+	return runTrace(target, model.traceMethod)
+}
+
+func (c *TraceComponent) Update(msg tea.Msg, model *UIModel) tea.Cmd {
+	switch msg := msg.(type) {
+	case traceResultMsg:
+		if msg.target != model.traceTarget {
+			return nil
+		}
+		model.tracing = false
+		model.traceErr = msg.err
+		if msg.err != nil || msg.result == nil {
+			return nil
+		}
+		if model.traces == nil {
+			model.traces = make(map[string]*TraceResult)
+		}
+		if _, seen := model.traces[msg.target]; !seen {
+			model.traceOrder = append(model.traceOrder, msg.target)
+		}
+		model.traces[msg.target] = msg.result
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Back):
+			model.traceVisible = false
+		case key.Matches(msg, keys.Trace):
+			if !model.tracing {
+				return c.Start(model, model.traceTarget)
+			}
+		case key.Matches(msg, keys.TraceMethod):
+			if !model.tracing {
+				for i, method := range traceMethods {
+					if method == model.traceMethod {
+						model.traceMethod = traceMethods[(i+1)%len(traceMethods)]
+						break
+					}
+				}
+				return c.Start(model, model.traceTarget)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *TraceComponent) View(model *UIModel) string {
+	lines := []string{fmt.Sprintf("🛰️  Trace to %s (%s)", model.traceTarget, model.traceMethod)}
+
+	switch {
+	case model.tracing:
+		lines = append(lines, "⏳ tracing...")
+	case model.traceErr != nil:
+		lines = append(lines, fmt.Sprintf("❌ %v", model.traceErr))
+	}
+
+	if result, ok := model.traces[model.traceTarget]; ok && !model.tracing {
+		lines = append(lines, result.Lines()[1:]...)
+	}
+
+	if len(model.traceOrder) > 1 {
+		var traces []*TraceResult
+		for _, target := range model.traceOrder {
+			traces = append(traces, model.traces[target])
+		}
+		lines = append(lines, "", fmt.Sprintf("🗺️  Topology (%d traced hosts)", len(traces)))
+		lines = append(lines, renderTopology(buildTopology(traces))...)
+	}
+
+	if len(lines) > model.viewHeight && model.viewHeight > 0 {
+		lines = lines[:model.viewHeight]
+	}
+	return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(lines, "\n"))
+}
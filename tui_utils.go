@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 
@@ -11,22 +10,17 @@ import (
 )
 
 func getUniqueVendors(results []*HostInfo) string {
-	vendorMap := make(map[string]bool)
-	for _, host := range results {
-		if host.Vendor != "" && host.Vendor != "Unknown" {
-			vendorMap[host.Vendor] = true
+	var vendors []string
+	for _, group := range groupHosts(results, groupByVendor) {
+		if group.Name != unknownVendorGroup {
+			vendors = append(vendors, group.Name)
 		}
 	}
 
-	if len(vendorMap) == 0 {
+	if len(vendors) == 0 {
 		return "None detected"
 	}
 
-	var vendors []string
-	for vendor := range vendorMap {
-		vendors = append(vendors, vendor)
-	}
-
 	if len(vendors) > 5 {
 		return fmt.Sprintf("%s and %d more", strings.Join(vendors[:5], ", "), len(vendors)-5)
 	}
@@ -35,6 +29,10 @@ func getUniqueVendors(results []*HostInfo) string {
 }
 
 func matchesSearch(host *HostInfo, searchTerm string) bool {
+	if title, ok := strings.CutPrefix(searchTerm, "title:"); ok {
+		return matchesHTTPTitle(host, strings.TrimSpace(title))
+	}
+
 	if fuzzyMatch(strings.ToLower(host.IP), searchTerm) {
 		return true
 	}
@@ -51,6 +49,10 @@ func matchesSearch(host *HostInfo, searchTerm string) bool {
 		return true
 	}
 
+	if matchesHostIdentity(host, searchTerm) {
+		return true
+	}
+
 	for _, service := range host.Services {
 		if fuzzyMatch(strings.ToLower(service.Service), searchTerm) {
 			return true
@@ -61,11 +63,68 @@ func matchesSearch(host *HostInfo, searchTerm string) bool {
 		if service.Banner != "" && fuzzyMatch(strings.ToLower(service.Banner), searchTerm) {
 			return true
 		}
+		if service.HTTP != nil {
+			if service.HTTP.Title != "" && fuzzyMatch(strings.ToLower(service.HTTP.Title), searchTerm) {
+				return true
+			}
+			if service.HTTP.Server != "" && fuzzyMatch(strings.ToLower(service.HTTP.Server), searchTerm) {
+				return true
+			}
+		}
 	}
 
 	return false
 }
 
+func matchesHostIdentity(host *HostInfo, searchTerm string) bool {
+	fields := []string{host.DeviceType, deviceTypeLabel(host.DeviceType), host.OSFamily}
+	if host.NetBIOS != nil {
+		fields = append(fields, host.NetBIOS.Name, host.NetBIOS.Workgroup, host.NetBIOS.User)
+	}
+	if host.SMB != nil {
+		fields = append(fields, host.SMB.NetBIOSComputer, host.SMB.NetBIOSDomain, host.SMB.DNSComputer,
+			host.SMB.DNSDomain, host.SMB.OSHint, "smb "+strings.Join(host.SMB.Dialects, " "))
+		if host.SMB.SMB1 {
+			fields = append(fields, "smbv1")
+		}
+		if !host.SMB.SigningRequired {
+			fields = append(fields, "signing not required")
+		}
+	}
+	if host.MDNS != nil {
+		fields = append(fields, host.MDNS.Hostname)
+		for _, service := range host.MDNS.Services {
+			fields = append(fields, service.Instance, service.Type)
+			fields = append(fields, service.TXT...)
+		}
+	}
+	if host.SNMP != nil {
+		fields = append(fields, host.SNMP.SysName, host.SNMP.SysDescr, host.SNMP.SysLocation,
+			host.SNMP.SysContact, host.SNMP.SysObjectID)
+	}
+	if host.UPnP != nil {
+		fields = append(fields, host.UPnP.FriendlyName, host.UPnP.Manufacturer, host.UPnP.ModelName,
+			host.UPnP.ModelNumber, host.UPnP.SerialNumber, host.UPnP.DeviceType)
+		fields = append(fields, host.UPnP.Services...)
+	}
+
+	for _, field := range fields {
+		if field != "" && fuzzyMatch(strings.ToLower(field), searchTerm) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesHTTPTitle(host *HostInfo, title string) bool {
+	for _, service := range host.Services {
+		if service.HTTP != nil && strings.Contains(strings.ToLower(service.HTTP.Title), title) {
+			return true
+		}
+	}
+	return false
+}
+
 func matchesFocusedSearch(host *HostInfo, searchTerm string) bool {
 	if fuzzyMatch(strings.ToLower(host.IP), searchTerm) {
 		return true
@@ -114,6 +173,9 @@ func animateProgress() tea.Cmd {
 
 func filterResults(model *UIModel) {
 	searchTerm := strings.ToLower(strings.TrimSpace(model.searchInput.Value()))
+	sortHosts(model.results, model.sortColumn, model.sortDesc)
+	model.searchErr = nil
+	model.filterActive = searchTerm != ""
 
 	if searchTerm == "" {
 		model.filteredResults = []*HostInfo{}
@@ -121,6 +183,18 @@ func filterResults(model *UIModel) {
 		return
 	}
 
+	var query *HostQuery
+	if !model.searchOnlyMode {
+		var err error
+		query, err = parseQuery(strings.TrimSpace(model.searchInput.Value()))
+		if err != nil {
+			model.searchErr = err
+			model.filteredResults = []*HostInfo{}
+			model.scrollOffset = 0
+			return
+		}
+	}
+
 	model.filteredResults = []*HostInfo{}
 
 	for _, host := range model.results {
@@ -131,17 +205,13 @@ func filterResults(model *UIModel) {
 		} else if model.searchOnlyMode {
 			matches = matchesFocusedSearch(host, searchTerm)
 		} else {
-			matches = matchesSearch(host, searchTerm)
+			matches = query.Match(host)
 		}
 		if matches {
 			model.filteredResults = append(model.filteredResults, host)
 		}
 	}
 
-	sort.Slice(model.filteredResults, func(i, j int) bool {
-		return ipToInt(model.filteredResults[i].IP) < ipToInt(model.filteredResults[j].IP)
-	})
-
 	model.scrollOffset = 0
 }
 
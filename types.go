@@ -12,20 +12,39 @@ type ServiceInfo struct {
 	Port         int
 	Protocol     string
 	Service      string
+	Product      string
 	Version      string
+	ExtraInfo    string
 	Banner       string
 	IsOpen       bool
 	ResponseTime time.Duration
+	TLS          *TLSInfo
+	HTTP         *HTTPInfo
+	SSH          *SSHInfo
+	TCP          *TCPFingerprint
 }
 
 type HostInfo struct {
-	IP           string
-	MAC          string
-	Vendor       string
-	Hostname     string
-	Services     []ServiceInfo
-	IsReachable  bool
-	ResponseTime time.Duration
+	IP               string
+	MAC              string
+	Vendor           string
+	Hostname         string
+	Services         []ServiceInfo
+	IsReachable      bool
+	ResponseTime     time.Duration
+	TTL              int
+	NetBIOS          *NetBIOSInfo
+	SMB              *SMBInfo
+	MDNS             *MDNSInfo
+	UPnP             *UPnPInfo
+	SNMP             *SNMPInfo
+	DeviceType       string
+	DeviceConfidence int
+	OSFamily         string
+	OSConfidence     int
+	DiscoveryMethod  string
+	FirstSeen        time.Time
+	LastSeen         time.Time
 }
 
 type ScanProgress struct {
@@ -43,6 +62,7 @@ type scanState int
 const (
 	stateScanning scanState = iota
 	stateComplete
+	stateSelectInterface
 )
 
 type UIModel struct {
@@ -53,23 +73,84 @@ type UIModel struct {
 	scanInfo        ScanProgress
 	results         []*HostInfo
 	filteredResults []*HostInfo
+	filterActive    bool
 	targetSubnet    string
 	startPort       int
 	endPort         int
 	timeout         int
 	customPorts     []int
 	ipsOnly         bool
+	discovery       string
 	quitting        bool
 	err             error
 	scrollOffset    int
+	cursor          int
+	detailVisible   bool
+	detailScroll    int
+	sortColumn      sortColumn
+	sortDesc        bool
+	portView        bool
+	portCursor      int
+	portExpanded    map[int]bool
+	groupBy         string
+	groupCursor     int
+	groupExpanded   map[string]bool
 	viewHeight      int
 	searchFocused   bool
 	searchOnlyMode  bool
+	searchErr       error
 	windowWidth     int
 	windowHeight    int
 	scanEndTime     time.Time
+	interfaces      []InterfaceSubnet
+	interfaceCursor int
+	traceVisible    bool
+	traceTarget     string
+	traceMethod     string
+	tracing         bool
+	traceErr        error
+	traces          map[string]*TraceResult
+	traceOrder      []string
+	tabs            []*scanTab
+	activeTab       int
+	polling         bool
+	actionsVisible  bool
+	actionCursor    int
+	status          string
+	exportVisible   bool
+	exportNaming    bool
+	exportFormat    int
+	exportFiltered  bool
+	exportInput     textinput.Model
+	helpVisible     bool
+	formVisible     bool
+	formInputs      []textinput.Model
+	formFocus       int
+	formIPsOnly     bool
+	formErr         error
 }
 
+type interfaceSelectedMsg struct{}
+
+type traceResultMsg struct {
+	target string
+	result *TraceResult
+	err    error
+}
+
+type newScanMsg struct {
+	options ScanOptions
+}
+
+type hostScanMsg struct {
+	tab    *scanTab
+	host   *HostInfo
+	result *HostInfo
+	deep   bool
+}
+
+type statusMsg string
+
 type scanErrorMsg struct {
 	err error
 }